@@ -30,16 +30,15 @@ type APIEntity struct {
 	Name string `json:"name"`
 }
 
-type apiProxyResponseData struct {
-	Root struct {
-		Results []struct {
-			Name    string `json:"name"`
-			Version string `json:"version"`
-			Status  string `json:"state"`
-		} `json:"results"`
-	} `json:"d"`
+type apiProxyResult struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Status  string `json:"state"`
 }
 
+// Number of APIProxies requested per page, as API Management does not return links to further pages
+const apiProxyPageSize = 1000
+
 type APIProxyMetadata struct {
 	Name    string
 	Version string
@@ -138,26 +137,20 @@ func (a *APIProxy) Get(id string) (bool, error) {
 
 func (a *APIProxy) List() ([]*APIProxyMetadata, error) {
 	log.Info().Msgf("Getting list of APIProxies")
-	urlPath := fmt.Sprintf("/apiportal/api/1.0/Management.svc/APIProxies")
-
-	callType := fmt.Sprintf("List APIProxies")
-	resp, err := readOnlyCall(urlPath, callType, a.exe)
-	// Process response to extract proxy details
-	var jsonData *apiProxyResponseData
-	respBody, err := a.exe.ReadRespBody(resp)
-	err = json.Unmarshal(respBody, &jsonData)
-	if err != nil {
-		log.Warn().Msgf("⚠️ Please check that hostname and credentials for APIM are correct - do not use CPI values!")
-		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
-		return nil, errors.Wrap(err, 0)
-	}
+	urlPath := fmt.Sprintf("/apiportal/api/1.0/Management.svc/APIProxies?$top=%d", apiProxyPageSize)
+
 	var details []*APIProxyMetadata
-	for _, result := range jsonData.Root.Results {
+	err := readAllPages(urlPath, "List APIProxies", a.exe, func(result *apiProxyResult) error {
 		details = append(details, &APIProxyMetadata{
 			Name:    result.Name,
 			Version: result.Version,
 			Status:  result.Status,
 		})
+		return nil
+	})
+	if err != nil {
+		log.Warn().Msgf("⚠️ Please check that hostname and credentials for APIM are correct - do not use CPI values!")
+		return nil, err
 	}
 	return details, nil
 }
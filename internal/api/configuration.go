@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/engswee/flashpipe/internal/httpclnt"
-	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 	"net/url"
 )
@@ -35,21 +34,14 @@ func (c *Configuration) Get(id string, version string) (*ParametersData, error)
 	log.Info().Msgf("Getting configuration parameters of Integration designtime artifact %v", id)
 	urlPath := fmt.Sprintf("/api/v1/IntegrationDesigntimeArtifacts(Id='%v',Version='%v')/Configurations", id, version)
 
-	callType := "Get configuration parameters"
-	resp, err := readOnlyCall(urlPath, callType, c.exe)
+	jsonData := new(ParametersData)
+	err := readAllPages(urlPath, "Get configuration parameters", c.exe, func(parameter *ParameterData) error {
+		jsonData.Root.Results = append(jsonData.Root.Results, parameter)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	var jsonData *ParametersData
-	respBody, err := c.exe.ReadRespBody(resp)
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(respBody, &jsonData)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
-		return nil, errors.Wrap(err, 0)
-	}
 	return jsonData, nil
 }
 
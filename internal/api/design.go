@@ -8,6 +8,7 @@ import (
 	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 	"os"
+	"path"
 	"path/filepath"
 )
 
@@ -19,7 +20,8 @@ type DesigntimeArtifact interface {
 	Get(id string, version string) (string, string, bool, error)
 	Download(targetFile string, id string) error
 	CopyContent(srcDir string, tgtDir string) error
-	CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (bool, error)
+	// CompareContent returns the paths of the changed files relative to the artifact directory
+	CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) ([]string, error)
 }
 
 type designtimeArtifactData struct {
@@ -172,15 +174,30 @@ func getContent(id string, version string, artifactType string, exe *httpclnt.HT
 	return exe.ReadRespBody(resp)
 }
 
-func diffContent(firstDir string, secondDir string) bool {
+func diffContent(firstDir string, secondDir string) []string {
+	return diffContentWithOptions(firstDir, secondDir, file.DirectoryDiffOptions())
+}
+
+func diffContentWithOptions(firstDir string, secondDir string, options *file.DiffOptions) []string {
 	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(firstDir+"/META-INF", secondDir+"/META-INF")
+	changedFiles := diffSubDirectory(firstDir, secondDir, "META-INF", options)
 	log.Info().Msg("Checking for changes in src/main/resources directory")
-	resourcesDiffer := file.DiffDirectories(firstDir+"/src/main/resources", secondDir+"/src/main/resources")
+	changedFiles = append(changedFiles, diffSubDirectory(firstDir, secondDir, "src/main/resources", options)...)
 	log.Info().Msg("Checking for changes in metainfo.prop")
-	metainfoDiffer := DiffOptionalFile(firstDir, secondDir, "metainfo.prop")
+	if DiffOptionalFile(firstDir, secondDir, "metainfo.prop") {
+		changedFiles = append(changedFiles, "metainfo.prop")
+	}
+	return changedFiles
+}
 
-	return metaDiffer || resourcesDiffer || metainfoDiffer
+// diffSubDirectory compares a subdirectory of two artifacts and returns the changed files relative to the artifact directory
+func diffSubDirectory(firstDir string, secondDir string, subDir string, options *file.DiffOptions) []string {
+	result := file.DiffDirectoriesWithOptions(firstDir+"/"+subDir, secondDir+"/"+subDir, options)
+	var changedFiles []string
+	for _, changedFile := range result.ChangedFiles() {
+		changedFiles = append(changedFiles, path.Join(subDir, changedFile))
+	}
+	return changedFiles
 }
 
 func copyContent(srcDir string, tgtDir string) error {
@@ -189,10 +206,20 @@ func copyContent(srcDir string, tgtDir string) error {
 	if err != nil {
 		return err
 	}
+	// Environment specific parameters are maintained only in Git, so keep those of the target
+	// and do not copy those of the source
+	overlays, err := readParameterOverlays(tgtDir + "/src/main/resources")
+	if err != nil {
+		return err
+	}
 	err = file.ReplaceDir(srcDir+"/src/main/resources", tgtDir+"/src/main/resources")
 	if err != nil {
 		return err
 	}
+	err = replaceParameterOverlays(tgtDir+"/src/main/resources", overlays)
+	if err != nil {
+		return err
+	}
 	// Copy also metainfo.prop that contains the description if it is available
 	if file.Exists(srcDir + "/metainfo.prop") {
 		err = file.CopyFile(srcDir+"/metainfo.prop", tgtDir+"/metainfo.prop")
@@ -202,11 +229,51 @@ func copyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
+
+// ParameterOverlayPattern matches the environment specific parameters.<environment>.prop files
+const ParameterOverlayPattern = "parameters.*.prop"
+
+func readParameterOverlays(dir string) (map[string][]byte, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, ParameterOverlayPattern))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	overlays := map[string][]byte{}
+	for _, match := range matches {
+		content, err := os.ReadFile(match)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		overlays[filepath.Base(match)] = content
+	}
+	return overlays, nil
+}
+
+func replaceParameterOverlays(dir string, overlays map[string][]byte) error {
+	matches, err := filepath.Glob(filepath.Join(dir, ParameterOverlayPattern))
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	for _, match := range matches {
+		err = os.Remove(match)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	for name, content := range overlays {
+		err = os.WriteFile(filepath.Join(dir, name), content, os.ModePerm)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
 func DiffOptionalFile(srcDir string, tgtDir string, fileRelativePath string) bool {
 	downloadedFile := fmt.Sprintf("%v/%v", srcDir, fileRelativePath)
 	gitFile := fmt.Sprintf("%v/%v", tgtDir, fileRelativePath)
 	if file.Exists(downloadedFile) && file.Exists(gitFile) {
-		return file.DiffFile(downloadedFile, gitFile)
+		return file.DiffFile(downloadedFile, gitFile) != nil
 	} else if !file.Exists(downloadedFile) && !file.Exists(gitFile) {
 		log.Warn().Msgf("Skipping diff of %v as it does not exist in both source and target", fileRelativePath)
 		return false
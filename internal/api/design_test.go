@@ -167,11 +167,11 @@ func compare(id string, dt DesigntimeArtifact, t *testing.T) {
 	// Diff artifact content
 	srcDir := fmt.Sprintf("../../test/testdata/artifacts/update/%v", id)
 	tgtDir := fmt.Sprintf("../../test/testdata/artifacts/create/%v", id)
-	dirDiffer, err := dt.CompareContent(srcDir, tgtDir, nil, "git")
+	changedFiles, err := dt.CompareContent(srcDir, tgtDir, nil, "git")
 	if err != nil {
 		t.Fatalf("CompareContent failed with error - %v", err)
 	}
-	assert.True(t, dirDiffer, "Directory contents do not differ")
+	assert.NotEmpty(t, changedFiles, "Directory contents do not differ")
 
 	// Copy to output folder
 	destinationDir := fmt.Sprintf("../../output/download/%v", id)
@@ -202,3 +202,37 @@ func setupArtifact(t *testing.T, artifactId string, packageId string, artifactDi
 		}
 	}
 }
+
+func TestCopyContent_ParameterOverlays(t *testing.T) {
+	srcDir := t.TempDir()
+	tgtDir := t.TempDir()
+	for _, dir := range []string{srcDir + "/META-INF", srcDir + "/src/main/resources", tgtDir + "/src/main/resources"} {
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			t.Fatalf("Directory creation failed with error - %v", err)
+		}
+	}
+	files := map[string]string{
+		srcDir + "/META-INF/MANIFEST.MF":                      "Manifest-Version: 1.0\n",
+		srcDir + "/src/main/resources/parameters.prop":        "Timeout=60000\n",
+		srcDir + "/src/main/resources/parameters.DEV.prop":    "Timeout=1000\n",
+		tgtDir + "/src/main/resources/parameters.QA.prop":     "Timeout=5000\n",
+		tgtDir + "/src/main/resources/script/obsolete.groovy": "",
+	}
+	for path, content := range files {
+		_ = os.MkdirAll(path[:strings.LastIndex(path, "/")], os.ModePerm)
+		err := os.WriteFile(path, []byte(content), os.ModePerm)
+		if err != nil {
+			t.Fatalf("File creation failed with error - %v", err)
+		}
+	}
+
+	err := copyContent(srcDir, tgtDir)
+	if err != nil {
+		t.Fatalf("copyContent failed with error - %v", err)
+	}
+	assert.True(t, file.Exists(tgtDir+"/src/main/resources/parameters.prop"), "parameters.prop missing in target")
+	assert.True(t, file.Exists(tgtDir+"/src/main/resources/parameters.QA.prop"), "Overlay of target removed")
+	assert.False(t, file.Exists(tgtDir+"/src/main/resources/parameters.DEV.prop"), "Overlay of source copied")
+	assert.False(t, file.Exists(tgtDir+"/src/main/resources/script/obsolete.groovy"), "Obsolete file not removed")
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type Integration struct {
-	exe *httpclnt.HTTPExecuter
-	typ string
+	exe           *httpclnt.HTTPExecuter
+	typ           string
+	ignoreDiagram bool
 }
 
 // NewIntegration returns an initialised Integration instance.
@@ -18,6 +19,11 @@ func NewIntegration(exe *httpclnt.HTTPExecuter) DesigntimeArtifact {
 	return i
 }
 
+// SetIgnoreDiagram ignores changes to the diagram elements (bpmndi) of the IFlow BPMN2 files during comparison
+func (int *Integration) SetIgnoreDiagram(ignoreDiagram bool) {
+	int.ignoreDiagram = ignoreDiagram
+}
+
 func (int *Integration) Create(id string, name string, packageId string, artifactDir string) error {
 	return create(id, name, packageId, artifactDir, int.typ, int.exe)
 }
@@ -39,25 +45,27 @@ func (int *Integration) Download(targetFile string, id string) error {
 func (int *Integration) CopyContent(srcDir string, tgtDir string) error {
 	return copyContent(srcDir, tgtDir)
 }
-func (int *Integration) CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (bool, error) {
+func (int *Integration) CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) ([]string, error) {
 	// Update the script collection in IFlow BPMN2 XML of source side before diff comparison
 	err := file.UpdateBPMN(srcDir, scriptMap)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Diff directories excluding parameters.prop
-	dirDiffer := diffContent(srcDir, tgtDir)
+	// Diff directories excluding parameters.prop, IFlow BPMN2 files are compared semantically
+	options := file.DirectoryDiffOptions()
+	options.IgnoreDiagram = int.ignoreDiagram
+	changedFiles := diffContentWithOptions(srcDir, tgtDir, options)
 
 	// Handling for parameters.prop differences
 	// - Any configured value will remain in IFlow even if the IFlow is replaced and the parameter is no longer used
 	// - Therefore diff of parameters.prop may come up with false differences
 	if target == "git" {
 		// When syncing (from tenant to Git), include diff of parameter.prop separately
-		paramDiffer := DiffOptionalFile(srcDir, tgtDir, "src/main/resources/parameters.prop")
-		return dirDiffer || paramDiffer, nil
-	} else {
-		// When uploading (from Git to tenant), API is used to update the configuration parameters separately
-		return dirDiffer, nil
+		if DiffOptionalFile(srcDir, tgtDir, "src/main/resources/parameters.prop") {
+			changedFiles = append(changedFiles, "src/main/resources/parameters.prop")
+		}
 	}
+	// When uploading (from Git to tenant), API is used to update the configuration parameters separately
+	return changedFiles, nil
 }
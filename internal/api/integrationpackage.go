@@ -30,22 +30,14 @@ type PackageSingleData struct {
 	} `json:"d"`
 }
 
-type artifactData struct {
-	Root struct {
-		Results []struct {
-			Id      string `json:"Id"`
-			Name    string `json:"Name"`
-			Version string `json:"Version"`
-		} `json:"results"`
-	} `json:"d"`
+type artifactResult struct {
+	Id      string `json:"Id"`
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
 }
 
-type packageMultipleData struct {
-	Root struct {
-		Results []struct {
-			Id string `json:"Id"`
-		} `json:"results"`
-	} `json:"d"`
+type packageResult struct {
+	Id string `json:"Id"`
 }
 
 type ArtifactDetails struct {
@@ -68,22 +60,13 @@ func (ip *IntegrationPackage) GetPackagesList() ([]string, error) {
 	log.Info().Msg("Getting list of IntegrationPackages")
 	urlPath := "/api/v1/IntegrationPackages"
 
-	callType := "Get IntegrationPackages list"
-	resp, err := readOnlyCall(urlPath, callType, ip.exe)
-	if err != nil {
-		return nil, err
-	}
-	// Process response to extract packages
-	var jsonData *packageMultipleData
-	respBody, err := ip.exe.ReadRespBody(resp)
-	err = json.Unmarshal(respBody, &jsonData)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
-		return nil, errors.Wrap(err, 0)
-	}
 	var packageIds []string
-	for _, result := range jsonData.Root.Results {
+	err := readAllPages(urlPath, "Get IntegrationPackages list", ip.exe, func(result *packageResult) error {
 		packageIds = append(packageIds, result.Id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return packageIds, nil
 }
@@ -119,20 +102,8 @@ func (ip *IntegrationPackage) GetArtifactsData(id string, artifactType string) (
 	urlPath := fmt.Sprintf("/api/v1/IntegrationPackages('%v')/%vDesigntimeArtifacts", id, artifactType)
 
 	callType := fmt.Sprintf("Get %v designtime artifacts of IntegrationPackages", artifactType)
-	resp, err := readOnlyCall(urlPath, callType, ip.exe)
-	if err != nil {
-		return nil, err
-	}
-	// Process response to extract artifact details
-	var jsonData *artifactData
-	respBody, err := ip.exe.ReadRespBody(resp)
-	err = json.Unmarshal(respBody, &jsonData)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
-		return nil, errors.Wrap(err, 0)
-	}
 	var details []*ArtifactDetails
-	for _, result := range jsonData.Root.Results {
+	err := readAllPages(urlPath, callType, ip.exe, func(result *artifactResult) error {
 		var draft bool
 		if result.Version == "Active" {
 			draft = true
@@ -146,6 +117,10 @@ func (ip *IntegrationPackage) GetArtifactsData(id string, artifactType string) (
 			Version:      result.Version,
 			ArtifactType: artifactType,
 		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return details, nil
 }
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MessageLog struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// MessageLogFilter are the criteria of the message processing logs to get. Empty criteria are not applied
+type MessageLogFilter struct {
+	IFlowIds      []string
+	Statuses      []string
+	CorrelationId string
+	From          time.Time
+	To            time.Time
+	// Limit is the maximum number of logs, starting with the most recent ones
+	Limit int
+}
+
+type MessageProcessingLog struct {
+	MessageGuid            string                  `json:"messageGuid"`
+	CorrelationId          string                  `json:"correlationId"`
+	ApplicationMessageId   string                  `json:"applicationMessageId,omitempty"`
+	IFlowId                string                  `json:"iflowId"`
+	Status                 string                  `json:"status"`
+	CustomStatus           string                  `json:"customStatus,omitempty"`
+	LogStart               time.Time               `json:"logStart"`
+	LogEnd                 time.Time               `json:"logEnd"`
+	ErrorInformation       string                  `json:"errorInformation,omitempty"`
+	Attachments            []*MessageAttachment    `json:"attachments,omitempty"`
+	CustomHeaderProperties []*CustomHeaderProperty `json:"customHeaderProperties,omitempty"`
+}
+
+type MessageAttachment struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+type CustomHeaderProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type messageLogResult struct {
+	MessageGuid            string `json:"MessageGuid"`
+	CorrelationId          string `json:"CorrelationId"`
+	ApplicationMessageId   string `json:"ApplicationMessageId"`
+	IntegrationFlowName    string `json:"IntegrationFlowName"`
+	Status                 string `json:"Status"`
+	CustomStatus           string `json:"CustomStatus"`
+	LogStart               string `json:"LogStart"`
+	LogEnd                 string `json:"LogEnd"`
+	CustomHeaderProperties struct {
+		Results []struct {
+			Name  string `json:"Name"`
+			Value string `json:"Value"`
+		} `json:"results"`
+	} `json:"CustomHeaderProperties"`
+}
+
+type attachmentResult struct {
+	Id          string `json:"Id"`
+	Name        string `json:"Name"`
+	ContentType string `json:"ContentType"`
+	PayloadSize int64  `json:"PayloadSize"`
+}
+
+// errLimitReached stops reading further pages once the limit of the filter is reached
+var errLimitReached = errors.New("limit reached")
+
+var odataDatePattern = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d+)?\)/$`)
+
+// NewMessageLog returns an initialised MessageLog instance.
+func NewMessageLog(exe *httpclnt.HTTPExecuter) *MessageLog {
+	m := new(MessageLog)
+	m.exe = exe
+	return m
+}
+
+// List returns the message processing logs matching the filter, most recent first, together with their
+// custom header properties, attachments and error information
+func (m *MessageLog) List(filter *MessageLogFilter) ([]*MessageProcessingLog, error) {
+	log.Info().Msg("Getting message processing logs")
+	urlPath := messageLogsPath(filter)
+
+	var logs []*MessageProcessingLog
+	err := readAllPages(urlPath, "Get message processing logs", m.exe, func(result *messageLogResult) error {
+		if filter.Limit > 0 && len(logs) >= filter.Limit {
+			return errLimitReached
+		}
+		mpl, err := result.toMessageProcessingLog()
+		if err != nil {
+			return err
+		}
+		logs = append(logs, mpl)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, err
+	}
+
+	for _, mpl := range logs {
+		mpl.Attachments, err = m.getAttachments(mpl.MessageGuid)
+		if err != nil {
+			return nil, err
+		}
+		switch mpl.Status {
+		case "FAILED", "RETRY", "ESCALATED":
+			mpl.ErrorInformation, err = m.getErrorInformation(mpl.MessageGuid)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logs, nil
+}
+
+func (m *MessageLog) getAttachments(messageGuid string) ([]*MessageAttachment, error) {
+	log.Debug().Msgf("Getting attachments of message %v", messageGuid)
+	urlPath := fmt.Sprintf("/api/v1/MessageProcessingLogs('%v')/Attachments", messageGuid)
+
+	var attachments []*MessageAttachment
+	err := readAllPages(urlPath, "Get message processing log attachments", m.exe, func(result *attachmentResult) error {
+		attachments = append(attachments, &MessageAttachment{Id: result.Id, Name: result.Name, ContentType: result.ContentType, Size: result.PayloadSize})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (m *MessageLog) getErrorInformation(messageGuid string) (string, error) {
+	log.Debug().Msgf("Getting error information of message %v", messageGuid)
+	urlPath := fmt.Sprintf("/api/v1/MessageProcessingLogs('%v')/ErrorInformation/$value", messageGuid)
+
+	callType := "Get message processing log error information"
+	resp, err := readOnlyCallWithBody(urlPath, nil, callType, m.exe)
+	if err != nil {
+		// Error information is not available for all messages, e.g. if it was already deleted
+		if resp != nil && resp.StatusCode == 404 {
+			return "", nil
+		}
+		return "", err
+	}
+	respBody, err := m.exe.ReadRespBody(resp)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(respBody)), nil
+}
+
+// messageLogsPath returns the URL path with the $filter of the criteria, ordered by most recent first
+func messageLogsPath(filter *MessageLogFilter) string {
+	var conditions []string
+	if len(filter.IFlowIds) > 0 {
+		conditions = append(conditions, orCondition("IntegrationFlowName", filter.IFlowIds))
+	}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, orCondition("Status", filter.Statuses))
+	}
+	if filter.CorrelationId != "" {
+		conditions = append(conditions, fmt.Sprintf("CorrelationId eq '%v'", escapeODataString(filter.CorrelationId)))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("LogEnd ge datetime'%v'", filter.From.UTC().Format("2006-01-02T15:04:05")))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("LogEnd le datetime'%v'", filter.To.UTC().Format("2006-01-02T15:04:05")))
+	}
+
+	query := url.Values{}
+	if len(conditions) > 0 {
+		query.Set("$filter", strings.Join(conditions, " and "))
+	}
+	query.Set("$orderby", "LogEnd desc")
+	query.Set("$expand", "CustomHeaderProperties")
+	// OData expects spaces in the query to be encoded as %20
+	return "/api/v1/MessageProcessingLogs?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func orCondition(property string, values []string) string {
+	var conditions []string
+	for _, value := range values {
+		conditions = append(conditions, fmt.Sprintf("%v eq '%v'", property, escapeODataString(value)))
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, " or ") + ")"
+}
+
+func escapeODataString(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+func (r *messageLogResult) toMessageProcessingLog() (*MessageProcessingLog, error) {
+	logStart, err := parseODataDate(r.LogStart)
+	if err != nil {
+		return nil, err
+	}
+	logEnd, err := parseODataDate(r.LogEnd)
+	if err != nil {
+		return nil, err
+	}
+	mpl := &MessageProcessingLog{
+		MessageGuid:          r.MessageGuid,
+		CorrelationId:        r.CorrelationId,
+		ApplicationMessageId: r.ApplicationMessageId,
+		IFlowId:              r.IntegrationFlowName,
+		Status:               r.Status,
+		CustomStatus:         r.CustomStatus,
+		LogStart:             logStart,
+		LogEnd:               logEnd,
+	}
+	for _, property := range r.CustomHeaderProperties.Results {
+		mpl.CustomHeaderProperties = append(mpl.CustomHeaderProperties, &CustomHeaderProperty{Name: property.Name, Value: property.Value})
+	}
+	return mpl, nil
+}
+
+// parseODataDate parses dates of OData V2 JSON like /Date(1719561600000)/
+func parseODataDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	match := odataDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid OData date %v", value)
+	}
+	millis, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, 0)
+	}
+	return time.UnixMilli(millis).UTC(), nil
+}
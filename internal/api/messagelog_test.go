@@ -0,0 +1,70 @@
+package api
+
+import (
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newMessageLogServer() *mock.Server {
+	s := mock.NewServer()
+	s.PageSize = 2
+	logEnd := time.Date(2024, 6, 28, 10, 0, 0, 0, time.UTC)
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "Message1", CorrelationId: "Correlation1", IFlowId: "IFlow1", Status: "COMPLETED", LogStart: logEnd.Add(-time.Second), LogEnd: logEnd,
+		CustomHeaderProperties: map[string]string{"OrderNumber": "4711"}})
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "Message2", CorrelationId: "Correlation2", IFlowId: "IFlow1", Status: "FAILED", LogStart: logEnd, LogEnd: logEnd.Add(time.Minute),
+		ErrorInformation: "Mapping failed", Attachments: []string{"Payload"}})
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "Message3", CorrelationId: "Correlation3", IFlowId: "IFlow2", Status: "FAILED", LogStart: logEnd, LogEnd: logEnd.Add(2 * time.Minute)})
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "Message4", CorrelationId: "Correlation3", IFlowId: "IFlow'3", Status: "COMPLETED", LogStart: logEnd, LogEnd: logEnd.Add(3 * time.Minute)})
+	return s
+}
+
+func TestMessageLogsPath(t *testing.T) {
+	path := messageLogsPath(&MessageLogFilter{
+		IFlowIds:      []string{"IFlow1", "IFlow'2"},
+		Statuses:      []string{"FAILED"},
+		CorrelationId: "Correlation1",
+		From:          time.Date(2024, 6, 28, 10, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(t, "/api/v1/MessageProcessingLogs?%24expand=CustomHeaderProperties&%24filter=%28IntegrationFlowName%20eq%20%27IFlow1%27%20or%20IntegrationFlowName%20eq%20%27IFlow%27%272%27%29%20and%20Status%20eq%20%27FAILED%27%20and%20CorrelationId%20eq%20%27Correlation1%27%20and%20LogEnd%20ge%20datetime%272024-06-28T10%3A00%3A00%27&%24orderby=LogEnd%20desc", path, "Incorrect URL path")
+}
+
+func TestMessageLogList(t *testing.T) {
+	svr := newMessageLogServer().Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	ml := NewMessageLog(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	logs, err := ml.List(&MessageLogFilter{IFlowIds: []string{"IFlow1", "IFlow2", "IFlow'3"}})
+	if err != nil {
+		t.Fatalf("List failed with error - %v", err)
+	}
+	if assert.Len(t, logs, 4, "Incorrect number of logs") {
+		assert.Equal(t, "Message4", logs[0].MessageGuid, "Most recent log not first")
+		assert.Equal(t, "IFlow'3", logs[0].IFlowId, "Incorrect IFlow ID")
+		assert.Equal(t, time.Date(2024, 6, 28, 10, 3, 0, 0, time.UTC), logs[0].LogEnd, "Incorrect log end")
+		assert.Equal(t, "Mapping failed", logs[2].ErrorInformation, "Incorrect error information")
+		if assert.Len(t, logs[2].Attachments, 1, "Incorrect number of attachments") {
+			assert.Equal(t, "Payload", logs[2].Attachments[0].Name, "Incorrect attachment")
+		}
+		if assert.Len(t, logs[3].CustomHeaderProperties, 1, "Incorrect number of custom header properties") {
+			assert.Equal(t, &CustomHeaderProperty{Name: "OrderNumber", Value: "4711"}, logs[3].CustomHeaderProperties[0], "Incorrect custom header property")
+		}
+	}
+
+	logs, err = ml.List(&MessageLogFilter{Statuses: []string{"FAILED"}, From: time.Date(2024, 6, 28, 10, 1, 30, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("List failed with error - %v", err)
+	}
+	if assert.Len(t, logs, 1, "Incorrect number of FAILED logs") {
+		assert.Equal(t, "Message3", logs[0].MessageGuid, "Incorrect FAILED log")
+	}
+
+	logs, err = ml.List(&MessageLogFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("List failed with error - %v", err)
+	}
+	assert.Len(t, logs, 3, "Limit not applied")
+}
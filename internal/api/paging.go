@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// odataPage is a single page of an OData V2 collection
+type odataPage[T any] struct {
+	Root struct {
+		Results []T    `json:"results"`
+		Next    string `json:"__next"`
+	} `json:"d"`
+}
+
+// readAllPages gets the OData collection of urlPath page by page and passes each result to process as soon as
+// its page is read. Server-driven paging is followed with the __next link of the page. If urlPath contains $top
+// and the server returns a full page without __next, the next page is requested with $skip instead.
+// Reading stops at the first error returned by process
+func readAllPages[T any](urlPath string, callType string, exe *httpclnt.HTTPExecuter, process func(T) error) error {
+	for urlPath != "" {
+		resp, err := readOnlyCall(urlPath, callType, exe)
+		if err != nil {
+			return err
+		}
+		respBody, err := exe.ReadRespBody(resp)
+		if err != nil {
+			return err
+		}
+		var page *odataPage[T]
+		err = json.Unmarshal(respBody, &page)
+		if err != nil {
+			log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+			return errors.Wrap(err, 0)
+		}
+		for _, result := range page.Root.Results {
+			if err = process(result); err != nil {
+				return err
+			}
+		}
+		urlPath, err = nextPagePath(urlPath, page.Root.Next, len(page.Root.Results))
+		if err != nil {
+			return err
+		}
+		if urlPath != "" {
+			log.Debug().Msgf("Getting next page of %v", callType)
+		}
+	}
+	return nil
+}
+
+// nextPagePath returns the path of the page after the page of urlPath with the given number of results,
+// or an empty string if it is the last page
+func nextPagePath(urlPath string, next string, count int) (string, error) {
+	current, err := url.Parse(urlPath)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	if next != "" {
+		nextUrl, err := url.Parse(next)
+		if err != nil {
+			return "", fmt.Errorf("invalid __next link %v: %w", next, err)
+		}
+		if !nextUrl.IsAbs() && !strings.HasPrefix(nextUrl.Path, "/") {
+			// Relative links start with the collection, e.g. IntegrationPackages?$skiptoken=1000,
+			// and are resolved against the root of the service
+			segment := strings.SplitN(nextUrl.Path, "(", 2)[0]
+			segment = strings.SplitN(segment, "/", 2)[0]
+			if i := strings.Index(current.Path, "/"+segment); i >= 0 {
+				current.Path = current.Path[:i+1]
+				current.RawPath = ""
+			}
+		}
+		return current.ResolveReference(nextUrl).RequestURI(), nil
+	}
+
+	// Client-driven paging with $top and $skip
+	query := current.Query()
+	top, _ := strconv.Atoi(query.Get("$top"))
+	if top <= 0 || count < top {
+		return "", nil
+	}
+	skip, _ := strconv.Atoi(query.Get("$skip"))
+	query.Set("$skip", strconv.Itoa(skip+top))
+	current.RawQuery = query.Encode()
+	return current.RequestURI(), nil
+}
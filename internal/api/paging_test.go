@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPagingServer() *httptest.Server {
+	mux := http.NewServeMux()
+	// Server-driven paging with absolute and relative __next links
+	mux.HandleFunc("/api/v1/IntegrationPackages", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("$skiptoken") {
+		case "":
+			w.Write([]byte(fmt.Sprintf(`{"d": {"results": [{"Id": "Package1"}, {"Id": "Package2"}], "__next": "http://%v/api/v1/IntegrationPackages?$skiptoken=2"}}`, r.Host)))
+		case "2":
+			w.Write([]byte(`{"d": {"results": [{"Id": "Package3"}], "__next": "IntegrationPackages?$skiptoken=3"}}`))
+		case "3":
+			w.Write([]byte(`{"d": {"results": [{"Id": "Package4"}]}}`))
+		}
+	})
+	mux.HandleFunc("/api/v1/IntegrationPackages('Package1')/IntegrationDesigntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("$skiptoken") == "" {
+			w.Write([]byte(`{"d": {"results": [{"Id": "IFlow1", "Version": "1.0.0"}], "__next": "IntegrationPackages('Package1')/IntegrationDesigntimeArtifacts?$skiptoken=1"}}`))
+		} else {
+			w.Write([]byte(`{"d": {"results": [{"Id": "IFlow2", "Version": "Active"}]}}`))
+		}
+	})
+	// Client-driven paging with $top and $skip
+	mux.HandleFunc("/apiportal/api/1.0/Management.svc/APIProxies", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var results string
+		for i := 0; i < apiProxyPageSize; i++ {
+			if i > 0 {
+				results += ","
+			}
+			results += fmt.Sprintf(`{"name": "Proxy%d"}`, i)
+		}
+		switch r.URL.Query().Get("$skip") {
+		case "":
+			w.Write([]byte(fmt.Sprintf(`{"d": {"results": [%v]}}`, results)))
+		case fmt.Sprint(apiProxyPageSize):
+			w.Write([]byte(`{"d": {"results": [{"name": "LastProxy"}]}}`))
+		default:
+			http.Error(w, "Unexpected page", http.StatusBadRequest)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestReadAllPages(t *testing.T) {
+	svr := newPagingServer()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+
+	ip := NewIntegrationPackage(exe)
+	ids, err := ip.GetPackagesList()
+	if err != nil {
+		t.Fatalf("GetPackagesList failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"Package1", "Package2", "Package3", "Package4"}, ids, "Incorrect packages")
+
+	artifacts, err := ip.GetArtifactsData("Package1", "Integration")
+	if err != nil {
+		t.Fatalf("GetArtifactsData failed with error - %v", err)
+	}
+	if assert.Len(t, artifacts, 2, "Incorrect number of artifacts") {
+		assert.Equal(t, "IFlow2", artifacts[1].Id, "Incorrect artifact on second page")
+		assert.True(t, artifacts[1].IsDraft, "Draft artifact not detected")
+	}
+
+	proxies, err := NewAPIProxy(exe).List()
+	if err != nil {
+		t.Fatalf("List failed with error - %v", err)
+	}
+	if assert.Len(t, proxies, apiProxyPageSize+1, "Incorrect number of APIProxies") {
+		assert.Equal(t, "LastProxy", proxies[apiProxyPageSize].Name, "Incorrect APIProxy on second page")
+	}
+}
+
+func TestReadAllPages_StopOnError(t *testing.T) {
+	svr := newPagingServer()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+
+	var ids []string
+	err := readAllPages("/api/v1/IntegrationPackages", "Get IntegrationPackages list", exe, func(result *packageResult) error {
+		if result.Id == "Package3" {
+			return fmt.Errorf("stop")
+		}
+		ids = append(ids, result.Id)
+		return nil
+	})
+	assert.EqualError(t, err, "stop", "Error of process not returned")
+	assert.Equal(t, []string{"Package1", "Package2"}, ids, "Results processed after error")
+}
@@ -19,6 +19,12 @@ type runtimeData struct {
 	} `json:"d"`
 }
 
+type RuntimeArtifact struct {
+	Id      string `json:"Id"`
+	Version string `json:"Version"`
+	Status  string `json:"Status"`
+}
+
 type runtimeError struct {
 	Parameter []string `json:"parameter"`
 }
@@ -65,6 +71,23 @@ func (r *Runtime) Get(id string) (version string, status string, err error) {
 	}
 }
 
+// GetAll returns the details of all artifacts deployed to the runtime, so that the status
+// of many artifacts can be checked with a single call
+func (r *Runtime) GetAll() ([]*RuntimeArtifact, error) {
+	log.Info().Msg("Getting details of all runtime artifacts")
+	urlPath := "/api/v1/IntegrationRuntimeArtifacts"
+
+	var artifacts []*RuntimeArtifact
+	err := readAllPages(urlPath, "Get runtime artifacts", r.exe, func(artifact *RuntimeArtifact) error {
+		artifacts = append(artifacts, artifact)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
 func (r *Runtime) GetErrorInfo(id string) (string, error) {
 	log.Info().Msgf("Getting error info of runtime artifact %v", id)
 	urlPath := fmt.Sprintf("/api/v1/IntegrationRuntimeArtifacts('%v')/ErrorInformation/$value", id)
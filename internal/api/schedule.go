@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/go-errors/errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScheduleDataType is the data type of configuration parameters of timers
+const ScheduleDataType = "custom:schedule"
+
+const scheduleRunOnce = "@once"
+
+const scheduleDefaultZone = "Etc/GMT"
+
+// Rows of the schedule XML that hold the fields of the cron expression, in cron order
+var scheduleCronKeys = []string{"second", "minute", "hour", "day_of_month", "month", "dayOfWeek", "year"}
+
+// Rows of the schedule XML that are covered by the cron form or derived from it by the tenant UI.
+// Other rows, e.g. calendar settings, are extra settings that the cron form cannot express.
+var scheduleCronFormKeys = append([]string{"schedule1", "fireNow", "timeZone", "startAt", "endAt", "dateType", "timeType"}, scheduleCronKeys...)
+
+// Schedule is the timer setting of a configuration parameter with data type custom:schedule.
+//
+// On the tenant, the setting is stored as a list of <row><cell>key</cell><cell>value</cell></row>
+// entries. In parameters.prop it can be maintained in a readable form instead:
+//
+//	@once
+//	<second> <minute> <hour> <day-of-month> <month> <day-of-week> [<year>] [tz=<zone>] [start=<date>] [end=<date>]
+//
+// where the fields follow the Quartz cron syntax, e.g. 0 0/15 8-18 ? * MON-FRI tz=Europe/Berlin
+type Schedule struct {
+	RunOnce  bool
+	Fields   []string
+	TimeZone string
+	StartAt  string
+	EndAt    string
+	rows     []*scheduleRow
+	extra    []*scheduleRow
+}
+
+type scheduleRow struct {
+	Key   string
+	Value string
+}
+
+type scheduleXML struct {
+	Rows []struct {
+		Cells []string `xml:"cell"`
+	} `xml:"row"`
+}
+
+// IsScheduleXML returns true if the value is a schedule in the XML form stored on the tenant
+func IsScheduleXML(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "<row")
+}
+
+// ParseSchedule parses a schedule in either the XML form of the tenant or the readable cron form
+func ParseSchedule(value string) (*Schedule, error) {
+	if IsScheduleXML(value) {
+		return ParseScheduleXML(value)
+	}
+	return ParseScheduleCron(value)
+}
+
+// ParseScheduleXML parses a schedule in the XML form of the tenant
+func ParseScheduleXML(value string) (*Schedule, error) {
+	if !IsScheduleXML(value) {
+		return nil, fmt.Errorf("Schedule %v is not in XML form", value)
+	}
+	var data scheduleXML
+	err := xml.Unmarshal([]byte("<schedule>"+value+"</schedule>"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	s := &Schedule{}
+	values := map[string]string{}
+	for _, row := range data.Rows {
+		if len(row.Cells) == 0 {
+			continue
+		}
+		r := &scheduleRow{Key: row.Cells[0]}
+		if len(row.Cells) > 1 {
+			r.Value = row.Cells[1]
+		}
+		s.rows = append(s.rows, r)
+		values[r.Key] = r.Value
+		if r.Value != "" && !slices.Contains(scheduleCronFormKeys, r.Key) {
+			s.extra = append(s.extra, r)
+		}
+	}
+
+	s.RunOnce = values["fireNow"] == "true"
+	for _, key := range scheduleCronKeys {
+		if values[key] == "" {
+			s.Fields = nil
+			break
+		}
+		s.Fields = append(s.Fields, values[key])
+	}
+	if s.Fields == nil && values["schedule1"] != "" {
+		s.Fields = strings.Split(values["schedule1"], "+")
+	}
+	if !s.RunOnce && len(s.Fields) < 6 {
+		return nil, fmt.Errorf("Schedule does not contain a recurrence")
+	}
+	s.TimeZone = zoneId(values["timeZone"])
+	s.StartAt = values["startAt"]
+	s.EndAt = values["endAt"]
+	return s, nil
+}
+
+// ParseScheduleCron parses a schedule in the readable cron form
+func ParseScheduleCron(value string) (*Schedule, error) {
+	s := &Schedule{}
+	tokens := strings.Fields(value)
+	if len(tokens) == 1 && tokens[0] == scheduleRunOnce {
+		s.RunOnce = true
+		return s, nil
+	}
+	for _, token := range tokens {
+		option, optionValue, found := strings.Cut(token, "=")
+		if !found {
+			if s.TimeZone != "" || s.StartAt != "" || s.EndAt != "" {
+				return nil, fmt.Errorf("Schedule %v has cron field %v after the options", value, token)
+			}
+			if strings.Contains(token, "+") {
+				return nil, fmt.Errorf("Schedule %v has invalid cron field %v", value, token)
+			}
+			s.Fields = append(s.Fields, strings.ToUpper(token))
+			continue
+		}
+		switch option {
+		case "tz":
+			s.TimeZone = optionValue
+		case "start":
+			s.StartAt = optionValue
+		case "end":
+			s.EndAt = optionValue
+		default:
+			return nil, fmt.Errorf("Schedule %v has unsupported option %v", value, option)
+		}
+	}
+	switch len(s.Fields) {
+	case 6:
+		s.Fields = append(s.Fields, "*")
+	case 7:
+	default:
+		return nil, fmt.Errorf("Schedule %v must have 6 or 7 cron fields or be %v", value, scheduleRunOnce)
+	}
+	if s.Fields[3] != "?" && s.Fields[5] != "?" {
+		return nil, fmt.Errorf("Schedule %v must have ? as either day-of-month or day-of-week", value)
+	}
+	return s, nil
+}
+
+// HasExtraSettings returns true if the schedule has settings like calendars that the cron form cannot express
+func (s *Schedule) HasExtraSettings() bool {
+	return len(s.extra) > 0
+}
+
+// Cron returns the schedule in the readable cron form
+func (s *Schedule) Cron() string {
+	if s.RunOnce {
+		return scheduleRunOnce
+	}
+	parts := append([]string{}, s.Fields...)
+	if s.TimeZone != "" {
+		parts = append(parts, "tz="+s.TimeZone)
+	}
+	if s.StartAt != "" {
+		parts = append(parts, "start="+s.StartAt)
+	}
+	if s.EndAt != "" {
+		parts = append(parts, "end="+s.EndAt)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Equal compares the schedules semantically, regardless of their form. Extra settings are only
+// compared if both schedules are in XML form, as the cron form keeps those of the tenant.
+func (s *Schedule) Equal(other *Schedule) bool {
+	if s.RunOnce || other.RunOnce {
+		return s.RunOnce == other.RunOnce
+	}
+	if len(s.Fields) != len(other.Fields) {
+		return false
+	}
+	for i := range s.Fields {
+		if !strings.EqualFold(s.Fields[i], other.Fields[i]) {
+			return false
+		}
+	}
+	if s.rows != nil && other.rows != nil && !equalRows(s.extra, other.extra) {
+		return false
+	}
+	return s.zone() == other.zone() && s.StartAt == other.StartAt && s.EndAt == other.EndAt
+}
+
+func equalRows(first []*scheduleRow, second []*scheduleRow) bool {
+	return slices.EqualFunc(first, second, func(a *scheduleRow, b *scheduleRow) bool {
+		return *a == *b
+	})
+}
+
+// XML returns the schedule in the XML form of the tenant. The rows of base, typically the
+// schedule currently on the tenant, are kept so that settings not covered by the cron form
+// remain unchanged. Extra settings of a schedule in XML form replace those of base.
+func (s *Schedule) XML(base *Schedule) (string, error) {
+	var rows []*scheduleRow
+	if base != nil {
+		for _, r := range base.rows {
+			rows = append(rows, &scheduleRow{Key: r.Key, Value: r.Value})
+		}
+	}
+	set := func(key string, value string) {
+		for _, r := range rows {
+			if r.Key == key {
+				r.Value = value
+				return
+			}
+		}
+		rows = append(rows, &scheduleRow{Key: key, Value: value})
+	}
+
+	for _, r := range s.extra {
+		set(r.Key, r.Value)
+	}
+	if s.RunOnce {
+		set("fireNow", "true")
+	} else {
+		if base != nil && base.RunOnce {
+			set("fireNow", "false")
+		}
+		for i, key := range scheduleCronKeys {
+			set(key, s.Fields[i])
+		}
+		set("schedule1", strings.Join(s.Fields, "+"))
+		timeZone := s.zone()
+		if base == nil || base.zone() != timeZone {
+			display, err := zoneDisplay(timeZone)
+			if err != nil {
+				return "", err
+			}
+			set("timeZone", display)
+		}
+		set("startAt", s.StartAt)
+		set("endAt", s.EndAt)
+	}
+
+	var sb strings.Builder
+	for _, r := range rows {
+		sb.WriteString("<row><cell>")
+		_ = xml.EscapeText(&sb, []byte(r.Key))
+		sb.WriteString("</cell><cell>")
+		_ = xml.EscapeText(&sb, []byte(r.Value))
+		sb.WriteString("</cell></row>")
+	}
+	return sb.String(), nil
+}
+
+// zone returns the time zone of the schedule, which defaults to GMT on the tenant
+func (s *Schedule) zone() string {
+	if s.TimeZone == "" {
+		return scheduleDefaultZone
+	}
+	return s.TimeZone
+}
+
+// zoneId extracts the zone ID from the time zone of the tenant, e.g. Europe/Berlin from
+// ( UTC 1:00 ) Central European Standard Time(Europe/Berlin)
+func zoneId(display string) string {
+	start := strings.LastIndex(display, "(")
+	if start == -1 || !strings.HasSuffix(display, ")") {
+		return display
+	}
+	return display[start+1 : len(display)-1]
+}
+
+// zoneDisplay returns the time zone in the format of the tenant based on the standard offset of the zone
+func zoneDisplay(id string) (string, error) {
+	loc, err := time.LoadLocation(id)
+	if err != nil {
+		return "", fmt.Errorf("Schedule has invalid time zone %v: %w", id, err)
+	}
+	_, january := time.Date(2024, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, july := time.Date(2024, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	offset := min(january, july)
+	sign := ""
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("( UTC %v%d:%02d ) %v(%v)", sign, offset/3600, offset%3600/60, id, id), nil
+}
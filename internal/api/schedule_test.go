@@ -0,0 +1,136 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const tenantSchedule = "<row><cell>dateType</cell><cell>DAILY</cell></row>" +
+	"<row><cell>timeType</cell><cell>TIME_MINUTE</cell></row>" +
+	"<row><cell>timeZone</cell><cell>( UTC 0:00 ) Greenwich Mean Time(Etc/GMT)</cell></row>" +
+	"<row><cell>second</cell><cell>0</cell></row>" +
+	"<row><cell>minute</cell><cell>0/15</cell></row>" +
+	"<row><cell>hour</cell><cell>0-23</cell></row>" +
+	"<row><cell>day_of_month</cell><cell>?</cell></row>" +
+	"<row><cell>month</cell><cell>*</cell></row>" +
+	"<row><cell>dayOfWeek</cell><cell>*</cell></row>" +
+	"<row><cell>year</cell><cell>*</cell></row>" +
+	"<row><cell>schedule1</cell><cell>0+0/15+0-23+?+*+*+*</cell></row>"
+
+func TestParseScheduleXML(t *testing.T) {
+	s, err := ParseScheduleXML(tenantSchedule)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.False(t, s.RunOnce)
+	assert.Equal(t, []string{"0", "0/15", "0-23", "?", "*", "*", "*"}, s.Fields)
+	assert.Equal(t, "Etc/GMT", s.TimeZone)
+	assert.Equal(t, "0 0/15 0-23 ? * * * tz=Etc/GMT", s.Cron())
+}
+
+func TestParseScheduleCron(t *testing.T) {
+	s, err := ParseScheduleCron("0 0/15 8-18 ? * mon-fri tz=Europe/Berlin end=2030-12-31")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"0", "0/15", "8-18", "?", "*", "MON-FRI", "*"}, s.Fields)
+	assert.Equal(t, "Europe/Berlin", s.TimeZone)
+	assert.Equal(t, "2030-12-31", s.EndAt)
+
+	s, err = ParseScheduleCron("@once")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.True(t, s.RunOnce)
+	assert.Equal(t, "@once", s.Cron())
+}
+
+func TestParseScheduleCron_Invalid(t *testing.T) {
+	for _, value := range []string{"0 0/15 * * *", "0 0 12 1 * MON", "0 0 12 ? * * * zone=UTC", "0 0 12 ? * tz=UTC *"} {
+		_, err := ParseScheduleCron(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSchedule_Equal(t *testing.T) {
+	tenant, err := ParseScheduleXML(tenantSchedule)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+
+	same, err := ParseSchedule("0 0/15 0-23 ? * *")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.True(t, tenant.Equal(same), "Default time zone and year are equal to tenant values")
+
+	other, err := ParseSchedule("0 0/30 0-23 ? * *")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.False(t, tenant.Equal(other))
+
+	once, err := ParseSchedule("@once")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.False(t, tenant.Equal(once))
+}
+
+func TestSchedule_XML(t *testing.T) {
+	tenant, err := ParseScheduleXML(tenantSchedule)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	s, err := ParseScheduleCron("0 0 6 ? * MON tz=Asia/Singapore")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+
+	value, err := s.XML(tenant)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.Contains(t, value, "<row><cell>dateType</cell><cell>DAILY</cell></row>", "Rows of the base are kept")
+	assert.Contains(t, value, "<row><cell>schedule1</cell><cell>0+0+6+?+*+MON+*</cell></row>")
+	assert.Contains(t, value, "<row><cell>timeZone</cell><cell>( UTC 8:00 ) Asia/Singapore(Asia/Singapore)</cell></row>")
+
+	// Round trip
+	parsed, err := ParseScheduleXML(value)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.True(t, parsed.Equal(s))
+	assert.Equal(t, "0 0 6 ? * MON * tz=Asia/Singapore", parsed.Cron())
+}
+
+func TestSchedule_ExtraSettings(t *testing.T) {
+	calendarSchedule := tenantSchedule + "<row><cell>calendar</cell><cell>Holidays_DE</cell></row>"
+	tenant, err := ParseScheduleXML(calendarSchedule)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.True(t, tenant.HasExtraSettings())
+
+	s, err := ParseScheduleCron("0 0/30 0-23 ? * *")
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	value, err := s.XML(tenant)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.Contains(t, value, "<row><cell>calendar</cell><cell>Holidays_DE</cell></row>", "Calendar settings of the base are kept")
+
+	// Extra settings are compared in XML form only
+	plain, err := ParseScheduleXML(tenantSchedule)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.False(t, plain.Equal(tenant), "Calendar settings not compared")
+	value, err = tenant.XML(plain)
+	if err != nil {
+		t.Fatalf("Schedule handling failed with error - %v", err)
+	}
+	assert.Contains(t, value, "<row><cell>calendar</cell><cell>Holidays_DE</cell></row>", "Calendar settings not applied")
+}
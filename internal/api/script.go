@@ -68,19 +68,22 @@ func (sc *ScriptCollection) CopyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
-func (sc *ScriptCollection) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (bool, error) {
-	// Diff directories
-	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF")
+func (sc *ScriptCollection) CompareContent(srcDir string, tgtDir string, _ []string, _ string) ([]string, error) {
 	// It is technically possible to have an empty script collection
 	if file.Exists(srcDir+"/src/main/resources") && file.Exists(tgtDir+"/src/main/resources") {
-		return metaDiffer || diffContent(srcDir, tgtDir), nil
-	} else if !file.Exists(srcDir+"/src/main/resources") && !file.Exists(tgtDir+"/src/main/resources") {
+		return diffContent(srcDir, tgtDir), nil
+	}
+	// Diff directories
+	log.Info().Msg("Checking for changes in META-INF directory")
+	changedFiles := diffSubDirectory(srcDir, tgtDir, "META-INF", file.DirectoryDiffOptions())
+	if !file.Exists(srcDir+"/src/main/resources") && !file.Exists(tgtDir+"/src/main/resources") {
 		log.Warn().Msg("Skipping diff as /src/main/resources does not exist in both source and target")
 		log.Info().Msg("Checking for changes in metainfo.prop")
-		metainfoDiffer := DiffOptionalFile(srcDir, tgtDir, "metainfo.prop")
-		return metaDiffer || metainfoDiffer, nil
+		if DiffOptionalFile(srcDir, tgtDir, "metainfo.prop") {
+			changedFiles = append(changedFiles, "metainfo.prop")
+		}
+		return changedFiles, nil
 	}
 	log.Info().Msg("Directory /src/main/resources does not exist in either source or target")
-	return true, nil
+	return append(changedFiles, "src/main/resources"), nil
 }
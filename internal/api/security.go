@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"net/url"
+	"strings"
+)
+
+type SecurityMaterial struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+type UserCredential struct {
+	Name        string `json:"Name"`
+	Kind        string `json:"Kind"`
+	Description string `json:"Description"`
+	User        string `json:"User"`
+	Password    string `json:"Password,omitempty"`
+	CompanyId   string `json:"CompanyId"`
+}
+
+type OAuth2ClientCredential struct {
+	Name                 string `json:"Name"`
+	Description          string `json:"Description"`
+	TokenServiceUrl      string `json:"TokenServiceUrl"`
+	ClientId             string `json:"ClientId"`
+	ClientSecret         string `json:"ClientSecret,omitempty"`
+	ClientAuthentication string `json:"ClientAuthentication"`
+	Scope                string `json:"Scope"`
+	ScopeContentType     string `json:"ScopeContentType"`
+	Resource             string `json:"Resource"`
+	Audience             string `json:"Audience"`
+}
+
+type SecureParameter struct {
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	SecureParam string `json:"SecureParam,omitempty"`
+}
+
+type KeystoreEntry struct {
+	Hexalias      string `json:"Hexalias"`
+	Alias         string `json:"Alias"`
+	Type          string `json:"Type"`
+	Owner         string `json:"Owner"`
+	ValidNotAfter string `json:"ValidNotAfter"`
+}
+
+// NewSecurityMaterial returns an initialised SecurityMaterial instance.
+func NewSecurityMaterial(exe *httpclnt.HTTPExecuter) *SecurityMaterial {
+	s := new(SecurityMaterial)
+	s.exe = exe
+	return s
+}
+
+func (s *SecurityMaterial) ListUserCredentials() ([]*UserCredential, error) {
+	log.Info().Msg("Getting user credentials")
+	var credentials []*UserCredential
+	err := readAllPages("/api/v1/UserCredentials", "Get user credentials", s.exe, func(credential *UserCredential) error {
+		credentials = append(credentials, credential)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (s *SecurityMaterial) ListOAuth2ClientCredentials() ([]*OAuth2ClientCredential, error) {
+	log.Info().Msg("Getting OAuth2 client credentials")
+	var credentials []*OAuth2ClientCredential
+	err := readAllPages("/api/v1/OAuth2ClientCredentials", "Get OAuth2 client credentials", s.exe, func(credential *OAuth2ClientCredential) error {
+		credentials = append(credentials, credential)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (s *SecurityMaterial) ListSecureParameters() ([]*SecureParameter, error) {
+	log.Info().Msg("Getting secure parameters")
+	var parameters []*SecureParameter
+	err := readAllPages("/api/v1/SecureParameters", "Get secure parameters", s.exe, func(parameter *SecureParameter) error {
+		parameters = append(parameters, parameter)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parameters, nil
+}
+
+func (s *SecurityMaterial) ListKeystoreEntries() ([]*KeystoreEntry, error) {
+	log.Info().Msg("Getting keystore entries")
+	var entries []*KeystoreEntry
+	err := readAllPages("/api/v1/KeystoreEntries", "Get keystore entries", s.exe, func(entry *KeystoreEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpsertUserCredential creates the user credential, or updates it if it exists
+func (s *SecurityMaterial) UpsertUserCredential(credential *UserCredential, exists bool) error {
+	return s.upsert("UserCredentials", "user credential", credential.Name, credential, exists)
+}
+
+// UpsertOAuth2ClientCredential creates the OAuth2 client credential, or updates it if it exists
+func (s *SecurityMaterial) UpsertOAuth2ClientCredential(credential *OAuth2ClientCredential, exists bool) error {
+	return s.upsert("OAuth2ClientCredentials", "OAuth2 client credential", credential.Name, credential, exists)
+}
+
+// UpsertSecureParameter creates the secure parameter, or updates it if it exists
+func (s *SecurityMaterial) UpsertSecureParameter(parameter *SecureParameter, exists bool) error {
+	return s.upsert("SecureParameters", "secure parameter", parameter.Name, parameter, exists)
+}
+
+func (s *SecurityMaterial) upsert(collection string, description string, name string, data any, exists bool) error {
+	requestBody, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if exists {
+		log.Info().Msgf("Updating %v %v", description, name)
+		urlPath := fmt.Sprintf("/api/v1/%v('%v')", collection, url.PathEscape(strings.ReplaceAll(name, "'", "''")))
+		return modifyingCall("PUT", urlPath, requestBody, 200, fmt.Sprintf("Update %v %v", description, name), s.exe)
+	}
+	log.Info().Msgf("Creating %v %v", description, name)
+	return modifyingCall("POST", "/api/v1/"+collection, requestBody, 201, fmt.Sprintf("Create %v %v", description, name), s.exe)
+}
+
+// UpsertCertificate adds the certificate (PEM or Base64 encoded DER) to the tenant keystore with the alias, or
+// replaces the certificate of the alias if it exists
+func (s *SecurityMaterial) UpsertCertificate(alias string, certificate []byte, exists bool) error {
+	if exists {
+		log.Info().Msgf("Updating certificate %v", alias)
+	} else {
+		log.Info().Msgf("Creating certificate %v", alias)
+	}
+	// The alias is hex encoded in the URL path
+	urlPath := fmt.Sprintf("/api/v1/CertificateResources('%v')/$value?fingerprintVerified=true&returnKeystoreEntries=false&update=%v", hex.EncodeToString([]byte(alias)), exists)
+	return modifyingCallWithContentType("POST", urlPath, certificate, "application/octet-stream", 201, fmt.Sprintf("Upload certificate %v", alias), s.exe)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testCertificate = "-----BEGIN CERTIFICATE-----\nMIIBkTCB+wIJAKHBfpegPjMCMA0GCSqGSIb3DQEBCwUAMBExDzANBgNVBAMMBnBh\n-----END CERTIFICATE-----\n"
+
+func TestSecurityMaterial_Upsert(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	sm := NewSecurityMaterial(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	err := sm.UpsertUserCredential(&UserCredential{Name: "SFTP_User", Kind: "default", User: "sftpuser", Password: "secret1"}, false)
+	if err != nil {
+		t.Fatalf("UpsertUserCredential failed with error - %v", err)
+	}
+	err = sm.UpsertUserCredential(&UserCredential{Name: "SFTP_User", Kind: "default", User: "sftpuser", Password: "secret2"}, true)
+	if err != nil {
+		t.Fatalf("UpsertUserCredential failed with error - %v", err)
+	}
+	assert.Equal(t, "secret2", s.SecurityMaterial("UserCredentials", "SFTP_User")["Password"], "Password not updated")
+
+	err = sm.UpsertSecureParameter(&SecureParameter{Name: "ApiKey", SecureParam: "key"}, false)
+	if err != nil {
+		t.Fatalf("UpsertSecureParameter failed with error - %v", err)
+	}
+	err = sm.UpsertOAuth2ClientCredential(&OAuth2ClientCredential{Name: "S4_OAuth", ClientId: "client", ClientSecret: "secret", ClientAuthentication: "SendAsBodyParameter"}, false)
+	if err != nil {
+		t.Fatalf("UpsertOAuth2ClientCredential failed with error - %v", err)
+	}
+	err = sm.UpsertCertificate("partner_cert", []byte(testCertificate), false)
+	if err != nil {
+		t.Fatalf("UpsertCertificate failed with error - %v", err)
+	}
+	err = sm.UpsertCertificate("partner_cert", []byte(testCertificate), false)
+	assert.Error(t, err, "Existing certificate replaced without update")
+
+	credentials, err := sm.ListUserCredentials()
+	if err != nil {
+		t.Fatalf("ListUserCredentials failed with error - %v", err)
+	}
+	if assert.Len(t, credentials, 1, "Incorrect number of user credentials") {
+		assert.Equal(t, "sftpuser", credentials[0].User, "Incorrect user")
+		assert.Empty(t, credentials[0].Password, "Password returned")
+	}
+	oauthCredentials, err := sm.ListOAuth2ClientCredentials()
+	if err != nil {
+		t.Fatalf("ListOAuth2ClientCredentials failed with error - %v", err)
+	}
+	assert.Len(t, oauthCredentials, 1, "Incorrect number of OAuth2 client credentials")
+	parameters, err := sm.ListSecureParameters()
+	if err != nil {
+		t.Fatalf("ListSecureParameters failed with error - %v", err)
+	}
+	assert.Len(t, parameters, 1, "Incorrect number of secure parameters")
+	entries, err := sm.ListKeystoreEntries()
+	if err != nil {
+		t.Fatalf("ListKeystoreEntries failed with error - %v", err)
+	}
+	if assert.Len(t, entries, 1, "Incorrect number of keystore entries") {
+		assert.Equal(t, "partner_cert", entries[0].Alias, "Incorrect alias")
+		assert.Equal(t, "Certificate", entries[0].Type, "Incorrect type")
+	}
+}
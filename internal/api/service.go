@@ -8,6 +8,9 @@ import (
 	"github.com/spf13/cobra"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ServiceDetails struct {
@@ -18,29 +21,97 @@ type ServiceDetails struct {
 	OauthPath         string
 	OauthClientId     string
 	OauthClientSecret string
+	OauthCertificate  string
+	OauthKey          string
+	TokenCache        bool
+	TokenCacheDir     string
+	RetryMax          int
+	RetryDelay        int
+	RetryModifying    bool
 }
 
 func GetServiceDetails(cmd *cobra.Command) *ServiceDetails {
-	oauthHost := config.GetString(cmd, "oauth-host")
+	return getServiceDetails(cmd, "")
+}
+
+// GetSourceServiceDetails returns the details of the source tenant from the flags prefixed with source-,
+// e.g. --source-tmn-host, for commands that read from one tenant and write to another
+func GetSourceServiceDetails(cmd *cobra.Command) *ServiceDetails {
+	return getServiceDetails(cmd, "source-")
+}
+
+func getServiceDetails(cmd *cobra.Command, prefix string) *ServiceDetails {
+	oauthHost := config.GetString(cmd, prefix+"oauth-host")
 	if oauthHost == "" {
 		return &ServiceDetails{
-			Host:     config.GetString(cmd, "tmn-host"),
-			Userid:   config.GetString(cmd, "tmn-userid"),
-			Password: config.GetString(cmd, "tmn-password"),
+			Host:           config.GetString(cmd, prefix+"tmn-host"),
+			Userid:         config.GetString(cmd, prefix+"tmn-userid"),
+			Password:       config.GetString(cmd, prefix+"tmn-password"),
+			RetryMax:       config.GetInt(cmd, "retry-max"),
+			RetryDelay:     config.GetInt(cmd, "retry-delay"),
+			RetryModifying: config.GetBool(cmd, "retry-modifying"),
 		}
 	} else {
 		return &ServiceDetails{
-			Host:              config.GetString(cmd, "tmn-host"),
+			Host:              config.GetString(cmd, prefix+"tmn-host"),
 			OauthHost:         oauthHost,
-			OauthClientId:     config.GetString(cmd, "oauth-clientid"),
-			OauthClientSecret: config.GetString(cmd, "oauth-clientsecret"),
-			OauthPath:         config.GetString(cmd, "oauth-path"),
+			OauthClientId:     config.GetString(cmd, prefix+"oauth-clientid"),
+			OauthClientSecret: config.GetString(cmd, prefix+"oauth-clientsecret"),
+			OauthPath:         config.GetString(cmd, prefix+"oauth-path"),
+			OauthCertificate:  config.GetString(cmd, prefix+"oauth-cert"),
+			OauthKey:          config.GetString(cmd, prefix+"oauth-key"),
+			TokenCache:        config.GetBool(cmd, "token-cache"),
+			TokenCacheDir:     config.GetString(cmd, "token-cache-dir"),
+			RetryMax:          config.GetInt(cmd, "retry-max"),
+			RetryDelay:        config.GetInt(cmd, "retry-delay"),
+			RetryModifying:    config.GetBool(cmd, "retry-modifying"),
 		}
 	}
 }
 
 func InitHTTPExecuter(serviceDetails *ServiceDetails) *httpclnt.HTTPExecuter {
-	return httpclnt.New(serviceDetails.OauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthClientSecret, serviceDetails.Userid, serviceDetails.Password, serviceDetails.Host, "https", 443, true)
+	host, scheme, port := splitHost(serviceDetails.Host)
+	oauthHost, _, _ := splitHost(serviceDetails.OauthHost)
+	var exe *httpclnt.HTTPExecuter
+	if oauthHost != "" && serviceDetails.OauthCertificate != "" {
+		exe = httpclnt.NewWithClientCert(oauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthCertificate, serviceDetails.OauthKey, host, scheme, port, true)
+	} else {
+		exe = httpclnt.New(oauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthClientSecret, serviceDetails.Userid, serviceDetails.Password, host, scheme, port, true)
+	}
+	if serviceDetails.TokenCache {
+		dir := serviceDetails.TokenCacheDir
+		if dir == "" {
+			dir = httpclnt.DefaultTokenCacheDir()
+		}
+		exe.SetTokenCache(dir)
+	}
+	policy := httpclnt.DefaultRetryPolicy()
+	policy.MaxRetries = serviceDetails.RetryMax
+	if serviceDetails.RetryDelay > 0 {
+		policy.InitialDelay = time.Duration(serviceDetails.RetryDelay) * time.Second
+	}
+	policy.RetryModifying = serviceDetails.RetryModifying
+	exe.SetRetryPolicy(policy)
+	return exe
+}
+
+// splitHost returns the host name, scheme and port of the host. Hosts are called with https on port 443, unless
+// they are prefixed with http://, e.g. http://localhost:8081 of the mock-server command
+func splitHost(host string) (string, string, int) {
+	address, found := strings.CutPrefix(host, "http://")
+	if !found {
+		return host, "https", 443
+	}
+	address = strings.TrimSuffix(address, "/")
+	name, portText, found := strings.Cut(address, ":")
+	if !found {
+		return name, "http", 80
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return name, "http", 80
+	}
+	return name, "http", port
 }
 
 func modifyingCall(method string, urlPath string, content []byte, successCode int, callType string, exe *httpclnt.HTTPExecuter) error {
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+type ServiceEndpoint struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+type serviceEndpointResult struct {
+	Id          string `json:"Id"`
+	Name        string `json:"Name"`
+	Protocol    string `json:"Protocol"`
+	EntryPoints struct {
+		Results []struct {
+			Name string `json:"Name"`
+			Url  string `json:"Url"`
+			Type string `json:"Type"`
+		} `json:"results"`
+	} `json:"EntryPoints"`
+}
+
+// NewServiceEndpoint returns an initialised ServiceEndpoint instance.
+func NewServiceEndpoint(exe *httpclnt.HTTPExecuter) *ServiceEndpoint {
+	s := new(ServiceEndpoint)
+	s.exe = exe
+	return s
+}
+
+// GetURLs returns the URLs of the runtime entry points of the deployed IFlow, e.g. of its HTTPS sender adapters
+func (s *ServiceEndpoint) GetURLs(iflowId string) ([]string, error) {
+	log.Info().Msgf("Getting service endpoints of IFlow %v", iflowId)
+	urlPath := "/api/v1/ServiceEndpoints?$expand=EntryPoints"
+
+	var urls []string
+	err := readAllPages(urlPath, "Get service endpoints", s.exe, func(result *serviceEndpointResult) error {
+		// The ID of the service endpoint is the IFlow ID followed by $endpointAddress=<address>
+		if result.Name != iflowId && !strings.HasPrefix(result.Id, iflowId+"$") {
+			return nil
+		}
+		for _, entryPoint := range result.EntryPoints.Results {
+			urls = append(urls, entryPoint.Url)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-errors/errors"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ServiceKey contains the connection details of a service key of SAP BTP
+type ServiceKey struct {
+	Host              string
+	OauthHost         string
+	OauthPath         string
+	OauthClientId     string
+	OauthClientSecret string
+	OauthCertificate  string
+	OauthKey          string
+	// APIM is true for service keys of API Management (API portal)
+	APIM bool
+}
+
+type serviceKeyCredentials struct {
+	Url          string `json:"url"`
+	TokenUrl     string `json:"tokenurl"`
+	ClientId     string `json:"clientid"`
+	ClientSecret string `json:"clientsecret"`
+	Certificate  string `json:"certificate"`
+	Key          string `json:"key"`
+}
+
+type serviceKeyData struct {
+	// Service keys of Cloud Integration contain the credentials in the oauth section
+	Oauth *serviceKeyCredentials `json:"oauth"`
+	// Service keys of API Management contain the credentials at the top level
+	serviceKeyCredentials
+}
+
+// ParseServiceKey parses the service key of Cloud Integration or API Management,
+// provided either as JSON content or as the path to a JSON file
+func ParseServiceKey(value string) (*ServiceKey, error) {
+	content := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		var err error
+		content, err = os.ReadFile(value)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+	}
+	var data *serviceKeyData
+	// Keys of API Management are in camel case, e.g. tokenUrl, which the JSON decoder matches case-insensitively
+	err := json.Unmarshal(content, &data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service key: %w", err)
+	}
+
+	key := new(ServiceKey)
+	credentials := &data.serviceKeyCredentials
+	if data.Oauth != nil {
+		credentials = data.Oauth
+	} else {
+		key.APIM = true
+	}
+	if credentials.Url == "" || credentials.TokenUrl == "" || credentials.ClientId == "" {
+		return nil, fmt.Errorf("invalid service key: url, tokenurl and clientid are required")
+	}
+	if credentials.ClientSecret == "" && (credentials.Certificate == "" || credentials.Key == "") {
+		return nil, fmt.Errorf("invalid service key: clientsecret or certificate and key are required")
+	}
+
+	host, err := url.Parse(credentials.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url in service key: %w", err)
+	}
+	tokenUrl, err := url.Parse(credentials.TokenUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tokenurl in service key: %w", err)
+	}
+	key.Host = host.Host
+	key.OauthHost = tokenUrl.Host
+	key.OauthPath = tokenUrl.Path
+	key.OauthClientId = credentials.ClientId
+	key.OauthClientSecret = credentials.ClientSecret
+	key.OauthCertificate = credentials.Certificate
+	key.OauthKey = credentials.Key
+	return key, nil
+}
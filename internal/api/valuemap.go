@@ -61,13 +61,15 @@ func (vm *ValueMapping) CopyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
-func (vm *ValueMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (bool, error) {
+func (vm *ValueMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) ([]string, error) {
 	// Diff directories
 	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF")
+	changedFiles := diffSubDirectory(srcDir, tgtDir, "META-INF", file.DirectoryDiffOptions())
 	log.Info().Msg("Checking for changes in value_mapping.xml")
-	xmlDiffer := file.DiffFile(srcDir+"/value_mapping.xml", tgtDir+"/value_mapping.xml")
+	if file.DiffFile(srcDir+"/value_mapping.xml", tgtDir+"/value_mapping.xml") != nil {
+		changedFiles = append(changedFiles, "value_mapping.xml")
+	}
 	// TODO - The API for value mapping does not return metainfo.prop, so we can't compare it
 
-	return metaDiffer || xmlDiffer, nil
+	return changedFiles, nil
 }
@@ -10,7 +10,6 @@ import (
 	"github.com/engswee/flashpipe/internal/analytics"
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
-	"github.com/engswee/flashpipe/internal/repo"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/engswee/flashpipe/internal/sync"
 	"github.com/go-errors/errors"
@@ -65,9 +64,12 @@ tenant and a Git repository.`,
 	return apimCmd
 }
 
-func runSyncAPIM(cmd *cobra.Command) error {
+func runSyncAPIM(cmd *cobra.Command) (err error) {
 	log.Info().Msg("Executing sync apim command")
 
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
 	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
 	if err != nil {
 		return fmt.Errorf("security alert for --dir-git-repo: %w", err)
@@ -82,10 +84,8 @@ func runSyncAPIM(cmd *cobra.Command) error {
 	}
 	includedIds := config.GetStringSlice(cmd, "ids-include")
 	excludedIds := config.GetStringSlice(cmd, "ids-exclude")
-	commitMsg := config.GetString(cmd, "git-commit-msg")
-	commitUser := config.GetString(cmd, "git-commit-user")
-	commitEmail := config.GetString(cmd, "git-commit-email")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
+	prune := config.GetBool(cmd, "prune")
 	target := config.GetString(cmd, "target")
 	if target == "local" {
 		target = "git"
@@ -97,13 +97,20 @@ func runSyncAPIM(cmd *cobra.Command) error {
 	exe := api.InitHTTPExecuter(serviceDetails)
 
 	syncer := sync.NewSyncer(target, "APIM", exe)
+	syncer.SetPrune(prune)
+	syncer.SetReport(rep)
 	apimWorkDir := fmt.Sprintf("%v/apim", workDir)
+	if target == "git" {
+		if err = checkoutGitBranch(cmd, gitRepoDir); err != nil {
+			return err
+		}
+	}
 	err = syncer.Exec(apimWorkDir, artifactsDir, str.TrimSlice(includedIds), str.TrimSlice(excludedIds))
 	if err != nil {
 		return err
 	}
 	if target == "git" && !skipCommit {
-		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
+		err = commitToGit(cmd, gitRepoDir, nil)
 		if err != nil {
 			return err
 		}
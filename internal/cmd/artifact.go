@@ -29,6 +29,13 @@ SAP Integration Suite tenant.`,
 			default:
 				return fmt.Errorf("invalid value for --artifact-type = %v", artifactType)
 			}
+			// Validate plan format
+			planFormat := config.GetString(cmd, "plan-format")
+			switch planFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --plan-format = %v", planFormat)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -52,6 +59,10 @@ SAP Integration Suite tenant.`,
 	artifactCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
 	artifactCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during create/update")
 	artifactCmd.Flags().String("artifact-type", "Integration", "Artifact type. Allowed values: Integration, MessageMapping, ScriptCollection, ValueMapping")
+	artifactCmd.Flags().String("environment", "", "Environment of the parameters.<environment>.prop file applied on top of parameters.prop")
+	artifactCmd.Flags().Bool("ignore-diagram", false, "Ignore changes to the diagram layout (bpmndi elements) of IFlows when comparing contents")
+	artifactCmd.Flags().Bool("dry-run", false, "Show the changes that would be made to the tenant without executing them")
+	artifactCmd.Flags().String("plan-format", "table", "Output format of the changes for --dry-run. Allowed values: table, json")
 	// TODO - another flag for replacing value mapping in QAS?
 
 	_ = artifactCmd.MarkFlagRequired("artifact-id")
@@ -61,10 +72,13 @@ SAP Integration Suite tenant.`,
 	return artifactCmd
 }
 
-func runUpdateArtifact(cmd *cobra.Command) error {
+func runUpdateArtifact(cmd *cobra.Command) (err error) {
 	artifactType := config.GetString(cmd, "artifact-type")
 	log.Info().Msgf("Executing update artifact %v command", artifactType)
 
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
 	artifactId := config.GetString(cmd, "artifact-id")
 	artifactName := config.GetString(cmd, "artifact-name")
 	packageId := config.GetString(cmd, "package-id")
@@ -85,6 +99,10 @@ func runUpdateArtifact(cmd *cobra.Command) error {
 		return fmt.Errorf("security alert for --dir-work: %w", err)
 	}
 	scriptMap := config.GetStringSlice(cmd, "script-collection-map")
+	ignoreDiagram := config.GetBool(cmd, "ignore-diagram")
+	environment := config.GetString(cmd, "environment")
+	dryRun := config.GetBool(cmd, "dry-run")
+	planFormat := config.GetString(cmd, "plan-format")
 
 	defaultParamFile := fmt.Sprintf("%v/src/main/resources/parameters.prop", artifactDir)
 	if parametersFile == "" {
@@ -110,7 +128,7 @@ func runUpdateArtifact(cmd *cobra.Command) error {
 
 	// Default artifact name from Manifest file or artifact ID
 	if artifactName == "" {
-		headers, err := sync.GetManifestHeaders(manifestFile)
+		headers, err := file.ReadManifest(manifestFile)
 		if err != nil {
 			return err
 		}
@@ -131,21 +149,34 @@ func runUpdateArtifact(cmd *cobra.Command) error {
 	exe := api.InitHTTPExecuter(serviceDetails)
 
 	// Create integration package first if required
-	err = createPackage(packageId, packageName, exe)
+	var plan *sync.Plan
+	if dryRun {
+		plan = sync.NewPlan()
+	}
+	err = createPackage(packageId, packageName, exe, plan)
 	if err != nil {
 		return err
 	}
 
 	synchroniser := sync.New(exe)
+	synchroniser.SetIgnoreDiagram(ignoreDiagram)
+	synchroniser.SetEnvironment(environment)
+	synchroniser.SetReport(rep)
+	if dryRun {
+		synchroniser.SetDryRun(plan)
+	}
 
 	err = synchroniser.SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, scriptMap)
 	if err != nil {
 		return err
 	}
+	if dryRun {
+		return plan.Print(cmd.OutOrStdout(), planFormat)
+	}
 	return nil
 }
 
-func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecuter) error {
+func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecuter, plan *sync.Plan) error {
 	// Check if integration package exists
 	ip := api.NewIntegrationPackage(exe)
 	_, _, packageExists, err := ip.Get(packageId)
@@ -153,7 +184,10 @@ func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecu
 		return err
 	}
 
-	if !packageExists {
+	if !packageExists && plan != nil {
+		log.Info().Msgf("Integration package %v would be created", packageId)
+		plan.Add(&sync.PlanAction{Action: sync.ActionCreate, ArtifactId: packageId, ArtifactType: "IntegrationPackage", PackageId: packageId})
+	} else if !packageExists {
 		jsonData := new(api.PackageSingleData)
 		jsonData.Root.Id = packageId
 		jsonData.Root.Name = packageName
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func NewDeleteCommand() *cobra.Command {
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete designtime artifacts and integration packages",
+		Long: `Delete designtime artifacts and integration packages
+from SAP Integration Suite tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate the artifact type
+			artifactType := config.GetString(cmd, "artifact-type")
+			switch artifactType {
+			case "MessageMapping", "ScriptCollection", "Integration", "ValueMapping":
+			default:
+				return fmt.Errorf("invalid value for --artifact-type = %v", artifactType)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runDelete(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	deleteCmd.Flags().StringSlice("artifact-ids", nil, "Comma separated list of designtime artifact IDs")
+	deleteCmd.Flags().String("artifact-type", "Integration", "Artifact type of --artifact-ids. Allowed values: Integration, MessageMapping, ScriptCollection, ValueMapping")
+	deleteCmd.Flags().StringSlice("package-ids", nil, "Comma separated list of Integration Package IDs, deleted together with their artifacts")
+	deleteCmd.Flags().Bool("undeploy", false, "Undeploy the artifacts from the runtime before deleting them")
+	deleteCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact undeployment status")
+	deleteCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact undeployment status")
+
+	deleteCmd.MarkFlagsOneRequired("artifact-ids", "package-ids")
+	return deleteCmd
+}
+
+func runDelete(cmd *cobra.Command) error {
+	log.Info().Msg("Executing delete command")
+
+	artifactIds := str.TrimSlice(config.GetStringSlice(cmd, "artifact-ids"))
+	artifactType := config.GetString(cmd, "artifact-type")
+	packageIds := str.TrimSlice(config.GetStringSlice(cmd, "package-ids"))
+	undeploy := config.GetBool(cmd, "undeploy")
+	delayLength := config.GetInt(cmd, "delay-length")
+	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	rt := api.NewRuntime(exe)
+	ip := api.NewIntegrationPackage(exe)
+
+	if len(artifactIds) > 0 {
+		err := deleteArtifacts(artifactIds, api.NewDesigntimeArtifact(artifactType, exe), undeploy, delayLength, maxCheckLimit, rt)
+		if err != nil {
+			return err
+		}
+	}
+	if len(packageIds) > 0 {
+		err := deletePackages(packageIds, ip, undeploy, delayLength, maxCheckLimit, rt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteArtifacts(ids []string, dt api.DesigntimeArtifact, undeploy bool, delayLength int, maxCheckLimit int, rt *api.Runtime) error {
+	if undeploy {
+		err := sync.UndeployArtifacts(ids, delayLength, maxCheckLimit, rt)
+		if err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		_, _, exists, err := dt.Get(id, "active")
+		if err != nil {
+			return err
+		}
+		if !exists {
+			log.Warn().Msgf("Designtime artifact %v does not exist. Skipping deletion", id)
+			continue
+		}
+		err = dt.Delete(id)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("🏆 Designtime artifact %v deleted", id)
+	}
+	return nil
+}
+
+func deletePackages(ids []string, ip *api.IntegrationPackage, undeploy bool, delayLength int, maxCheckLimit int, rt *api.Runtime) error {
+	for _, id := range ids {
+		_, _, exists, err := ip.Get(id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			log.Warn().Msgf("Integration package %v does not exist. Skipping deletion", id)
+			continue
+		}
+		if undeploy {
+			artifacts, err := ip.GetAllArtifacts(id)
+			if err != nil {
+				return err
+			}
+			var artifactIds []string
+			for _, artifact := range artifacts {
+				artifactIds = append(artifactIds, artifact.Id)
+			}
+			err = sync.UndeployArtifacts(artifactIds, delayLength, maxCheckLimit, rt)
+			if err != nil {
+				return err
+			}
+		}
+		err = ip.Delete(id)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("🏆 Integration package %v deleted", id)
+	}
+	return nil
+}
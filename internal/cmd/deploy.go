@@ -5,9 +5,12 @@ import (
 	"github.com/engswee/flashpipe/internal/analytics"
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/dependency"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"strings"
 	"time"
 )
 
@@ -45,12 +48,17 @@ runtime of SAP Integration Suite tenant.`,
 	// To set to false, use --compare-versions=false
 	deployCmd.Flags().Bool("compare-versions", true, "Perform version comparison of design time against runtime before deployment")
 	deployCmd.Flags().String("artifact-type", "Integration", "Artifact type. Allowed values: Integration, MessageMapping, ScriptCollection, ValueMapping")
+	deployCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts, for deploying the artifacts after the artifacts they depend on")
+	deployCmd.Flags().Bool("batch-check", false, "Check the deployment status of all artifacts together instead of one by one, and report a summary")
 
 	_ = deployCmd.MarkFlagRequired("artifact-ids")
 	return deployCmd
 }
 
-func runDeploy(cmd *cobra.Command) error {
+func runDeploy(cmd *cobra.Command) (err error) {
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
 	serviceDetails := api.GetServiceDetails(cmd)
 
 	artifactType := config.GetString(cmd, "artifact-type")
@@ -60,15 +68,39 @@ func runDeploy(cmd *cobra.Command) error {
 	delayLength := config.GetInt(cmd, "delay-length")
 	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
 	compareVersions := config.GetBool(cmd, "compare-versions")
+	batchCheck := config.GetBool(cmd, "batch-check")
+	artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	if artifactsDir != "" {
+		artifactIds = orderByDependencies(str.TrimSlice(artifactIds), artifactsDir)
+	}
 
-	err := deployArtifacts(artifactIds, artifactType, delayLength, maxCheckLimit, compareVersions, serviceDetails)
+	err = deployArtifacts(artifactIds, artifactType, delayLength, maxCheckLimit, compareVersions, batchCheck, serviceDetails, rep)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func deployArtifacts(artifactIds []string, artifactType string, delayLength int, maxCheckLimit int, compareVersions bool, serviceDetails *api.ServiceDetails) error {
+// orderByDependencies sorts the artifact IDs so that referenced artifacts, e.g. IFlows called with ProcessDirect,
+// come before the artifacts using them. If the dependencies cannot be determined, the order is not changed.
+func orderByDependencies(artifactIds []string, artifactsDir string) []string {
+	graph, err := dependency.Build(artifactsDir)
+	if err != nil {
+		log.Warn().Msgf("Artifacts are deployed in the given order as their dependencies cannot be determined - %v", err)
+		return artifactIds
+	}
+	var ordered []string
+	for _, level := range graph.Order(artifactIds) {
+		ordered = append(ordered, level...)
+	}
+	log.Info().Msgf("Deploying artifacts in the order of their dependencies: %v", strings.Join(ordered, ", "))
+	return ordered
+}
+
+func deployArtifacts(artifactIds []string, artifactType string, delayLength int, maxCheckLimit int, compareVersions bool, batchCheck bool, serviceDetails *api.ServiceDetails, rep *report.Report) error {
 
 	// Initialise HTTP executer
 	exe := api.InitHTTPExecuter(serviceDetails)
@@ -82,31 +114,86 @@ func deployArtifacts(artifactIds []string, artifactType string, delayLength int,
 	artifactIds = str.TrimSlice(artifactIds)
 
 	// Loop and deploy each artifact
+	entries := make([]*report.Entry, len(artifactIds))
+	starts := make([]time.Time, len(artifactIds))
+	triggered := artifactIds
+	var triggerErr error
 	for i, id := range artifactIds {
 		log.Info().Msgf("Processing artifact %d - %v", i+1, id)
-		err := deploySingle(dt, rt, id, compareVersions)
+		starts[i] = time.Now()
+		entries[i] = &report.Entry{Id: id, Type: artifactType, Action: report.ActionDeployed}
+		err := deploySingle(dt, rt, id, compareVersions, entries[i])
 		// TODO - PRIO1 write error wrapper - https://go.dev/blog/errors-are-values
 		if err != nil {
+			rep.Add(entries[i], starts[i], err)
+			// The deployment of the artifacts already triggered is still checked and reported
+			triggerErr = err
+			triggered = artifactIds[:i]
+			break
+		}
+	}
+	if len(triggered) > 0 {
+		err := checkDeployments(rt, delayLength, maxCheckLimit, batchCheck, triggered, entries, starts, rep)
+		if err != nil {
+			if triggerErr != nil {
+				log.Error().Msgf("Deployment check of triggered artifact(s) failed - %v", err)
+				return triggerErr
+			}
 			return err
 		}
 	}
+	if triggerErr != nil {
+		return triggerErr
+	}
 
-	// Check deployment status of artifacts
-	for i, id := range artifactIds {
+	log.Info().Msg("🏆 Artifact(s) deployment completed successfully")
+	return nil
+}
+
+// checkDeployments checks the deployment status of the triggered artifacts and adds their entries to the report
+func checkDeployments(rt *api.Runtime, delayLength int, maxCheckLimit int, batchCheck bool, ids []string, entries []*report.Entry, starts []time.Time, rep *report.Report) error {
+	if batchCheck {
+		failures, err := checkAllDeploymentStatus(rt, delayLength, maxCheckLimit, ids)
+		for i, id := range ids {
+			// Without failures of the artifacts, the status could not be checked at all
+			artifactErr := err
+			if failures != nil {
+				artifactErr = nil
+				if failure, failed := failures[id]; failed {
+					artifactErr = fmt.Errorf("%v", failure)
+				}
+			}
+			rep.Add(entries[i], starts[i], artifactErr)
+		}
+		return err
+	}
+	// All triggered artifacts are checked, so that each of them is reported
+	var errs []error
+	var errorList []string
+	for i, id := range ids {
 		err := checkDeploymentStatus(rt, delayLength, maxCheckLimit, id)
+		rep.Add(entries[i], starts[i], err)
 		if err != nil {
-			return err
+			log.Error().Msgf("Artifact %d - %v deployment unsuccessful - %v", i+1, id, err)
+			errs = append(errs, err)
+			errorList = append(errorList, fmt.Sprintf("%v: %v", id, err))
+			continue
 		}
 		// TODO - PRIO1 write error wrapper - https://go.dev/blog/errors-are-values
 
 		log.Info().Msgf("Artifact %d - %v deployed successfully", i+1, id)
 	}
-
-	log.Info().Msg("🏆 Artifact(s) deployment completed successfully")
-	return nil
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return fmt.Errorf("Deployment of %d artifact(s) unsuccessful. %v", len(errs), strings.Join(errorList, "; "))
+	}
 }
 
-func deploySingle(artifact api.DesigntimeArtifact, runtime *api.Runtime, id string, compareVersions bool) error {
+func deploySingle(artifact api.DesigntimeArtifact, runtime *api.Runtime, id string, compareVersions bool, entry *report.Entry) error {
 	designtimeVer, _, exists, err := artifact.Get(id, "active")
 	if err != nil {
 		return err
@@ -114,18 +201,21 @@ func deploySingle(artifact api.DesigntimeArtifact, runtime *api.Runtime, id stri
 	if !exists {
 		return fmt.Errorf("Designtime artifact %v does not exist", id)
 	}
+	entry.VersionAfter = designtimeVer
 
 	if compareVersions == true {
 		runtimeVer, _, err := runtime.Get(id)
 		if err != nil {
 			return err
 		}
+		entry.VersionBefore = runtimeVer
 
 		// Compare designtime version with runtime version to determine if deployment is needed
 		log.Info().Msg("Comparing designtime version with runtime version")
 		log.Debug().Msgf("Designtime version = %s. Runtime version = %s", designtimeVer, runtimeVer)
 		if designtimeVer == runtimeVer {
 			log.Info().Msgf("Artifact %v with version %v already deployed. Skipping runtime deployment", id, runtimeVer)
+			entry.Action = report.ActionUnchanged
 		} else {
 			log.Info().Msgf("🚀 Artifact previously not deployed, or versions differ. Proceeding to deploy artifact %v with version %v", id, designtimeVer)
 			err = artifact.Deploy(id)
@@ -176,3 +266,79 @@ func checkDeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit
 	}
 	return nil
 }
+
+// checkAllDeploymentStatus checks the runtime status of all artifacts together with a single call per check,
+// and logs a summary of the final status of each artifact. The failures are returned by artifact ID
+func checkAllDeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit int, ids []string) (map[string]string, error) {
+	log.Info().Msgf("Checking runtime status for %d artifact(s) every %d seconds up to %d times", len(ids), delayLength, maxCheckLimit)
+
+	statuses := map[string]string{}
+	errorMessages := map[string]string{}
+	pending := ids
+	for i := 0; i < maxCheckLimit && len(pending) > 0; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(delayLength) * time.Second)
+		}
+		runtimeArtifacts, err := runtime.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		current := map[string]string{}
+		for _, artifact := range runtimeArtifacts {
+			current[artifact.Id] = artifact.Status
+		}
+
+		var stillPending []string
+		var failed []string
+		for _, id := range pending {
+			status, deployed := current[id]
+			if !deployed {
+				status = "NOT_DEPLOYED"
+			}
+			log.Info().Msgf("Check %d - Current runtime status of artifact %v = %s", i+1, id, status)
+			statuses[id] = status
+			switch status {
+			case "STARTED":
+			case "STARTING", "NOT_DEPLOYED":
+				stillPending = append(stillPending, id)
+			default:
+				failed = append(failed, id)
+			}
+		}
+		pending = stillPending
+
+		if len(failed) > 0 {
+			// Delay before getting the error details as it sometimes return 204 when the error details are not available yet
+			time.Sleep(time.Duration(delayLength) * time.Second)
+			for _, id := range failed {
+				errorMessage, err := runtime.GetErrorInfo(id)
+				if err != nil {
+					errorMessage = fmt.Sprintf("Error information not available - %v", err)
+				}
+				errorMessages[id] = errorMessage
+			}
+		}
+	}
+	for _, id := range pending {
+		errorMessages[id] = fmt.Sprintf("Artifact status remained in %s after %d checks", statuses[id], maxCheckLimit)
+		statuses[id] = "TIMEOUT"
+	}
+
+	// Summary of all artifacts in the order of the input
+	log.Info().Msg("Summary of artifact deployment status")
+	var errorList []string
+	failures := map[string]string{}
+	for _, id := range ids {
+		if errorMessage, failed := errorMessages[id]; failed {
+			log.Error().Msgf("%-12v %v - %v", statuses[id], id, errorMessage)
+			errorList = append(errorList, fmt.Sprintf("%v (%v): %v", id, statuses[id], errorMessage))
+			failures[id] = fmt.Sprintf("%v: %v", statuses[id], errorMessage)
+		} else {
+			log.Info().Msgf("%-12v %v", statuses[id], id)
+		}
+	}
+	if len(errorList) > 0 {
+		return failures, fmt.Errorf("Deployment of %d artifact(s) unsuccessful. %v", len(errorList), strings.Join(errorList, "; "))
+	}
+	return failures, nil
+}
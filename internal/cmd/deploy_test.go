@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckAllDeploymentStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"results":[{"Id":"Started_IFlow","Version":"1.0.0","Status":"STARTED"},{"Id":"Error_IFlow","Version":"1.0.0","Status":"ERROR"},{"Id":"Starting_IFlow","Version":"1.0.0","Status":"STARTING"}]}}`))
+	})
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('Error_IFlow')/ErrorInformation/$value", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"parameter":["Unresolved dependency"]}`))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	host, port := httpclnt.GetHostPort(svr.URL)
+	rt := api.NewRuntime(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	failures, err := checkAllDeploymentStatus(rt, 0, 2, []string{"Started_IFlow", "Error_IFlow", "Starting_IFlow"})
+	assert.Equal(t, map[string]string{
+		"Error_IFlow":    "ERROR: Unresolved dependency",
+		"Starting_IFlow": "TIMEOUT: Artifact status remained in STARTING after 2 checks",
+	}, failures, "Incorrect failures of artifacts")
+	if assert.Error(t, err, "Deployment errors not reported") {
+		assert.Contains(t, err.Error(), "Deployment of 2 artifact(s) unsuccessful")
+		assert.Contains(t, err.Error(), "Error_IFlow (ERROR): Unresolved dependency")
+		assert.Contains(t, err.Error(), "Starting_IFlow (TIMEOUT): Artifact status remained in STARTING after 2 checks")
+		assert.NotContains(t, err.Error(), "Started_IFlow")
+	}
+}
+
+func TestCheckAllDeploymentStatus_Started(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"results":[{"Id":"Started_IFlow","Version":"1.0.0","Status":"STARTED"}]}}`))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	host, port := httpclnt.GetHostPort(svr.URL)
+	rt := api.NewRuntime(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	failures, err := checkAllDeploymentStatus(rt, 0, 2, []string{"Started_IFlow"})
+	assert.NoError(t, err, "Deployment not successful")
+	assert.Empty(t, failures, "Failures reported for successful deployment")
+}
+
+func TestDeployArtifacts_TriggerFailure(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "user", "password", host, "http", port, true)
+
+	packageData, err := api.GetPackageDetails("../../test/testdata/FlashPipeIntegrationTest.json")
+	if err != nil {
+		t.Fatalf("GetPackageDetails failed with error - %v", err)
+	}
+	err = api.NewIntegrationPackage(exe).Create(packageData)
+	if err != nil {
+		t.Fatalf("Create package failed with error - %v", err)
+	}
+	err = api.NewDesigntimeArtifact("Integration", exe).Create("Integration_Test_IFlow", "Integration Test IFlow", "FlashPipeIntegrationTest", "../../test/testdata/artifacts/create/Integration_Test_IFlow")
+	if err != nil {
+		t.Fatalf("Create artifact failed with error - %v", err)
+	}
+
+	// The artifact triggered before the failure is still checked and reported
+	rep := report.New("deploy")
+	serviceDetails := &api.ServiceDetails{Host: svr.URL, Userid: "user", Password: "password"}
+	err = deployArtifacts([]string{"Integration_Test_IFlow", "Missing_IFlow"}, "Integration", 0, 2, false, false, serviceDetails, rep)
+	assert.ErrorContains(t, err, "Missing_IFlow does not exist")
+	if assert.Len(t, rep.Entries, 2, "Incorrect number of report entries") {
+		assert.Equal(t, "Missing_IFlow", rep.Entries[0].Id)
+		assert.Equal(t, report.ActionFailed, rep.Entries[0].Action)
+		assert.Equal(t, "Integration_Test_IFlow", rep.Entries[1].Id)
+		assert.Equal(t, report.ActionDeployed, rep.Entries[1].Action, "Triggered artifact not reported as deployed")
+	}
+}
+
+func TestCheckDeployments_ReportAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('Error_IFlow')", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"Id":"Error_IFlow","Version":"1.0.0","Status":"ERROR"}}`))
+	})
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('Error_IFlow')/ErrorInformation/$value", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"parameter":["Unresolved dependency"]}`))
+	})
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('Started_IFlow')", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"Id":"Started_IFlow","Version":"1.0.0","Status":"STARTED"}}`))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	host, port := httpclnt.GetHostPort(svr.URL)
+	rt := api.NewRuntime(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	// The artifact after the failed one is still checked and reported
+	rep := report.New("deploy")
+	ids := []string{"Error_IFlow", "Started_IFlow"}
+	entries := []*report.Entry{{Id: ids[0], Action: report.ActionDeployed}, {Id: ids[1], Action: report.ActionDeployed}}
+	starts := []time.Time{time.Now(), time.Now()}
+	err := checkDeployments(rt, 0, 2, false, ids, entries, starts, rep)
+	assert.ErrorContains(t, err, "Unresolved dependency")
+	if assert.Len(t, rep.Entries, 2, "Incorrect number of report entries") {
+		assert.Equal(t, report.ActionFailed, rep.Entries[0].Action)
+		assert.Equal(t, report.ActionDeployed, rep.Entries[1].Action, "Artifact after failure not reported as deployed")
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addGitFlags adds the flags for the branch, tag and push of the commit to the Git repository
+func addGitFlags(flags *pflag.FlagSet) {
+	flags.String("git-branch", "", "Branch to check out before the changes, created if it does not exist")
+	flags.String("git-tag", "", "Tag created on the commit, e.g. snapshot-2024-06-28")
+	flags.Bool("git-push", false, "Push the branch (and tag) to the remote after committing")
+	flags.String("git-remote", "origin", "Name or URL of the remote for --git-push")
+	flags.String("git-token", "", "Token for HTTPS authentication to the remote")
+	flags.String("git-ssh-key", "", "Private key (PEM content or file) for SSH authentication to the remote")
+	flags.String("git-ssh-key-password", "", "Password of the private key for SSH authentication")
+}
+
+// checkoutGitBranch checks out the branch of --git-branch, if set, before the changes in the Git repository
+func checkoutGitBranch(cmd *cobra.Command, gitRepoDir string) error {
+	branch := config.GetString(cmd, "git-branch")
+	if branch == "" {
+		return nil
+	}
+	return repo.CheckoutBranch(gitRepoDir, branch, config.GetString(cmd, "git-remote"))
+}
+
+// newGitChanges returns the collector of changed artifacts, or nil if no commit per artifact is requested
+// with --git-commit-per-artifact
+func newGitChanges(cmd *cobra.Command) *repo.Changes {
+	if !config.GetBool(cmd, "git-commit-per-artifact") {
+		return nil
+	}
+	return repo.NewChanges()
+}
+
+// commitToGit commits the changes in the Git repository, one commit per artifact of changes (if not nil)
+// and a commit for the remaining changes, and then tags and pushes the commit if requested
+func commitToGit(cmd *cobra.Command, gitRepoDir string, changes *repo.Changes) error {
+	commitUser := config.GetString(cmd, "git-commit-user")
+	commitEmail := config.GetString(cmd, "git-commit-email")
+	err := repo.CommitArtifacts(gitRepoDir, changes, commitUser, commitEmail)
+	if err != nil {
+		return err
+	}
+	err = repo.CommitToRepo(gitRepoDir, config.GetString(cmd, "git-commit-msg"), commitUser, commitEmail)
+	if err != nil {
+		return err
+	}
+	tag := config.GetString(cmd, "git-tag")
+	if tag != "" {
+		if err = repo.TagHead(gitRepoDir, tag); err != nil {
+			return err
+		}
+	}
+	if config.GetBool(cmd, "git-push") {
+		return repo.Push(gitRepoDir, tag, &repo.PushOptions{
+			Remote:         config.GetString(cmd, "git-remote"),
+			Token:          config.GetString(cmd, "git-token"),
+			SSHKey:         config.GetString(cmd, "git-ssh-key"),
+			SSHKeyPassword: config.GetString(cmd, "git-ssh-key-password"),
+		})
+	}
+	return nil
+}
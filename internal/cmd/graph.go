@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/dependency"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewGraphCommand() *cobra.Command {
+
+	graphCmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Show dependencies between artifacts",
+		Long: `Analyse the dependencies between the IFlows, script collections,
+message mappings and value mappings in a directory of artifacts,
+e.g. synced to Git or of a snapshot of the tenant, without connecting
+to the tenant.`,
+		Annotations: map[string]string{localCommand: "true"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate output format
+			outputFormat := config.GetString(cmd, "output-format")
+			switch outputFormat {
+			case "dot", "json", "mermaid":
+			default:
+				return fmt.Errorf("invalid value for --output-format = %v", outputFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runGraph(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	graphCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts, searched recursively")
+	graphCmd.Flags().String("output-format", "dot", "Output format of the graph. Allowed values: dot, json, mermaid")
+	graphCmd.Flags().String("output-file", "", "Write the graph to file instead of stdout")
+
+	_ = graphCmd.MarkFlagRequired("dir-artifacts")
+
+	return graphCmd
+}
+
+func runGraph(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing graph command")
+
+	artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	outputFile, err := config.GetStringWithEnvExpand(cmd, "output-file")
+	if err != nil {
+		return fmt.Errorf("security alert for --output-file: %w", err)
+	}
+	outputFormat := config.GetString(cmd, "output-format")
+
+	graph, err := dependency.Build(artifactsDir)
+	if err != nil {
+		return err
+	}
+	if _, cyclic := graph.Levels(); len(cyclic) > 0 {
+		log.Warn().Msgf("Artifacts %v have cyclic dependencies", strings.Join(cyclic, ", "))
+	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = errors.Wrap(closeErr, 0)
+			}
+		}()
+		w = f
+	}
+	switch outputFormat {
+	case "json":
+		err = dependency.WriteJSON(w, graph)
+	case "mermaid":
+		err = dependency.WriteMermaid(w, graph)
+	default:
+		err = dependency.WriteDOT(w, graph)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("🏆 Found %d dependencies between %d artifacts", len(graph.Edges), len(graph.Nodes))
+	return nil
+}
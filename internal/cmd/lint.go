@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/lint"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewLintCommand() *cobra.Command {
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate integration artifact directories offline",
+		Long: `Validate the contents of integration artifact directories before
+uploading them to the SAP Integration Suite tenant, without connecting
+to the tenant.
+
+The MANIFEST.MF, the IFlow BPMN2 files, the scripts, mappings and
+schemas they reference and the externalised parameters are checked.
+The command fails if any error is found.`,
+		Annotations: map[string]string{localCommand: "true"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate output format
+			outputFormat := config.GetString(cmd, "output-format")
+			switch outputFormat {
+			case "text", "json", "sarif":
+			default:
+				return fmt.Errorf("invalid value for --output-format = %v", outputFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runLint(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	lintCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts, searched recursively")
+	lintCmd.Flags().String("output-format", "text", "Output format of the findings. Allowed values: text, json, sarif")
+	lintCmd.Flags().String("output-file", "", "Write the findings to file instead of stdout")
+
+	_ = lintCmd.MarkFlagRequired("dir-artifacts")
+
+	return lintCmd
+}
+
+func runLint(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing lint command")
+
+	artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	outputFile, err := config.GetStringWithEnvExpand(cmd, "output-file")
+	if err != nil {
+		return fmt.Errorf("security alert for --output-file: %w", err)
+	}
+	outputFormat := config.GetString(cmd, "output-format")
+
+	result, err := lint.Run(artifactsDir)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = errors.Wrap(closeErr, 0)
+			}
+		}()
+		w = f
+	}
+	switch outputFormat {
+	case "json":
+		err = lint.WriteJSON(w, result)
+	case "sarif":
+		err = lint.WriteSARIF(w, result, cmd.Root().Version)
+	default:
+		err = lint.WriteText(w, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if errorCount := result.Errors(); errorCount > 0 {
+		return fmt.Errorf("found %d errors in %d artifacts", errorCount, len(result.Artifacts))
+	}
+	log.Info().Msgf("🏆 No errors found in %d artifacts", len(result.Artifacts))
+	return nil
+}
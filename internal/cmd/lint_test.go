@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint_SARIF(t *testing.T) {
+	artifactsDir := t.TempDir()
+	files := map[string]string{
+		"IFlow1/META-INF/MANIFEST.MF":                                         "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow1\nSAP-BundleType: IntegrationFlow\n\n",
+		"IFlow1/src/main/resources/scenarioflows/integrationflow/IFlow1.iflw": "<definitions>\n  <property>{{Missing}}</property>\n</definitions>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(artifactsDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+
+	newRoot := func() *cobra.Command {
+		rootCmd := NewCmdRoot()
+		rootCmd.AddCommand(NewLintCommand())
+		return rootCmd
+	}
+	outputFile := filepath.Join(t.TempDir(), "lint.sarif")
+	_, _, err := ExecuteCommandC(newRoot(), "lint", "--dir-artifacts", artifactsDir, "--output-format", "sarif", "--output-file", outputFile)
+	assert.ErrorContains(t, err, "found 1 errors in 1 artifacts", "Missing parameter not detected")
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	assert.Contains(t, string(content), `"ruleId": "iflow-parameter-missing"`, "Finding not written")
+	assert.Contains(t, string(content), `"startLine": 2`, "Line not written")
+
+	if err = os.WriteFile(filepath.Join(artifactsDir, "IFlow1/src/main/resources/parameters.prop"), []byte("Missing=value\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	_, output, err := ExecuteCommandC(newRoot(), "lint", "--dir-artifacts", artifactsDir)
+	assert.NoError(t, err, "Valid artifact not accepted")
+	assert.Empty(t, output, "Findings for valid artifact")
+}
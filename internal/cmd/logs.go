@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewLogsCommand() *cobra.Command {
+
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show message processing logs",
+		Long: `Show the message processing logs of SAP Integration Suite
+tenant, optionally failing when new FAILED messages appear,
+e.g. to verify IFlows after a deployment.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate output format
+			outputFormat := config.GetString(cmd, "output-format")
+			switch outputFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --output-format = %v", outputFormat)
+			}
+			// Validate the time window
+			for _, name := range []string{"since", "to", "fail-on-error-since"} {
+				if _, err := parseTimeFlag(config.GetString(cmd, name), time.Now()); err != nil {
+					return fmt.Errorf("invalid value for --%v: %w", name, err)
+				}
+			}
+			if limit := config.GetInt(cmd, "limit"); limit < 0 {
+				return fmt.Errorf("invalid value for --limit = %v", limit)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runLogs(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	logsCmd.Flags().StringSlice("iflow-ids", nil, "Comma separated list of IFlow IDs")
+	logsCmd.Flags().StringSlice("status", nil, "Comma separated list of message statuses, e.g. FAILED, RETRY, COMPLETED")
+	logsCmd.Flags().String("correlation-id", "", "Correlation ID of the messages")
+	logsCmd.Flags().String("since", "", "Show messages that ended after the timestamp (RFC 3339) or within the duration before now, e.g. 1h")
+	logsCmd.Flags().String("to", "", "Show messages that ended before the timestamp (RFC 3339) or the duration before now")
+	logsCmd.Flags().Int("limit", 100, "Maximum number of messages, starting with the most recent. 0 for no limit")
+	logsCmd.Flags().String("output-format", "table", "Output format of the messages. Allowed values: table, json")
+	logsCmd.Flags().String("fail-on-error-since", "", "Fail if there are FAILED messages that ended after the timestamp (RFC 3339) or within the duration before now, e.g. 15m")
+
+	logsCmd.MarkFlagsMutuallyExclusive("fail-on-error-since", "status")
+	logsCmd.MarkFlagsMutuallyExclusive("fail-on-error-since", "since")
+
+	return logsCmd
+}
+
+func runLogs(cmd *cobra.Command) error {
+	log.Info().Msg("Executing logs command")
+
+	now := time.Now()
+	from, _ := parseTimeFlag(config.GetString(cmd, "since"), now)
+	to, _ := parseTimeFlag(config.GetString(cmd, "to"), now)
+	failSince, _ := parseTimeFlag(config.GetString(cmd, "fail-on-error-since"), now)
+	outputFormat := config.GetString(cmd, "output-format")
+
+	filter := &api.MessageLogFilter{
+		IFlowIds:      str.TrimSlice(config.GetStringSlice(cmd, "iflow-ids")),
+		Statuses:      str.TrimSlice(config.GetStringSlice(cmd, "status")),
+		CorrelationId: config.GetString(cmd, "correlation-id"),
+		From:          from,
+		To:            to,
+		Limit:         config.GetInt(cmd, "limit"),
+	}
+	if !failSince.IsZero() {
+		filter.Statuses = []string{"FAILED"}
+		filter.From = failSince
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	logs, err := api.NewMessageLog(exe).List(filter)
+	if err != nil {
+		return err
+	}
+	err = printMessageLogs(cmd.OutOrStdout(), logs, outputFormat)
+	if err != nil {
+		return err
+	}
+
+	if !failSince.IsZero() {
+		if len(logs) > 0 {
+			return fmt.Errorf("found %d FAILED messages since %v", len(logs), failSince.UTC().Format(time.RFC3339))
+		}
+		log.Info().Msgf("🏆 No FAILED messages since %v", failSince.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// parseTimeFlag parses the value as a timestamp in RFC 3339 format, or as a duration before now.
+// An empty value returns the zero time
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v is neither a duration (e.g. 15m) nor a timestamp in RFC 3339 format (e.g. 2024-06-28T10:00:00Z)", value)
+	}
+	return t, nil
+}
+
+func printMessageLogs(w io.Writer, logs []*api.MessageProcessingLog, format string) error {
+	switch format {
+	case "json":
+		if logs == nil {
+			logs = []*api.MessageProcessingLog{}
+		}
+		content, err := json.MarshalIndent(logs, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", content)
+		return err
+	case "table":
+		if len(logs) == 0 {
+			_, err := fmt.Fprintln(w, "No messages found.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "LOG END (UTC)\tIFLOW\tSTATUS\tMESSAGE ID\tCORRELATION ID")
+		for _, mpl := range logs {
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", mpl.LogEnd.UTC().Format(time.DateTime), mpl.IFlowId, mpl.Status, mpl.MessageGuid, mpl.CorrelationId)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		// Details of the messages are listed below the table
+		for _, mpl := range logs {
+			if mpl.ErrorInformation == "" && len(mpl.Attachments) == 0 && len(mpl.CustomHeaderProperties) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\nMessage %v (%v):\n", mpl.MessageGuid, mpl.IFlowId)
+			if mpl.ErrorInformation != "" {
+				fmt.Fprintf(w, "  Error: %v\n", strings.ReplaceAll(mpl.ErrorInformation, "\n", "\n         "))
+			}
+			for _, attachment := range mpl.Attachments {
+				fmt.Fprintf(w, "  Attachment: %v (%v, %d bytes)\n", attachment.Name, attachment.ContentType, attachment.Size)
+			}
+			for _, property := range mpl.CustomHeaderProperties {
+				fmt.Fprintf(w, "  Header: %v = %v\n", property.Name, property.Value)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %v", format)
+	}
+}
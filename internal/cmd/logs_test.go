@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2024, 6, 28, 10, 0, 0, 0, time.UTC)
+
+	since, err := parseTimeFlag("15m", now)
+	if err != nil {
+		t.Fatalf("parseTimeFlag failed with error - %v", err)
+	}
+	assert.Equal(t, time.Date(2024, 6, 28, 9, 45, 0, 0, time.UTC), since, "Incorrect time of duration")
+
+	since, err = parseTimeFlag("2024-06-27T08:00:00+02:00", now)
+	if err != nil {
+		t.Fatalf("parseTimeFlag failed with error - %v", err)
+	}
+	assert.True(t, time.Date(2024, 6, 27, 6, 0, 0, 0, time.UTC).Equal(since), "Incorrect time of timestamp")
+
+	_, err = parseTimeFlag("yesterday", now)
+	assert.ErrorContains(t, err, "is neither a duration", "Invalid value not detected")
+}
+
+func TestLogs_FailOnErrorSince(t *testing.T) {
+	s := mock.NewServer()
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "OldFailure", IFlowId: "IFlow1", Status: "FAILED", LogEnd: time.Now().Add(-time.Hour)})
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "Success", IFlowId: "IFlow1", Status: "COMPLETED", LogEnd: time.Now()})
+	s.AddMessageLog(&mock.MessageLog{MessageGuid: "NewFailure", IFlowId: "IFlow2", Status: "FAILED", LogEnd: time.Now(), ErrorInformation: "Connection refused"})
+	svr := s.Start()
+	defer svr.Close()
+	// Use Basic Auth with the mock server instead of the OAuth client of the test tenant
+	for _, key := range []string{"FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		t.Setenv(key, "")
+	}
+
+	newRoot := func() *cobra.Command {
+		rootCmd := NewCmdRoot()
+		rootCmd.AddCommand(NewLogsCommand())
+		return rootCmd
+	}
+
+	_, output, err := ExecuteCommandC(newRoot(), "logs", "--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password",
+		"--iflow-ids", "IFlow1", "--fail-on-error-since", "15m")
+	assert.NoError(t, err, "Old failure not ignored")
+	assert.Contains(t, output, "No messages found.", "Incorrect output")
+
+	_, output, err = ExecuteCommandC(newRoot(), "logs", "--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password",
+		"--fail-on-error-since", "15m")
+	assert.ErrorContains(t, err, "found 1 FAILED messages since", "New failure not detected")
+	assert.Contains(t, output, "Connection refused", "Error information not shown")
+}
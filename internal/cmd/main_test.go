@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/mock"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests against a mock tenant if no live tenant is configured with FLASHPIPE_TMN_HOST
+func TestMain(m *testing.M) {
+	stop := mock.StartTestTenant()
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
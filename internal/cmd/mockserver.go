@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewMockServerCommand() *cobra.Command {
+
+	mockServerCmd := &cobra.Command{
+		Use:   "mock-server",
+		Short: "Run a local mock of Cloud Integration and API Management",
+		Long: `Run an in-memory mock of the Cloud Integration and API Management
+APIs used by FlashPipe, for trying out commands and running tests
+without a live SAP Integration Suite tenant.
+
+Point the other commands to the mock server with
+--tmn-host http://localhost:<port> and any credentials.`,
+		Annotations: map[string]string{localCommand: "true"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if port := config.GetInt(cmd, "port"); port <= 0 || port > 65535 {
+				return fmt.Errorf("invalid value for --port = %v", port)
+			}
+			if config.GetInt(cmd, "page-size") < 0 {
+				return fmt.Errorf("invalid value for --page-size = %v", config.GetInt(cmd, "page-size"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runMockServer(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	mockServerCmd.Flags().Int("port", 8081, "Local port of the mock server")
+	mockServerCmd.Flags().Int("page-size", 0, "Maximum number of results per page of OData collections, 0 to disable paging")
+
+	return mockServerCmd
+}
+
+func runMockServer(cmd *cobra.Command) error {
+	port := config.GetInt(cmd, "port")
+
+	s := mock.NewServer()
+	s.PageSize = config.GetInt(cmd, "page-size")
+	svr := &http.Server{Addr: fmt.Sprintf("localhost:%d", port), Handler: s}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = svr.Shutdown(context.Background())
+	}()
+
+	log.Info().Msgf("🚀 Mock server listening on http://localhost:%d", port)
+	if err := svr.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, 0)
+	}
+	log.Info().Msg("🛑 Mock server stopped")
+	return nil
+}
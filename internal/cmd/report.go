@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// newReport returns a report for the command, or nil if no report is requested with --report
+func newReport(cmd *cobra.Command) *report.Report {
+	if config.GetString(cmd, "report") == "" {
+		return nil
+	}
+	return report.New(cmd.CommandPath())
+}
+
+// writeReport writes the report also when the command failed, and returns the error of the command.
+// An error writing the report is only returned when the command itself was successful
+func writeReport(cmd *cobra.Command, rep *report.Report, err error) error {
+	if rep == nil {
+		return err
+	}
+	file, writeErr := config.GetStringWithEnvExpand(cmd, "report")
+	if writeErr == nil {
+		writeErr = rep.Write(file, config.GetString(cmd, "report-format"))
+	}
+	if writeErr != nil {
+		if err != nil {
+			log.Error().Msgf("Report could not be written - %v", writeErr)
+			return err
+		}
+		return writeErr
+	}
+	log.Info().Msgf("Report written to %v", file)
+	return err
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewRestoreCommand() *cobra.Command {
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore integration packages from Git to tenant",
+		Long: `Restore integration packages and their artifacts from a Git
+repository snapshot to the SAP Integration Suite tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate plan format
+			planFormat := config.GetString(cmd, "plan-format")
+			switch planFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --plan-format = %v", planFormat)
+			}
+			// Validate parallelism
+			if parallelism := config.GetInt(cmd, "parallelism"); parallelism < 1 {
+				return fmt.Errorf("invalid value for --parallelism = %v", parallelism)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runRestore(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	restoreCmd.Flags().String("dir-git-repo", "", "Directory of Git repository")
+	restoreCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts (grouped into packages)")
+	restoreCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
+	restoreCmd.Flags().StringSlice("ids-include", nil, "List of included package IDs")
+	restoreCmd.Flags().StringSlice("ids-exclude", nil, "List of excluded package IDs")
+	restoreCmd.Flags().Int("parallelism", 1, "Number of artifacts processed concurrently")
+	restoreCmd.Flags().Bool("ignore-diagram", false, "Ignore changes to the diagram layout (bpmndi elements) of IFlows when comparing contents")
+	restoreCmd.Flags().Bool("dry-run", false, "Show the changes that would be made to the tenant without executing them")
+	restoreCmd.Flags().String("plan-format", "table", "Output format of the changes for --dry-run. Allowed values: table, json")
+
+	_ = restoreCmd.MarkFlagRequired("dir-git-repo")
+	restoreCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+
+	return restoreCmd
+}
+
+func runRestore(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing snapshot restore command")
+
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
+	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-git-repo: %w", err)
+	}
+	artifactsBaseDir, err := config.GetStringWithEnvExpandWithDefault(cmd, "dir-artifacts", gitRepoDir)
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	workDir, err := config.GetStringWithEnvExpand(cmd, "dir-work")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-work: %w", err)
+	}
+	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
+	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	ignoreDiagram := config.GetBool(cmd, "ignore-diagram")
+	parallelism := config.GetInt(cmd, "parallelism")
+	dryRun := config.GetBool(cmd, "dry-run")
+	planFormat := config.GetString(cmd, "plan-format")
+
+	var plan *sync.Plan
+	if dryRun {
+		plan = sync.NewPlan()
+	}
+	serviceDetails := api.GetServiceDetails(cmd)
+	err = restoreTenantSnapshot(serviceDetails, artifactsBaseDir, workDir, ignoreDiagram, parallelism, includedIds, excludedIds, plan, rep)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return plan.Print(cmd.OutOrStdout(), planFormat)
+	}
+	return nil
+}
+
+func restoreTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, ignoreDiagram bool, parallelism int, includedIds []string, excludedIds []string, plan *sync.Plan, rep *report.Report) error {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msg("📢 Begin restoring snapshot to the tenant")
+
+	ids, err := getSnapshotPackageIds(artifactsBaseDir)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("No package directories found in %v", artifactsBaseDir)
+	}
+
+	// Initialise HTTP executer
+	exe := api.InitHTTPExecuter(serviceDetails)
+	ip := api.NewIntegrationPackage(exe)
+	synchroniser := sync.New(exe)
+	synchroniser.SetIgnoreDiagram(ignoreDiagram)
+	synchroniser.SetParallelism(parallelism)
+	synchroniser.SetReport(rep)
+	if plan != nil {
+		synchroniser.SetDryRun(plan)
+	}
+
+	log.Info().Msgf("Processing %d packages", len(ids))
+	for i, id := range ids {
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("Processing package %d/%d - ID: %v", i+1, len(ids), id)
+		// Filter in/out packages
+		if str.FilterIDs(id, includedIds, excludedIds) {
+			continue
+		}
+		packageWorkingDir := fmt.Sprintf("%v/%v", workDir, id)
+		packageArtifactsDir := fmt.Sprintf("%v/%v", artifactsBaseDir, id)
+
+		_, readOnly, packageExists, err := ip.Get(id)
+		if err != nil {
+			return err
+		}
+		if readOnly {
+			log.Warn().Msgf("Skipping package %v as it is Configure-only in the tenant", id)
+			continue
+		}
+		if !packageExists {
+			err = restorePackage(ip, id, packageArtifactsDir, plan)
+			if err != nil {
+				return err
+			}
+		}
+		err = synchroniser.ArtifactsToTenant(id, packageWorkingDir, packageArtifactsDir, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msg("🏆 Completed restoring snapshot to the tenant")
+	return nil
+}
+
+func restorePackage(ip *api.IntegrationPackage, packageId string, packageArtifactsDir string, plan *sync.Plan) error {
+	// Use package details from snapshot if it was taken with --sync-package-details
+	packageFile := fmt.Sprintf("%v/%v.json", packageArtifactsDir, packageId)
+	var packageDetails *api.PackageSingleData
+	if file.Exists(packageFile) {
+		log.Info().Msgf("Getting package details from %v file", packageFile)
+		var err error
+		packageDetails, err = api.GetPackageDetails(packageFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Info().Msgf("Package file %v not found, package %v will be created with default details", packageFile, packageId)
+		packageDetails = new(api.PackageSingleData)
+		packageDetails.Root.Id = packageId
+		packageDetails.Root.Name = packageId
+		packageDetails.Root.ShortText = packageId
+		packageDetails.Root.Version = "1.0.0"
+	}
+	if plan != nil {
+		log.Info().Msgf("Integration package %v would be created", packageId)
+		plan.Add(&sync.PlanAction{Action: sync.ActionCreate, ArtifactId: packageId, ArtifactType: "IntegrationPackage", PackageId: packageId})
+		return nil
+	}
+	err := ip.Create(packageDetails)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Integration package %v created", packageId)
+	return nil
+}
+
+// getSnapshotPackageIds returns the package IDs of the directories written by
+// the snapshot command, i.e. <dir-artifacts>/<packageId>
+func getSnapshotPackageIds(artifactsBaseDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Clean(artifactsBaseDir))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var ids []string
+	for _, entry := range entries {
+		// Skip files and hidden directories like .git
+		if !entry.IsDir() || entry.Name()[0] == '.' {
+			continue
+		}
+		ids = append(ids, entry.Name())
+	}
+	return ids, nil
+}
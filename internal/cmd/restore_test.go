@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetSnapshotPackageIds(t *testing.T) {
+	ids, err := getSnapshotPackageIds("../../test/testdata/artifacts")
+	if err != nil {
+		t.Fatalf("getSnapshotPackageIds failed with error - %v", err)
+	}
+
+	assert.Equal(t, []string{"collection", "create", "update"}, ids, "Incorrect package IDs")
+}
+
+func TestRestore(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	// Use Basic Auth with the mock server instead of the OAuth client of the test tenant
+	for _, key := range []string{"FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		t.Setenv(key, "")
+	}
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "user", "password", host, "http", port, true)
+	ip := api.NewIntegrationPackage(exe)
+	dt := api.NewDesigntimeArtifact("Integration", exe)
+
+	// Snapshot with a package taken with --sync-package-details and a package that is excluded
+	gitRepoDir := t.TempDir()
+	copyDir := func(src string, dst string) {
+		if err := file.ReplaceDir(src, filepath.Join(gitRepoDir, dst)); err != nil {
+			t.Fatalf("ReplaceDir failed with error - %v", err)
+		}
+	}
+	copyDir("../../test/testdata/artifacts/create/Integration_Test_IFlow", "FlashPipeIntegrationTest/Integration_Test_IFlow")
+	if err := file.CopyFile("../../test/testdata/FlashPipeIntegrationTest.json", filepath.Join(gitRepoDir, "FlashPipeIntegrationTest/FlashPipeIntegrationTest.json")); err != nil {
+		t.Fatalf("CopyFile failed with error - %v", err)
+	}
+	copyDir("../../test/testdata/artifacts/create/Integration_Test_Value_Mapping", "Excluded_Package/Integration_Test_Value_Mapping")
+
+	newRoot := func() *cobra.Command {
+		rootCmd := NewCmdRoot()
+		snapshotCmd := NewSnapshotCommand()
+		snapshotCmd.AddCommand(NewRestoreCommand())
+		rootCmd.AddCommand(snapshotCmd)
+		return rootCmd
+	}
+	restore := func(args ...string) string {
+		t.Helper()
+		args = append([]string{"snapshot", "restore", "--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password",
+			"--dir-git-repo", gitRepoDir, "--dir-work", t.TempDir(), "--ids-exclude", "Excluded_Package"}, args...)
+		_, output, err := ExecuteCommandC(newRoot(), args...)
+		if err != nil {
+			t.Fatalf("snapshot restore failed with error - %v", err)
+		}
+		return output
+	}
+	packageExists := func(id string) bool {
+		t.Helper()
+		_, _, exists, err := ip.Get(id)
+		if err != nil {
+			t.Fatalf("Get package failed with error - %v", err)
+		}
+		return exists
+	}
+
+	// 1 - Dry run does not change the tenant
+	output := restore("--dry-run", "--plan-format", "json")
+	assert.Contains(t, output, `"artifactId": "FlashPipeIntegrationTest"`, "Package creation not planned")
+	assert.Contains(t, output, `"artifactId": "Integration_Test_IFlow"`, "Artifact creation not planned")
+	assert.NotContains(t, output, "Integration_Test_Value_Mapping", "Excluded package planned")
+	assert.False(t, packageExists("FlashPipeIntegrationTest"), "Package created with --dry-run")
+
+	// 2 - Package and artifact are created, excluded package is not
+	restore()
+	assert.True(t, packageExists("FlashPipeIntegrationTest"), "Package not created")
+	assert.False(t, packageExists("Excluded_Package"), "Excluded package created")
+	version, _, exists, err := dt.Get("Integration_Test_IFlow", "active")
+	if err != nil {
+		t.Fatalf("Get artifact failed with error - %v", err)
+	}
+	assert.True(t, exists, "Artifact not created")
+	assert.Equal(t, "1.0.0", version, "Incorrect version of created artifact")
+
+	// 3 - Changed artifact is updated
+	copyDir("../../test/testdata/artifacts/update/Integration_Test_IFlow", "FlashPipeIntegrationTest/Integration_Test_IFlow")
+	restore()
+	version, _, _, err = dt.Get("Integration_Test_IFlow", "active")
+	if err != nil {
+		t.Fatalf("Get artifact failed with error - %v", err)
+	}
+	assert.Equal(t, "1.0.1", version, "Artifact not updated")
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/httpclnt"
 	"github.com/engswee/flashpipe/internal/logger"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -37,6 +39,8 @@ automating time-consuming manual tasks like:
 	}
 
 	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/flashpipe.yaml)")
+	rootCmd.PersistentFlags().String("service-key", "", "Service key of SAP BTP (JSON content or file) with the host and OAuth credentials of the tenant")
+	rootCmd.PersistentFlags().String("tenant", "", "Name of the tenant profile under tenants in the config file")
 
 	// Define cobra flags, the default value has the lowest (least significant) precedence
 	rootCmd.PersistentFlags().String("tmn-host", "", "Host for tenant management node of Cloud Integration excluding https://")
@@ -46,12 +50,23 @@ automating time-consuming manual tasks like:
 	rootCmd.PersistentFlags().String("oauth-clientid", "", "Client ID for using OAuth")
 	rootCmd.PersistentFlags().String("oauth-clientsecret", "", "Client Secret for using OAuth")
 	rootCmd.PersistentFlags().String("oauth-path", "/oauth/token", "Path for OAuth token server")
+	rootCmd.PersistentFlags().String("oauth-cert", "", "Client certificate (PEM content or file) for using OAuth with mTLS instead of client secret")
+	rootCmd.PersistentFlags().String("oauth-key", "", "Private key (PEM content or file) of the client certificate for using OAuth with mTLS")
+	rootCmd.PersistentFlags().Bool("token-cache", false, "Cache the OAuth token encrypted on disk and reuse it in subsequent calls until it expires")
+	rootCmd.PersistentFlags().String("token-cache-dir", "", "Directory of the OAuth token cache (default is flashpipe in the user cache directory)")
+	rootCmd.PersistentFlags().Int("retry-max", 3, "Maximum number of retries for HTTP requests failing with a transient error (429, 502, 503, 504 or connection error)")
+	rootCmd.PersistentFlags().Int("retry-delay", 1, "Delay in seconds before the first retry, doubled for each subsequent retry")
+	rootCmd.PersistentFlags().Bool("retry-modifying", false, "Retry also non-idempotent (POST, PUT, DELETE) HTTP requests")
+	rootCmd.PersistentFlags().String("report", "", "Write a report of the processed artifacts to file")
+	rootCmd.PersistentFlags().String("report-format", "json", "Format of the report. Allowed values: json, junit")
 
 	rootCmd.PersistentFlags().Bool("debug", false, "Show debug logs")
 
 	_ = rootCmd.MarkPersistentFlagRequired("tmn-host")
 	rootCmd.MarkFlagsRequiredTogether("tmn-userid", "tmn-password")
-	rootCmd.MarkFlagsRequiredTogether("oauth-host", "oauth-clientid", "oauth-clientsecret")
+	rootCmd.MarkFlagsRequiredTogether("oauth-host", "oauth-clientid")
+	rootCmd.MarkFlagsRequiredTogether("oauth-cert", "oauth-key")
+	rootCmd.MarkFlagsMutuallyExclusive("oauth-clientsecret", "oauth-cert")
 
 	return rootCmd
 }
@@ -62,6 +77,8 @@ func Execute() {
 
 	rootCmd := NewCmdRoot()
 	rootCmd.AddCommand(NewDeployCommand())
+	rootCmd.AddCommand(NewUndeployCommand())
+	rootCmd.AddCommand(NewDeleteCommand())
 	syncCmd := NewSyncCommand()
 	syncCmd.AddCommand(NewAPIMCommand())
 	rootCmd.AddCommand(syncCmd)
@@ -69,7 +86,20 @@ func Execute() {
 	updateCmd.AddCommand(NewArtifactCommand())
 	updateCmd.AddCommand(NewPackageCommand())
 	rootCmd.AddCommand(updateCmd)
-	rootCmd.AddCommand(NewSnapshotCommand())
+	rootCmd.AddCommand(NewTransportCommand())
+	snapshotCmd := NewSnapshotCommand()
+	snapshotCmd.AddCommand(NewRestoreCommand())
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(NewLogsCommand())
+	rootCmd.AddCommand(NewTestCommand())
+	securityCmd := NewSecurityCommand()
+	securityCmd.AddCommand(NewSecurityApplyCommand())
+	securityCmd.AddCommand(NewSecurityListCommand())
+	securityCmd.AddCommand(NewSecurityCheckCommand())
+	rootCmd.AddCommand(securityCmd)
+	rootCmd.AddCommand(NewLintCommand())
+	rootCmd.AddCommand(NewGraphCommand())
+	rootCmd.AddCommand(NewMockServerCommand())
 
 	err := rootCmd.Execute()
 
@@ -80,6 +110,9 @@ func Execute() {
 	}
 }
 
+// localCommand is the annotation of commands that run without a tenant
+const localCommand = "local"
+
 func initializeConfig(cmd *cobra.Command) error {
 	cfgFile := config.GetString(cmd, "config")
 	if cfgFile != "" {
@@ -113,30 +146,180 @@ func initializeConfig(cmd *cobra.Command) error {
 	viper.AutomaticEnv()
 
 	// Bind the current command's flags to viper
-	bindFlags(cmd)
+	if err := bindFlags(cmd); err != nil {
+		return err
+	}
 
 	// Set debug flag from command line to viper
 	if !viper.IsSet("debug") {
 		viper.Set("debug", config.GetBool(cmd, "debug"))
 	}
 
+	// Local commands do not connect to a tenant, so the tenant flags are not required
+	if cmd.Annotations[localCommand] == "true" {
+		_ = cmd.Flags().SetAnnotation("tmn-host", cobra.BashCompOneRequiredFlag, []string{"false"})
+		logger.InitConsoleLogger(viper.GetBool("debug"))
+		return nil
+	}
+
+	if err := applyServiceKey(cmd, ""); err != nil {
+		return err
+	}
+	if cmd.Flags().Lookup("source-service-key") != nil {
+		if err := applyServiceKey(cmd, "source-"); err != nil {
+			return err
+		}
+	}
+
+	switch config.GetString(cmd, "report-format") {
+	case "json", "junit":
+	default:
+		return fmt.Errorf("invalid value for --report-format = %v", config.GetString(cmd, "report-format"))
+	}
+
 	if config.GetString(cmd, "oauth-host") == "" && config.GetString(cmd, "tmn-userid") == "" {
 		return fmt.Errorf("required flag \"tmn-userid\" (Basic Auth) or \"oauth-host\" (OAuth) not set")
 	}
+	if err := verifyOAuthCredentials(cmd, ""); err != nil {
+		return err
+	}
+	if cmd.Flags().Lookup("source-oauth-host") != nil {
+		if err := verifyOAuthCredentials(cmd, "source-"); err != nil {
+			return err
+		}
+	}
 
 	logger.InitConsoleLogger(viper.GetBool("debug"))
 
 	return nil
 }
 
+// applyServiceKey sets the host and OAuth flags from the service key, unless they are set explicitly
+func applyServiceKey(cmd *cobra.Command, prefix string) error {
+	value := config.GetString(cmd, prefix+"service-key")
+	if value == "" {
+		return nil
+	}
+	key, err := api.ParseServiceKey(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for --%vservice-key: %w", prefix, err)
+	}
+	if key.APIM && cmd.Name() != "apim" {
+		log.Warn().Msgf("--%vservice-key is a service key of API Management, but command %v is for Cloud Integration", prefix, cmd.Name())
+	} else if !key.APIM && cmd.Name() == "apim" {
+		log.Warn().Msgf("--%vservice-key is a service key of Cloud Integration, but command %v is for API Management", prefix, cmd.Name())
+	}
+	values := map[string]string{
+		"tmn-host":       key.Host,
+		"oauth-host":     key.OauthHost,
+		"oauth-path":     key.OauthPath,
+		"oauth-clientid": key.OauthClientId,
+	}
+	// Certificate keys are used with mTLS, which is mutually exclusive with the client secret
+	if key.OauthCertificate != "" {
+		values["oauth-cert"] = key.OauthCertificate
+		values["oauth-key"] = key.OauthKey
+	} else {
+		values["oauth-clientsecret"] = key.OauthClientSecret
+	}
+	for name, val := range values {
+		f := cmd.Flags().Lookup(prefix + name)
+		if f == nil || f.Changed || val == "" {
+			continue
+		}
+		if err = cmd.Flags().Set(prefix+name, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyOAuthCredentials checks that OAuth has either a client secret or a valid client certificate
+func verifyOAuthCredentials(cmd *cobra.Command, prefix string) error {
+	if config.GetString(cmd, prefix+"oauth-host") == "" {
+		return nil
+	}
+	certificate := config.GetString(cmd, prefix+"oauth-cert")
+	if certificate == "" {
+		if config.GetString(cmd, prefix+"oauth-clientsecret") == "" {
+			return fmt.Errorf("required flag \"%voauth-clientsecret\" or \"%voauth-cert\" (mTLS) not set", prefix, prefix)
+		}
+		return nil
+	}
+	if _, err := httpclnt.LoadClientCertificate(certificate, config.GetString(cmd, prefix+"oauth-key")); err != nil {
+		return fmt.Errorf("invalid value for --%voauth-cert/--%voauth-key: %w", prefix, prefix, err)
+	}
+	return nil
+}
+
 // Bind each cobra flag to its associated viper configuration (config file and environment variable)
-func bindFlags(cmd *cobra.Command) {
+func bindFlags(cmd *cobra.Command) error {
+	tenant, err := getTenantProfile(cmd, "tenant")
+	if err != nil {
+		return err
+	}
+	sourceTenant, err := getTenantProfile(cmd, "source-tenant")
+	if err != nil {
+		return err
+	}
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		configName := f.Name
+		if f.Changed {
+			return
+		}
+		// Apply the value of the tenant profile when the flag is not set as environment variable
+		envName := "FLASHPIPE_" + strings.ToUpper(strings.ReplaceAll(configName, "-", "_"))
+		if _, found := os.LookupEnv(envName); !found {
+			if val, found := getProfileValue(cmd, tenant, sourceTenant, configName); found {
+				cmd.Flags().Set(configName, fmt.Sprintf("%v", val))
+				return
+			}
+		}
 		// Apply the viper config value to the flag when the flag is not set and viper has a value
-		if !f.Changed && viper.IsSet(configName) {
+		if viper.IsSet(configName) {
 			val := viper.Get(configName)
 			cmd.Flags().Set(configName, fmt.Sprintf("%v", val))
 		}
 	})
+	return nil
+}
+
+// getTenantProfile returns the name of the tenant profile in the flag, and checks that it exists in the config file
+func getTenantProfile(cmd *cobra.Command, flagName string) (string, error) {
+	if cmd.Flags().Lookup(flagName) == nil {
+		return "", nil
+	}
+	tenant := config.GetString(cmd, flagName)
+	if tenant == "" {
+		tenant = viper.GetString(flagName)
+	}
+	if tenant != "" && !viper.IsSet("tenants."+tenant) {
+		return "", fmt.Errorf("tenant profile %v for --%v not found under tenants in config file", tenant, flagName)
+	}
+	return tenant, nil
+}
+
+// getProfileValue returns the value of the flag from the tenant profile. Flags prefixed with source- are
+// taken from the profile of --source-tenant. The values under apim of the profile take precedence for
+// the API Management commands
+func getProfileValue(cmd *cobra.Command, tenant string, sourceTenant string, flagName string) (any, bool) {
+	key := flagName
+	if key == "tenant" || key == "source-tenant" {
+		return nil, false
+	}
+	if sourceTenant != "" && strings.HasPrefix(key, "source-") {
+		tenant = sourceTenant
+		key = strings.TrimPrefix(key, "source-")
+	}
+	if tenant == "" {
+		return nil, false
+	}
+	profileKey := "tenants." + tenant + "."
+	if cmd.Name() == "apim" && viper.IsSet(profileKey+"apim."+key) {
+		return viper.Get(profileKey + "apim." + key), true
+	}
+	if viper.IsSet(profileKey + key) {
+		return viper.Get(profileKey + key), true
+	}
+	return nil, false
 }
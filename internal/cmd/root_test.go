@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+const testTenantsConfig = `tenants:
+  dev:
+    tmn-host: dev.hana.ondemand.com
+    oauth-host: dev.authentication.hana.ondemand.com
+    oauth-clientid: dev-client
+    oauth-clientsecret: dev-secret
+    apim:
+      tmn-host: dev-apim.hana.ondemand.com
+  qas:
+    tmn-host: qas.hana.ondemand.com
+    tmn-userid: qas-user
+    tmn-password: qas-password
+`
+
+func executeWithTenantsConfig(t *testing.T, name string, args ...string) (*api.ServiceDetails, *api.ServiceDetails, error) {
+	t.Cleanup(viper.Reset)
+	// Environment variables of the integration tests take precedence over the tenant profiles
+	for _, env := range []string{"FLASHPIPE_TMN_HOST", "FLASHPIPE_TMN_USERID", "FLASHPIPE_TMN_PASSWORD", "FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		if val, found := os.LookupEnv(env); found {
+			_ = os.Unsetenv(env)
+			t.Cleanup(func() { _ = os.Setenv(env, val) })
+		}
+	}
+	configFile := t.TempDir() + "/flashpipe.yaml"
+	err := os.WriteFile(configFile, []byte(testTenantsConfig), os.ModePerm)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+
+	var target, source *api.ServiceDetails
+	testCmd := &cobra.Command{
+		Use: name,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target = api.GetServiceDetails(cmd)
+			source = api.GetSourceServiceDetails(cmd)
+			return nil
+		},
+	}
+	testCmd.Flags().String("source-tenant", "", "")
+	testCmd.Flags().String("source-tmn-host", "", "")
+	testCmd.Flags().String("source-tmn-userid", "", "")
+	testCmd.Flags().String("source-tmn-password", "", "")
+	testCmd.Flags().String("source-oauth-host", "", "")
+	rootCmd := NewCmdRoot()
+	rootCmd.AddCommand(testCmd)
+
+	_, _, err = ExecuteCommandC(rootCmd, append([]string{name, "--config", configFile}, args...)...)
+	return target, source, err
+}
+
+func TestTenantProfile(t *testing.T) {
+	target, source, err := executeWithTenantsConfig(t, "profile", "--tenant", "dev", "--source-tenant", "qas")
+	if err != nil {
+		t.Fatalf("profile failed with error - %v", err)
+	}
+	assert.Equal(t, "dev.hana.ondemand.com", target.Host, "Incorrect host of tenant profile")
+	assert.Equal(t, "dev-client", target.OauthClientId, "Incorrect client ID of tenant profile")
+	assert.Equal(t, "qas.hana.ondemand.com", source.Host, "Incorrect host of source tenant profile")
+	assert.Equal(t, "qas-user", source.Userid, "Incorrect user of source tenant profile")
+}
+
+func TestTenantProfile_APIM(t *testing.T) {
+	target, _, err := executeWithTenantsConfig(t, "apim", "--tenant", "dev")
+	if err != nil {
+		t.Fatalf("apim failed with error - %v", err)
+	}
+	assert.Equal(t, "dev-apim.hana.ondemand.com", target.Host, "APIM host of tenant profile not used")
+	assert.Equal(t, "dev-client", target.OauthClientId, "Incorrect client ID of tenant profile")
+}
+
+func TestTenantProfile_FlagPrecedence(t *testing.T) {
+	target, _, err := executeWithTenantsConfig(t, "profile", "--tenant", "dev", "--tmn-host", "other.hana.ondemand.com")
+	if err != nil {
+		t.Fatalf("profile failed with error - %v", err)
+	}
+	assert.Equal(t, "other.hana.ondemand.com", target.Host, "CLI flag does not take precedence over tenant profile")
+}
+
+func TestTenantProfile_NotFound(t *testing.T) {
+	_, _, err := executeWithTenantsConfig(t, "profile", "--tenant", "prd")
+	assert.ErrorContains(t, err, "tenant profile prd", "Missing tenant profile not reported")
+}
+
+func TestServiceKey(t *testing.T) {
+	serviceKey := `{ "oauth": { "clientid": "sb-clientid", "clientsecret": "secret", "url": "https://key.hana.ondemand.com", "tokenurl": "https://key.authentication.hana.ondemand.com/oauth/token" } }`
+	target, _, err := executeWithTenantsConfig(t, "profile", "--service-key", serviceKey, "--oauth-clientid", "other-client")
+	if err != nil {
+		t.Fatalf("profile failed with error - %v", err)
+	}
+	assert.Equal(t, "key.hana.ondemand.com", target.Host, "Incorrect host from service key")
+	assert.Equal(t, "key.authentication.hana.ondemand.com", target.OauthHost, "Incorrect OAuth host from service key")
+	assert.Equal(t, "secret", target.OauthClientSecret, "Incorrect client secret from service key")
+	assert.Equal(t, "other-client", target.OauthClientId, "CLI flag does not take precedence over service key")
+}
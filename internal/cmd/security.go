@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewSecurityCommand() *cobra.Command {
+
+	securityCmd := &cobra.Command{
+		Use:   "security",
+		Short: "Manage security material of the tenant",
+		Long: `Create, update, list and check the security material (user credentials,
+OAuth2 client credentials, secure parameters and keystore certificates)
+of the SAP Integration Suite tenant.`,
+	}
+	return securityCmd
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSecurityRoot() *cobra.Command {
+	rootCmd := NewCmdRoot()
+	securityCmd := NewSecurityCommand()
+	securityCmd.AddCommand(NewSecurityApplyCommand())
+	securityCmd.AddCommand(NewSecurityListCommand())
+	securityCmd.AddCommand(NewSecurityCheckCommand())
+	rootCmd.AddCommand(securityCmd)
+	return rootCmd
+}
+
+func writeSecurityTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+}
+
+func TestSecurity_ApplyAndCheck(t *testing.T) {
+	s := mock.NewServer()
+	s.AddKeystoreEntry("sap_cloudintegrationcertificate", "KeyPair", nil)
+	svr := s.Start()
+	defer svr.Close()
+	// Use Basic Auth with the mock server instead of the OAuth client of the test tenant
+	for _, key := range []string{"FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		t.Setenv(key, "")
+	}
+	tenant := []string{"--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password"}
+
+	dir := t.TempDir()
+	writeSecurityTestFiles(t, dir, map[string]string{
+		"security.yaml": "userCredentials:\n  - name: SFTP_User\n    user: sftpuser\n    password:\n      env: FLASHPIPE_TEST_SFTP_PASSWORD\n" +
+			"certificates:\n  - alias: partner_cert\n    file: partner.pem\n",
+		"partner.pem":                           "-----BEGIN CERTIFICATE-----\nMIIBkTCB+wIJAKHBfpegPjMC\n-----END CERTIFICATE-----\n",
+		"artifacts/IFlow1/META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow1; singleton:=true\n\n",
+		"artifacts/IFlow1/src/main/resources/scenarioflows/integrationflow/IFlow1.iflw": `<?xml version="1.0" encoding="UTF-8"?><bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd">
+<bpmn2:messageFlow id="MessageFlow_1"><bpmn2:extensionElements>
+<ifl:property><key>credential_name</key><value>SFTP_User</value></ifl:property>
+<ifl:property><key>privateKeyAlias</key><value>sap_cloudintegrationcertificate</value></ifl:property>
+<ifl:property><key>publicKeyAlias</key><value>partner_cert</value></ifl:property>
+</bpmn2:extensionElements></bpmn2:messageFlow></bpmn2:definitions>`,
+	})
+	manifest := filepath.Join(dir, "security.yaml")
+	artifactsDir := filepath.Join(dir, "artifacts")
+
+	// Missing security material is detected before it is applied
+	_, _, err := ExecuteCommandC(newSecurityRoot(), append([]string{"security", "check", "--dir-artifacts", artifactsDir}, tenant...)...)
+	assert.ErrorContains(t, err, "2 of 3 references to security material are missing on the tenant", "Missing security material not detected")
+
+	// Nothing is applied if a secret value is missing
+	_, _, err = ExecuteCommandC(newSecurityRoot(), append([]string{"security", "apply", "--manifest", manifest}, tenant...)...)
+	assert.ErrorContains(t, err, "environment variable FLASHPIPE_TEST_SFTP_PASSWORD is not set", "Missing secret not detected")
+	assert.Nil(t, s.KeystoreEntry("partner_cert"), "Certificate applied despite missing secret")
+
+	t.Setenv("FLASHPIPE_TEST_SFTP_PASSWORD", "secret")
+	_, _, err = ExecuteCommandC(newSecurityRoot(), append([]string{"security", "apply", "--manifest", manifest}, tenant...)...)
+	if err != nil {
+		t.Fatalf("security apply failed with error - %v", err)
+	}
+	assert.Equal(t, "secret", s.SecurityMaterial("UserCredentials", "SFTP_User")["Password"], "Password not set")
+	assert.NotNil(t, s.KeystoreEntry("partner_cert"), "Certificate not added")
+
+	// Existing security material is updated
+	_, _, err = ExecuteCommandC(newSecurityRoot(), append([]string{"security", "apply", "--manifest", manifest}, tenant...)...)
+	assert.NoError(t, err, "Existing security material not updated")
+
+	_, _, err = ExecuteCommandC(newSecurityRoot(), append([]string{"security", "check", "--dir-artifacts", artifactsDir}, tenant...)...)
+	assert.NoError(t, err, "Applied security material not found")
+
+	_, output, err := ExecuteCommandC(newSecurityRoot(), append([]string{"security", "list"}, tenant...)...)
+	if err != nil {
+		t.Fatalf("security list failed with error - %v", err)
+	}
+	assert.Contains(t, output, "UserCredential", "User credential not listed")
+	assert.Contains(t, output, "partner_cert", "Certificate not listed")
+	assert.NotContains(t, output, "secret", "Secret value listed")
+}
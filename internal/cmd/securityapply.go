@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/security"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewSecurityApplyCommand() *cobra.Command {
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create/update security material from manifest",
+		Long: `Create or update the user credentials, OAuth2 client credentials,
+secure parameters and keystore certificates of the security manifest
+on the SAP Integration Suite tenant.
+
+Secret values are read from environment variables or files referenced
+in the manifest, so that the manifest itself can be stored in Git.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runSecurityApply(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	applyCmd.Flags().String("manifest", "", "Path to the security manifest (YAML)")
+	applyCmd.Flags().Bool("skip-existing", false, "Only create missing security material, and leave the existing unchanged")
+
+	_ = applyCmd.MarkFlagRequired("manifest")
+
+	return applyCmd
+}
+
+func runSecurityApply(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing security apply command")
+
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
+	manifestFile, err := config.GetStringWithEnvExpand(cmd, "manifest")
+	if err != nil {
+		return fmt.Errorf("security alert for --manifest: %w", err)
+	}
+	skipExisting := config.GetBool(cmd, "skip-existing")
+
+	manifest, err := security.LoadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	// Read all secret values before changing the tenant, so that a missing value does not leave it half updated
+	secrets, err := resolveSecrets(manifest)
+	if err != nil {
+		return err
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	sm := api.NewSecurityMaterial(exe)
+
+	existing, err := getCredentialNames(sm)
+	if err != nil {
+		return err
+	}
+	// apply creates or updates the security material. existingType is the type of the security material of the same
+	// name on the tenant, or empty if there is none
+	apply := func(name string, materialType string, existingType string, upsert func(exists bool) error) error {
+		start := time.Now()
+		entry := &report.Entry{Id: name, Type: materialType, Action: report.ActionCreated}
+		var err error
+		switch existingType {
+		case "":
+			err = upsert(false)
+		case materialType:
+			if skipExisting {
+				log.Info().Msgf("Skipping existing %v %v", materialType, name)
+				entry.Action = report.ActionUnchanged
+				break
+			}
+			entry.Action = report.ActionUpdated
+			err = upsert(true)
+		default:
+			err = fmt.Errorf("%v %v cannot be created as the name is already used by a %v", materialType, name, existingType)
+		}
+		rep.Add(entry, start, err)
+		return err
+	}
+
+	for _, c := range manifest.UserCredentials {
+		credential := &api.UserCredential{Name: c.Name, Kind: c.Kind, Description: c.Description, User: c.User, Password: secrets[c.Name], CompanyId: c.CompanyId}
+		err = apply(c.Name, "UserCredential", existing[c.Name], func(exists bool) error { return sm.UpsertUserCredential(credential, exists) })
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range manifest.OAuth2ClientCredentials {
+		credential := &api.OAuth2ClientCredential{Name: c.Name, Description: c.Description, TokenServiceUrl: c.TokenServiceUrl, ClientId: c.ClientId, ClientSecret: secrets[c.Name],
+			ClientAuthentication: c.ClientAuthentication, Scope: c.Scope, ScopeContentType: c.ScopeContentType, Resource: c.Resource, Audience: c.Audience}
+		err = apply(c.Name, "OAuth2ClientCredential", existing[c.Name], func(exists bool) error { return sm.UpsertOAuth2ClientCredential(credential, exists) })
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range manifest.SecureParameters {
+		parameter := &api.SecureParameter{Name: p.Name, Description: p.Description, SecureParam: secrets[p.Name]}
+		err = apply(p.Name, "SecureParameter", existing[p.Name], func(exists bool) error { return sm.UpsertSecureParameter(parameter, exists) })
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(manifest.Certificates) > 0 {
+		aliases, err := getKeystoreAliases(sm)
+		if err != nil {
+			return err
+		}
+		for _, c := range manifest.Certificates {
+			content, err := manifest.CertificateContent(c)
+			if err != nil {
+				return err
+			}
+			err = apply(c.Alias, "Certificate", aliases[c.Alias], func(exists bool) error { return sm.UpsertCertificate(c.Alias, content, exists) })
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info().Msg("🏆 Security material applied to the tenant")
+	return nil
+}
+
+// resolveSecrets returns the secret values of the manifest by name
+func resolveSecrets(manifest *security.Manifest) (map[string]string, error) {
+	secrets := map[string]string{}
+	resolve := func(name string, secret security.Secret) error {
+		value, err := secret.Value()
+		if err != nil {
+			return fmt.Errorf("secret of %v could not be read: %w", name, err)
+		}
+		secrets[name] = value
+		return nil
+	}
+	for _, c := range manifest.UserCredentials {
+		if err := resolve(c.Name, c.Password); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range manifest.OAuth2ClientCredentials {
+		if err := resolve(c.Name, c.ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range manifest.SecureParameters {
+		if err := resolve(p.Name, p.Value); err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+// getCredentialNames returns the types of the user credentials, OAuth2 client credentials and secure parameters
+// of the tenant by name
+func getCredentialNames(sm *api.SecurityMaterial) (map[string]string, error) {
+	names := map[string]string{}
+	userCredentials, err := sm.ListUserCredentials()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range userCredentials {
+		names[c.Name] = "UserCredential"
+	}
+	oauthCredentials, err := sm.ListOAuth2ClientCredentials()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range oauthCredentials {
+		names[c.Name] = "OAuth2ClientCredential"
+	}
+	parameters, err := sm.ListSecureParameters()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range parameters {
+		names[p.Name] = "SecureParameter"
+	}
+	return names, nil
+}
+
+// getKeystoreAliases returns the types of the entries of the tenant keystore by alias, e.g. Certificate or KeyPair
+func getKeystoreAliases(sm *api.SecurityMaterial) (map[string]string, error) {
+	entries, err := sm.ListKeystoreEntries()
+	if err != nil {
+		return nil, err
+	}
+	aliases := map[string]string{}
+	for _, entry := range entries {
+		aliases[entry.Alias] = entry.Type
+	}
+	return aliases, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewSecurityCheckCommand() *cobra.Command {
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check security material referenced by IFlows",
+		Long: `Check that the credentials and keystore entries referenced in the
+BPMN2 files of the IFlows exist on the SAP Integration Suite tenant,
+e.g. before deploying the IFlows to a new environment.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runSecurityCheck(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	checkCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts, searched recursively")
+
+	_ = checkCmd.MarkFlagRequired("dir-artifacts")
+
+	return checkCmd
+}
+
+func runSecurityCheck(cmd *cobra.Command) error {
+	log.Info().Msg("Executing security check command")
+
+	artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+
+	artifactDirs, err := file.FindArtifactDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	sm := api.NewSecurityMaterial(exe)
+
+	credentials, err := getCredentialNames(sm)
+	if err != nil {
+		return err
+	}
+	aliases, err := getKeystoreAliases(sm)
+	if err != nil {
+		return err
+	}
+
+	checked := 0
+	missing := 0
+	for _, artifactDir := range artifactDirs {
+		references, err := file.FindSecurityAliases(artifactDir)
+		if err != nil {
+			return err
+		}
+		if len(references) == 0 {
+			continue
+		}
+		artifactId, err := sync.GetManifestId(artifactDir)
+		if err != nil {
+			return err
+		}
+		for _, reference := range references {
+			checked++
+			switch {
+			case reference.Kind == file.AliasCredential && credentials[reference.Alias] != "":
+			case reference.Kind == file.AliasKeystore && aliases[reference.Alias] != "":
+			default:
+				missing++
+				log.Error().Msgf("❌ %v %v referenced by %v of IFlow %v is missing on the tenant", reference.Kind, reference.Alias, reference.Key, artifactId)
+				continue
+			}
+			log.Debug().Msgf("Found %v %v referenced by %v of IFlow %v", reference.Kind, reference.Alias, reference.Key, artifactId)
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%d of %d references to security material are missing on the tenant", missing, checked)
+	}
+	log.Info().Msgf("🏆 All %d references to security material exist on the tenant", checked)
+	return nil
+}
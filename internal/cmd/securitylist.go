@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewSecurityListCommand() *cobra.Command {
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List security material of the tenant",
+		Long: `List the user credentials, OAuth2 client credentials, secure
+parameters and keystore entries of the SAP Integration Suite tenant.
+Secret values are never returned by the tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate output format
+			outputFormat := config.GetString(cmd, "output-format")
+			switch outputFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --output-format = %v", outputFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runSecurityList(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	listCmd.Flags().String("output-format", "table", "Output format of the security material. Allowed values: table, json")
+
+	return listCmd
+}
+
+// securityMaterialList is the security material of the tenant
+type securityMaterialList struct {
+	UserCredentials         []*api.UserCredential         `json:"userCredentials"`
+	OAuth2ClientCredentials []*api.OAuth2ClientCredential `json:"oauth2ClientCredentials"`
+	SecureParameters        []*api.SecureParameter        `json:"secureParameters"`
+	KeystoreEntries         []*api.KeystoreEntry          `json:"keystoreEntries"`
+}
+
+func runSecurityList(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing security list command")
+
+	outputFormat := config.GetString(cmd, "output-format")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	sm := api.NewSecurityMaterial(exe)
+
+	list := &securityMaterialList{}
+	if list.UserCredentials, err = sm.ListUserCredentials(); err != nil {
+		return err
+	}
+	if list.OAuth2ClientCredentials, err = sm.ListOAuth2ClientCredentials(); err != nil {
+		return err
+	}
+	if list.SecureParameters, err = sm.ListSecureParameters(); err != nil {
+		return err
+	}
+	if list.KeystoreEntries, err = sm.ListKeystoreEntries(); err != nil {
+		return err
+	}
+	return printSecurityMaterial(cmd.OutOrStdout(), list, outputFormat)
+}
+
+func printSecurityMaterial(w io.Writer, list *securityMaterialList, format string) error {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", content)
+		return err
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tNAME\tDETAILS")
+		for _, c := range list.UserCredentials {
+			fmt.Fprintf(tw, "UserCredential\t%v\tuser: %v, kind: %v\n", c.Name, c.User, c.Kind)
+		}
+		for _, c := range list.OAuth2ClientCredentials {
+			fmt.Fprintf(tw, "OAuth2ClientCredential\t%v\tclient ID: %v, token URL: %v\n", c.Name, c.ClientId, c.TokenServiceUrl)
+		}
+		for _, p := range list.SecureParameters {
+			fmt.Fprintf(tw, "SecureParameter\t%v\t%v\n", p.Name, p.Description)
+		}
+		for _, entry := range list.KeystoreEntries {
+			fmt.Fprintf(tw, "KeystoreEntry\t%v\ttype: %v, owner: %v\n", entry.Alias, entry.Type, entry.Owner)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("invalid output format %v", format)
+	}
+}
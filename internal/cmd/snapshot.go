@@ -6,6 +6,7 @@ import (
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
 	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/engswee/flashpipe/internal/sync"
 	"github.com/rs/zerolog/log"
@@ -47,6 +48,10 @@ tenant to a Git repository.`,
 					return fmt.Errorf("--dir-artifacts [%v] should be a subdirectory of --dir-git-repo [%v]", artifactsDir, gitRepoDirClean)
 				}
 			}
+			// Validate parallelism
+			if parallelism := config.GetInt(cmd, "parallelism"); parallelism < 1 {
+				return fmt.Errorf("invalid value for --parallelism = %v", parallelism)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -67,22 +72,29 @@ tenant to a Git repository.`,
 	snapshotCmd.Flags().StringSlice("ids-include", nil, "List of included package IDs")
 	snapshotCmd.Flags().StringSlice("ids-exclude", nil, "List of excluded package IDs")
 
-	// TODO - add restore feature
 	snapshotCmd.Flags().String("git-commit-msg", "Tenant snapshot of "+time.Now().Format(time.UnixDate), "Message used in commit")
 	snapshotCmd.Flags().String("git-commit-user", "github-actions[bot]", "User used in commit")
 	snapshotCmd.Flags().String("git-commit-email", "41898282+github-actions[bot]@users.noreply.github.com", "Email used in commit")
 	snapshotCmd.Flags().Bool("git-skip-commit", false, "Skip committing changes to Git repository")
+	addGitFlags(snapshotCmd.Flags())
+	snapshotCmd.Flags().Bool("git-commit-per-artifact", false, "Commit the changes of each artifact separately with a generated message")
 	snapshotCmd.Flags().Bool("sync-package-details", false, "Sync details of Integration Packages")
+	snapshotCmd.Flags().Int("parallelism", 1, "Number of artifacts processed concurrently")
+	snapshotCmd.Flags().Bool("ignore-diagram", false, "Ignore changes to the diagram layout (bpmndi elements) of IFlows when comparing contents")
 
 	_ = snapshotCmd.MarkFlagRequired("dir-git-repo")
 	snapshotCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+	snapshotCmd.MarkFlagsMutuallyExclusive("git-token", "git-ssh-key")
 
 	return snapshotCmd
 }
 
-func runSnapshot(cmd *cobra.Command) error {
+func runSnapshot(cmd *cobra.Command) (err error) {
 	log.Info().Msg("Executing snapshot command")
 
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
 	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
 	if err != nil {
 		return fmt.Errorf("security alert for --dir-git-repo: %w", err)
@@ -98,20 +110,24 @@ func runSnapshot(cmd *cobra.Command) error {
 	draftHandling := config.GetString(cmd, "draft-handling")
 	includedIds := config.GetStringSlice(cmd, "ids-include")
 	excludedIds := config.GetStringSlice(cmd, "ids-exclude")
-	commitMsg := config.GetString(cmd, "git-commit-msg")
-	commitUser := config.GetString(cmd, "git-commit-user")
-	commitEmail := config.GetString(cmd, "git-commit-email")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	syncPackageLevelDetails := config.GetBool(cmd, "sync-package-details")
+	ignoreDiagram := config.GetBool(cmd, "ignore-diagram")
+	parallelism := config.GetInt(cmd, "parallelism")
+
+	if err = checkoutGitBranch(cmd, gitRepoDir); err != nil {
+		return err
+	}
 
+	changes := newGitChanges(cmd)
 	serviceDetails := api.GetServiceDetails(cmd)
-	err = getTenantSnapshot(serviceDetails, artifactsBaseDir, workDir, draftHandling, syncPackageLevelDetails, includedIds, excludedIds)
+	err = getTenantSnapshot(serviceDetails, artifactsBaseDir, workDir, draftHandling, syncPackageLevelDetails, ignoreDiagram, parallelism, includedIds, excludedIds, rep, changes)
 	if err != nil {
 		return err
 	}
 
 	if !skipCommit {
-		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
+		err = commitToGit(cmd, gitRepoDir, changes)
 		if err != nil {
 			return err
 		}
@@ -119,7 +135,7 @@ func runSnapshot(cmd *cobra.Command) error {
 	return nil
 }
 
-func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, draftHandling string, syncPackageLevelDetails bool, includedIds []string, excludedIds []string) error {
+func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, draftHandling string, syncPackageLevelDetails bool, ignoreDiagram bool, parallelism int, includedIds []string, excludedIds []string, rep *report.Report, changes *repo.Changes) error {
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msg("📢 Begin taking a snapshot of the tenant")
 
@@ -136,36 +152,23 @@ func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir stri
 		return fmt.Errorf("No packages found in the tenant")
 	}
 
-	log.Info().Msgf("Processing %d packages", len(ids))
-	synchroniser := sync.New(exe)
-	for i, id := range ids {
-		log.Info().Msg("---------------------------------------------------------------------------------")
-		log.Info().Msgf("Processing package %d/%d - ID: %v", i+1, len(ids), id)
-		packageWorkingDir := fmt.Sprintf("%v/%v", workDir, id)
-		packageArtifactsDir := fmt.Sprintf("%v/%v", artifactsBaseDir, id)
-		packageDataFromTenant, readOnly, _, err := synchroniser.VerifyDownloadablePackage(id)
-		if err != nil {
-			if err != nil {
-				return err
-			}
+	var packageIds []string
+	for _, id := range ids {
+		// Filter in/out packages
+		if !str.FilterIDs(id, includedIds, excludedIds) {
+			packageIds = append(packageIds, id)
 		}
-		if !readOnly {
-			// Filter in/out artifacts
-			if str.FilterIDs(id, includedIds, excludedIds) {
-				continue
-			}
-			if syncPackageLevelDetails {
-				err = synchroniser.PackageToGit(packageDataFromTenant, id, packageWorkingDir, packageArtifactsDir)
-				if err != nil {
-					return err
-				}
-			}
-			err = synchroniser.ArtifactsToGit(id, packageWorkingDir, packageArtifactsDir, nil, nil, draftHandling, "ID", nil)
-			if err != nil {
-				return err
-			}
+	}
 
-		}
+	log.Info().Msgf("Processing %d packages", len(packageIds))
+	synchroniser := sync.New(exe)
+	synchroniser.SetIgnoreDiagram(ignoreDiagram)
+	synchroniser.SetParallelism(parallelism)
+	synchroniser.SetReport(rep)
+	synchroniser.SetGitChanges(changes)
+	err = synchroniser.PackagesToGit(packageIds, workDir, artifactsBaseDir, draftHandling, syncPackageLevelDetails)
+	if err != nil {
+		return err
 	}
 
 	log.Info().Msg("---------------------------------------------------------------------------------")
@@ -10,7 +10,6 @@ import (
 	"github.com/engswee/flashpipe/internal/analytics"
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
-	"github.com/engswee/flashpipe/internal/repo"
 	"github.com/engswee/flashpipe/internal/sync"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -61,6 +60,21 @@ tenant and a Git repository.`,
 			default:
 				return fmt.Errorf("invalid value for --target = %v", target)
 			}
+			// Changes to Git are not planned, so a dry run would still write, commit and push them
+			if config.GetBool(cmd, "dry-run") && (target == "git" || target == "local") {
+				return fmt.Errorf("--dry-run is only supported for --target = tenant")
+			}
+			// Validate plan format
+			planFormat := config.GetString(cmd, "plan-format")
+			switch planFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --plan-format = %v", planFormat)
+			}
+			// Validate parallelism
+			if parallelism := config.GetInt(cmd, "parallelism"); parallelism < 1 {
+				return fmt.Errorf("invalid value for --parallelism = %v", parallelism)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -88,18 +102,33 @@ tenant and a Git repository.`,
 	syncCmd.PersistentFlags().String("git-commit-email", "41898282+github-actions[bot]@users.noreply.github.com", "Email used in commit")
 	syncCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during sync ")
 	syncCmd.PersistentFlags().Bool("git-skip-commit", false, "Skip committing changes to Git repository")
+	addGitFlags(syncCmd.PersistentFlags())
+	syncCmd.Flags().Bool("git-commit-per-artifact", false, "Commit the changes of each artifact separately with a generated message")
 	syncCmd.Flags().Bool("sync-package-details", false, "Sync details of Integration Package")
+	syncCmd.Flags().Bool("ignore-diagram", false, "Ignore changes to the diagram layout (bpmndi elements) of IFlows when comparing contents")
+	syncCmd.Flags().String("environment", "", "Environment of the parameters.<environment>.prop files applied on top of parameters.prop (only for --target tenant)")
+	syncCmd.Flags().Int("parallelism", 1, "Number of artifacts processed concurrently")
+	syncCmd.PersistentFlags().Bool("prune", false, "Remove artifacts from the target that no longer exist in the source")
+	syncCmd.Flags().Bool("prune-undeploy", false, "Undeploy artifacts before deleting them with --prune (only for --target tenant)")
+	syncCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact undeployment status with --prune-undeploy")
+	syncCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact undeployment status with --prune-undeploy")
+	syncCmd.Flags().Bool("dry-run", false, "Show the changes that would be made to the tenant without executing them (only for --target tenant)")
+	syncCmd.Flags().String("plan-format", "table", "Output format of the changes for --dry-run. Allowed values: table, json")
 
 	_ = syncCmd.MarkFlagRequired("package-id")
 	_ = syncCmd.MarkFlagRequired("dir-git-repo")
 	syncCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+	syncCmd.MarkFlagsMutuallyExclusive("git-token", "git-ssh-key")
 
 	return syncCmd
 }
 
-func runSync(cmd *cobra.Command) error {
+func runSync(cmd *cobra.Command) (err error) {
 	log.Info().Msg("Executing sync command")
 
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
 	packageId := config.GetString(cmd, "package-id")
 	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
 	if err != nil {
@@ -117,12 +146,18 @@ func runSync(cmd *cobra.Command) error {
 	draftHandling := config.GetString(cmd, "draft-handling")
 	includedIds := config.GetStringSlice(cmd, "ids-include")
 	excludedIds := config.GetStringSlice(cmd, "ids-exclude")
-	commitMsg := config.GetString(cmd, "git-commit-msg")
-	commitUser := config.GetString(cmd, "git-commit-user")
-	commitEmail := config.GetString(cmd, "git-commit-email")
 	scriptCollectionMap := config.GetStringSlice(cmd, "script-collection-map")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	syncPackageLevelDetails := config.GetBool(cmd, "sync-package-details")
+	ignoreDiagram := config.GetBool(cmd, "ignore-diagram")
+	parallelism := config.GetInt(cmd, "parallelism")
+	environment := config.GetString(cmd, "environment")
+	prune := config.GetBool(cmd, "prune")
+	pruneUndeploy := config.GetBool(cmd, "prune-undeploy")
+	delayLength := config.GetInt(cmd, "delay-length")
+	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
+	dryRun := config.GetBool(cmd, "dry-run")
+	planFormat := config.GetString(cmd, "plan-format")
 	target := config.GetString(cmd, "target")
 	if target == "local" {
 		target = "git"
@@ -134,6 +169,13 @@ func runSync(cmd *cobra.Command) error {
 	// Initialise HTTP executer
 	exe := api.InitHTTPExecuter(serviceDetails)
 	synchroniser := sync.New(exe)
+	synchroniser.SetIgnoreDiagram(ignoreDiagram)
+	synchroniser.SetParallelism(parallelism)
+	synchroniser.SetPrune(prune, pruneUndeploy)
+	synchroniser.SetUndeploymentCheck(delayLength, maxCheckLimit)
+	synchroniser.SetReport(rep)
+	changes := newGitChanges(cmd)
+	synchroniser.SetGitChanges(changes)
 
 	// Sync from tenant to Git
 	if target == "git" {
@@ -142,6 +184,9 @@ func runSync(cmd *cobra.Command) error {
 			return err
 		}
 		if !readOnly {
+			if err = checkoutGitBranch(cmd, gitRepoDir); err != nil {
+				return err
+			}
 			if syncPackageLevelDetails {
 				err = synchroniser.PackageToGit(packageDataFromTenant, packageId, workDir, artifactsDir)
 				if err != nil {
@@ -155,7 +200,7 @@ func runSync(cmd *cobra.Command) error {
 			}
 
 			if !skipCommit {
-				err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
+				err = commitToGit(cmd, gitRepoDir, changes)
 				if err != nil {
 					return err
 				}
@@ -174,10 +219,19 @@ func runSync(cmd *cobra.Command) error {
 			return err
 		}
 
+		var plan *sync.Plan
+		if dryRun {
+			plan = sync.NewPlan()
+			synchroniser.SetDryRun(plan)
+		}
+		synchroniser.SetEnvironment(environment)
 		err = synchroniser.ArtifactsToTenant(packageId, workDir, artifactsDir, includedIds, excludedIds)
 		if err != nil {
 			return err
 		}
+		if dryRun {
+			return plan.Print(cmd.OutOrStdout(), planFormat)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSync_DryRunTargetGit(t *testing.T) {
+	rootCmd := NewCmdRoot()
+	rootCmd.AddCommand(NewSyncCommand())
+
+	gitRepoDir := t.TempDir()
+	_, _, err := ExecuteCommandC(rootCmd, "sync", "--tmn-host", "localhost", "--tmn-userid", "user", "--tmn-password", "password",
+		"--dir-git-repo", gitRepoDir, "--target", "git", "--dry-run")
+	assert.ErrorContains(t, err, "--dry-run is only supported for --target = tenant")
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/smoketest"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewTestCommand() *cobra.Command {
+
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send test messages to deployed IFlows",
+		Long: `Run the test cases of the artifacts against the deployed IFlows
+of SAP Integration Suite tenant, e.g. as smoke tests after a deployment.
+
+The test cases are YAML files in the test directory of each artifact.
+The message of each test case is sent to the runtime endpoint of the IFlow
+and the response is checked against the expected status, headers and body.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runTest(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	testCmd.Flags().String("dir-artifacts", "", "Directory containing contents of artifacts, searched recursively")
+	testCmd.Flags().String("dir-tests", "src/test/flashpipe", "Directory of the test cases, relative to the directory of each artifact")
+	testCmd.Flags().StringSlice("iflow-ids", nil, "Comma separated list of IFlow IDs to test, all if empty")
+
+	_ = testCmd.MarkFlagRequired("dir-artifacts")
+
+	return testCmd
+}
+
+func runTest(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing test command")
+
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
+	artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	testsDir := config.GetString(cmd, "dir-tests")
+	iflowIds := str.TrimSlice(config.GetStringSlice(cmd, "iflow-ids"))
+
+	artifactDirs, err := file.FindArtifactDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	runner := &testRunner{exe: exe, se: api.NewServiceEndpoint(exe), urls: map[string][]string{}, rep: rep}
+
+	for _, artifactDir := range artifactDirs {
+		cases, err := smoketest.LoadCases(filepath.Join(artifactDir, testsDir))
+		if err != nil {
+			return err
+		}
+		if len(cases) == 0 {
+			continue
+		}
+		artifactId, err := sync.GetManifestId(artifactDir)
+		if err != nil {
+			return err
+		}
+		for _, c := range cases {
+			iflowId := artifactId
+			if c.IFlowId != "" {
+				iflowId = c.IFlowId
+			}
+			if len(iflowIds) > 0 && !slices.Contains(iflowIds, iflowId) {
+				continue
+			}
+			runner.run(c, iflowId)
+		}
+	}
+
+	if runner.total == 0 {
+		log.Warn().Msgf("⚠️ No test cases found in %v", artifactsDir)
+		return nil
+	}
+	if runner.failed > 0 {
+		return fmt.Errorf("%d of %d test cases failed", runner.failed, runner.total)
+	}
+	log.Info().Msgf("🏆 All %d test cases passed", runner.total)
+	return nil
+}
+
+type testRunner struct {
+	exe    *httpclnt.HTTPExecuter
+	se     *api.ServiceEndpoint
+	urls   map[string][]string
+	rep    *report.Report
+	total  int
+	failed int
+}
+
+// run runs the test case against the IFlow and records the outcome in the report
+func (r *testRunner) run(c *smoketest.Case, iflowId string) {
+	start := time.Now()
+	err := r.send(c, iflowId)
+	r.rep.Add(&report.Entry{Id: iflowId + "/" + c.Name, Type: "Test", Action: report.ActionPassed}, start, err)
+	r.total++
+	if err != nil {
+		r.failed++
+		log.Error().Msgf("❌ Test case %v of IFlow %v failed: %v", c.Name, iflowId, err)
+		return
+	}
+	log.Info().Msgf("✅ Test case %v of IFlow %v passed", c.Name, iflowId)
+}
+
+func (r *testRunner) send(c *smoketest.Case, iflowId string) error {
+	// Service endpoints are only looked up once per IFlow
+	urls, found := r.urls[iflowId]
+	if !found {
+		var err error
+		urls, err = r.se.GetURLs(iflowId)
+		if err != nil {
+			return err
+		}
+		r.urls[iflowId] = urls
+	}
+	url, err := c.SelectURL(urls)
+	if err != nil {
+		return err
+	}
+	return c.Run(r.exe, url)
+}
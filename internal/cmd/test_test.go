@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTest_JUnitReport(t *testing.T) {
+	s := mock.NewServer()
+	s.AddServiceEndpoint("IFlow1", "/orders", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"received":` + string(body) + `}`))
+	}))
+	svr := s.Start()
+	defer svr.Close()
+	// Use Basic Auth with the mock server instead of the OAuth client of the test tenant
+	for _, key := range []string{"FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		t.Setenv(key, "")
+	}
+
+	artifactsDir := t.TempDir()
+	files := map[string]string{
+		"Package1/IFlow1/META-INF/MANIFEST.MF":                 "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow1; singleton:=true\n\n",
+		"Package1/IFlow1/src/test/flashpipe/order.json":        `{"order":4711}`,
+		"Package1/IFlow1/src/test/flashpipe/accepted.yaml":     "payloadFile: order.json\nheaders:\n  Content-Type: application/json\nexpect:\n  status: 200\n  bodyContains: ['\"order\":4711']\n",
+		"Package1/IFlow1/src/test/flashpipe/rejected.yaml":     "name: Order is rejected\npayload: '{}'\nexpect:\n  bodyMatches: rejected\n",
+		"Package1/IFlow2/META-INF/MANIFEST.MF":                 "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow2\n\n",
+		"Package1/IFlow2/src/main/resources/scenarioflows.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(artifactsDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+
+	newRoot := func() *cobra.Command {
+		rootCmd := NewCmdRoot()
+		rootCmd.AddCommand(NewTestCommand())
+		return rootCmd
+	}
+	reportFile := filepath.Join(t.TempDir(), "report.xml")
+	_, _, err := ExecuteCommandC(newRoot(), "test", "--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password",
+		"--dir-artifacts", artifactsDir, "--report", reportFile, "--report-format", "junit")
+	assert.ErrorContains(t, err, "1 of 2 test cases failed", "Failed test case not detected")
+
+	content, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	assert.Contains(t, string(content), `<testsuite name="flashpipe test" tests="2" failures="1"`, "Incorrect test suite")
+	assert.Contains(t, string(content), `<testcase name="IFlow1/accepted" classname="Test"`, "Test case not reported")
+	assert.Contains(t, string(content), `<failure message="expected body to match &#34;rejected&#34;">`, "Failure not reported")
+
+	_, _, err = ExecuteCommandC(newRoot(), "test", "--tmn-host", svr.URL, "--tmn-userid", "user", "--tmn-password", "password",
+		"--dir-artifacts", artifactsDir, "--iflow-ids", "IFlow2")
+	assert.NoError(t, err, "IFlow without test cases not ignored")
+}
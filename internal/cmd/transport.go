@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewTransportCommand() *cobra.Command {
+
+	transportCmd := &cobra.Command{
+		Use:   "transport",
+		Short: "Transport designtime artifacts between tenants",
+		Long: `Transport integration packages and their designtime artifacts
+from a source SAP Integration Suite tenant to the target tenant
+without going through a Git repository.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate Draft Handling
+			draftHandling := config.GetString(cmd, "draft-handling")
+			switch draftHandling {
+			case "SKIP", "ADD", "ERROR":
+			default:
+				return fmt.Errorf("invalid value for --draft-handling = %v", draftHandling)
+			}
+			// Validate plan format
+			planFormat := config.GetString(cmd, "plan-format")
+			switch planFormat {
+			case "table", "json":
+			default:
+				return fmt.Errorf("invalid value for --plan-format = %v", planFormat)
+			}
+			// Validate the ID mappings
+			if _, err := parseIdMap(config.GetStringSlice(cmd, "package-map")); err != nil {
+				return fmt.Errorf("invalid value for --package-map: %w", err)
+			}
+			if _, err := parseIdMap(config.GetStringSlice(cmd, "artifact-map")); err != nil {
+				return fmt.Errorf("invalid value for --artifact-map: %w", err)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runTransport(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	transportCmd.Flags().String("source-service-key", "", "Service key of SAP BTP (JSON content or file) with the host and OAuth credentials of the source tenant")
+	transportCmd.Flags().String("source-tenant", "", "Name of the tenant profile under tenants in the config file for the source tenant")
+	transportCmd.Flags().String("source-tmn-host", "", "Host for tenant management node of the source tenant excluding https://")
+	transportCmd.Flags().String("source-tmn-userid", "", "User ID for Basic Auth of the source tenant")
+	transportCmd.Flags().String("source-tmn-password", "", "Password for Basic Auth of the source tenant")
+	transportCmd.Flags().String("source-oauth-host", "", "Host for OAuth token server of the source tenant excluding https://")
+	transportCmd.Flags().String("source-oauth-clientid", "", "Client ID for using OAuth on the source tenant")
+	transportCmd.Flags().String("source-oauth-clientsecret", "", "Client Secret for using OAuth on the source tenant")
+	transportCmd.Flags().String("source-oauth-path", "/oauth/token", "Path for OAuth token server of the source tenant")
+	transportCmd.Flags().String("source-oauth-cert", "", "Client certificate (PEM content or file) for using OAuth with mTLS on the source tenant")
+	transportCmd.Flags().String("source-oauth-key", "", "Private key (PEM content or file) of the client certificate of the source tenant")
+	transportCmd.Flags().StringSlice("package-ids", nil, "Comma separated list of Integration Package IDs to transport")
+	transportCmd.Flags().StringSlice("package-map", nil, "Comma-separated source-target package ID pairs for renaming packages in the target tenant")
+	transportCmd.Flags().StringSlice("artifact-map", nil, "Comma-separated source-target artifact ID pairs for renaming artifacts in the target tenant")
+	transportCmd.Flags().StringSlice("ids-include", nil, "List of included artifact IDs")
+	transportCmd.Flags().StringSlice("ids-exclude", nil, "List of excluded artifact IDs")
+	transportCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during transport")
+	transportCmd.Flags().String("draft-handling", "SKIP", "Handling when artifact is in draft version. Allowed values: SKIP, ADD, ERROR")
+	transportCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
+	transportCmd.Flags().Bool("dry-run", false, "Show the changes that would be made to the target tenant without executing them")
+	transportCmd.Flags().String("plan-format", "table", "Output format of the changes for --dry-run. Allowed values: table, json")
+
+	_ = transportCmd.MarkFlagRequired("source-tmn-host")
+	_ = transportCmd.MarkFlagRequired("package-ids")
+	transportCmd.MarkFlagsRequiredTogether("source-tmn-userid", "source-tmn-password")
+	transportCmd.MarkFlagsRequiredTogether("source-oauth-host", "source-oauth-clientid")
+	transportCmd.MarkFlagsRequiredTogether("source-oauth-cert", "source-oauth-key")
+	transportCmd.MarkFlagsMutuallyExclusive("source-oauth-clientsecret", "source-oauth-cert")
+	transportCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+
+	return transportCmd
+}
+
+func runTransport(cmd *cobra.Command) (err error) {
+	log.Info().Msg("Executing transport command")
+
+	rep := newReport(cmd)
+	defer func() { err = writeReport(cmd, rep, err) }()
+
+	packageIds := str.TrimSlice(config.GetStringSlice(cmd, "package-ids"))
+	packageMap, _ := parseIdMap(config.GetStringSlice(cmd, "package-map"))
+	artifactMap, _ := parseIdMap(config.GetStringSlice(cmd, "artifact-map"))
+	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
+	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	scriptCollectionMap := config.GetStringSlice(cmd, "script-collection-map")
+	draftHandling := config.GetString(cmd, "draft-handling")
+	workDir, err := config.GetStringWithEnvExpand(cmd, "dir-work")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-work: %w", err)
+	}
+	dryRun := config.GetBool(cmd, "dry-run")
+	planFormat := config.GetString(cmd, "plan-format")
+
+	// Initialise HTTP executers for both tenants
+	srcExe := api.InitHTTPExecuter(api.GetSourceServiceDetails(cmd))
+	tgtExe := api.InitHTTPExecuter(api.GetServiceDetails(cmd))
+
+	// Script collections renamed with --artifact-map are also renamed in the references of the IFlows
+	scriptMap := append([]string{}, scriptCollectionMap...)
+	for src, tgt := range artifactMap {
+		scriptMap = append(scriptMap, src+"="+tgt)
+	}
+
+	var plan *sync.Plan
+	if dryRun {
+		plan = sync.NewPlan()
+	}
+	t := &transporter{
+		src:         sync.New(srcExe),
+		srcIp:       api.NewIntegrationPackage(srcExe),
+		tgt:         sync.New(tgtExe),
+		tgtExe:      tgtExe,
+		packageMap:  packageMap,
+		artifactMap: artifactMap,
+		scriptMap:   scriptMap,
+		plan:        plan,
+	}
+	t.tgt.SetReport(rep)
+	if dryRun {
+		t.tgt.SetDryRun(plan)
+	}
+
+	for _, packageId := range packageIds {
+		err = t.transportPackage(packageId, workDir, includedIds, excludedIds, draftHandling)
+		if err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return plan.Print(cmd.OutOrStdout(), planFormat)
+	}
+	return nil
+}
+
+type transporter struct {
+	src         *sync.Synchroniser
+	srcIp       *api.IntegrationPackage
+	tgt         *sync.Synchroniser
+	tgtExe      *httpclnt.HTTPExecuter
+	packageMap  map[string]string
+	artifactMap map[string]string
+	scriptMap   []string
+	plan        *sync.Plan
+}
+
+func (t *transporter) transportPackage(packageId string, workDir string, includedIds []string, excludedIds []string, draftHandling string) error {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("📢 Begin transport of integration package %v", packageId)
+	packageData, readOnly, packageExists, err := t.src.VerifyDownloadablePackage(packageId)
+	if err != nil {
+		return err
+	}
+	if !packageExists || readOnly {
+		return nil
+	}
+
+	// Stage the artifacts of the source tenant in the working directory
+	stagingDir := fmt.Sprintf("%v/transport/%v", workDir, packageId)
+	err = os.RemoveAll(stagingDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer os.RemoveAll(stagingDir)
+	err = t.src.ArtifactsToGit(packageId, workDir+"/source", stagingDir, includedIds, excludedIds, draftHandling, "ID", nil)
+	if err != nil {
+		return err
+	}
+	if !file.Exists(stagingDir) {
+		log.Warn().Msgf("No artifacts to transport in integration package %v", packageId)
+		return nil
+	}
+
+	// Create or update the package in the target tenant with the details from the source tenant
+	targetPackageId := mapId(packageId, t.packageMap)
+	packageData.Root.Id = targetPackageId
+	err = t.upsertPackage(packageData)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := t.srcIp.GetAllArtifacts(packageId)
+	if err != nil {
+		return err
+	}
+	ids, err := sync.GetArtifactDirIds(stagingDir)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		artifact := api.FindArtifactById(id, artifacts)
+		if artifact == nil {
+			return fmt.Errorf("Artifact %v not found in package %v", id, packageId)
+		}
+		artifactDir := fmt.Sprintf("%v/%v", stagingDir, id)
+		targetId := mapId(id, t.artifactMap)
+		if targetId != id {
+			log.Info().Msgf("Renaming artifact %v to %v", id, targetId)
+			err = sync.SetManifestId(artifactDir+"/META-INF/MANIFEST.MF", targetId)
+			if err != nil {
+				return err
+			}
+		}
+		// Converting the references here also applies them when an existing artifact is updated
+		if artifact.ArtifactType == "Integration" {
+			err = file.UpdateBPMN(artifactDir, t.scriptMap)
+			if err != nil {
+				return err
+			}
+		}
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("📢 Begin processing for artifact %v", targetId)
+		err = t.tgt.SingleArtifactToTenant(targetId, artifact.Name, artifact.ArtifactType, targetPackageId, artifactDir, workDir+"/target", artifactDir+"/src/main/resources/parameters.prop", t.scriptMap)
+		if err != nil {
+			return err
+		}
+	}
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Completed transport of integration package %v to %v", packageId, targetPackageId)
+	return nil
+}
+
+func (t *transporter) upsertPackage(packageData *api.PackageSingleData) error {
+	ip := api.NewIntegrationPackage(t.tgtExe)
+	_, _, exists, err := ip.Get(packageData.Root.Id)
+	if err != nil {
+		return err
+	}
+	if t.plan != nil {
+		action := sync.ActionUpdate
+		if !exists {
+			action = sync.ActionCreate
+		}
+		t.plan.Add(&sync.PlanAction{Action: action, ArtifactId: packageData.Root.Id, ArtifactType: "IntegrationPackage", PackageId: packageData.Root.Id})
+		return nil
+	}
+	if !exists {
+		log.Info().Msgf("Creating integration package %v in target tenant", packageData.Root.Id)
+		return ip.Create(packageData)
+	}
+	log.Info().Msgf("Updating integration package %v in target tenant", packageData.Root.Id)
+	return ip.Update(packageData)
+}
+
+// parseIdMap converts the source-target ID pairs into a map
+func parseIdMap(pairs []string) (map[string]string, error) {
+	output := map[string]string{}
+	for _, pair := range str.TrimSlice(pairs) {
+		srcTgt := str.ExtractDelimitedValues(pair, "=")
+		if len(srcTgt) != 2 || srcTgt[0] == "" || srcTgt[1] == "" {
+			return nil, fmt.Errorf("%v is not in the format SOURCE=TARGET", pair)
+		}
+		output[srcTgt[0]] = srcTgt[1]
+	}
+	return output, nil
+}
+
+func mapId(id string, idMap map[string]string) string {
+	if target, ok := idMap[id]; ok {
+		return target
+	}
+	return id
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIdMap(t *testing.T) {
+	idMap, err := parseIdMap([]string{"Package_DEV=Package_QAS", " IFlow1 = IFlow1_Copy "})
+	if err != nil {
+		t.Fatalf("parseIdMap failed with error - %v", err)
+	}
+	assert.Equal(t, map[string]string{"Package_DEV": "Package_QAS", "IFlow1": "IFlow1_Copy"}, idMap, "Incorrect ID map")
+	assert.Equal(t, "Package_QAS", mapId("Package_DEV", idMap), "Mapped ID not renamed")
+	assert.Equal(t, "IFlow2", mapId("IFlow2", idMap), "Unmapped ID renamed")
+}
+
+func TestParseIdMap_Invalid(t *testing.T) {
+	_, err := parseIdMap([]string{"Package_DEV"})
+	assert.Error(t, err, "Pair without target accepted")
+}
+
+func TestTransport_CamelExpression(t *testing.T) {
+	source := mock.NewServer()
+	sourceSvr := source.Start()
+	defer sourceSvr.Close()
+	target := mock.NewServer()
+	targetSvr := target.Start()
+	defer targetSvr.Close()
+	// Use Basic Auth with the mock servers instead of the OAuth client of the test tenant
+	for _, key := range []string{"FLASHPIPE_OAUTH_HOST", "FLASHPIPE_OAUTH_CLIENTID", "FLASHPIPE_OAUTH_CLIENTSECRET"} {
+		t.Setenv(key, "")
+	}
+
+	// Camel expressions in parameters are only resolved at runtime
+	artifactDir := filepath.Join(t.TempDir(), "Integration_Test_IFlow")
+	if err := file.ReplaceDir("../../test/testdata/artifacts/create/Integration_Test_IFlow", artifactDir); err != nil {
+		t.Fatalf("ReplaceDir failed with error - %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(artifactDir, "src/main/resources/parameters.prop"), []byte("Receiver=${header.Receiver}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+
+	newRoot := func() *cobra.Command {
+		rootCmd := NewCmdRoot()
+		updateCmd := NewUpdateCommand()
+		updateCmd.AddCommand(NewArtifactCommand())
+		updateCmd.AddCommand(NewPackageCommand())
+		rootCmd.AddCommand(updateCmd)
+		rootCmd.AddCommand(NewTransportCommand())
+		return rootCmd
+	}
+	sourceArgs := []string{"--tmn-host", sourceSvr.URL, "--tmn-userid", "user", "--tmn-password", "password"}
+	_, _, err := ExecuteCommandC(newRoot(), append([]string{"update", "package", "--package-file", "../../test/testdata/FlashPipeIntegrationTest.json"}, sourceArgs...)...)
+	if err != nil {
+		t.Fatalf("update package failed with error - %v", err)
+	}
+	_, _, err = ExecuteCommandC(newRoot(), append([]string{"update", "artifact", "--artifact-id", "Integration_Test_IFlow", "--artifact-name", "Integration Test IFlow",
+		"--package-id", "FlashPipeIntegrationTest", "--package-name", "FlashPipe Integration Test", "--dir-artifact", artifactDir, "--dir-work", t.TempDir()}, sourceArgs...)...)
+	if err != nil {
+		t.Fatalf("update artifact failed with error - %v", err)
+	}
+
+	_, _, err = ExecuteCommandC(newRoot(), "transport", "--source-tmn-host", sourceSvr.URL, "--source-tmn-userid", "user", "--source-tmn-password", "password",
+		"--tmn-host", targetSvr.URL, "--tmn-userid", "user", "--tmn-password", "password", "--package-ids", "FlashPipeIntegrationTest", "--dir-work", t.TempDir())
+	if err != nil {
+		t.Fatalf("transport failed with error - %v", err)
+	}
+
+	host, port := httpclnt.GetHostPort(targetSvr.URL)
+	configuration := api.NewConfiguration(httpclnt.New("", "", "", "", "user", "password", host, "http", port, true))
+	parameters, err := configuration.Get("Integration_Test_IFlow", "active")
+	if err != nil {
+		t.Fatalf("Get configuration failed with error - %v", err)
+	}
+	parameter := api.FindParameterByKey("Receiver", parameters.Root.Results)
+	if assert.NotNil(t, parameter, "Parameter not transported") {
+		assert.Equal(t, "${header.Receiver}", parameter.ParameterValue, "Camel expression changed")
+	}
+}
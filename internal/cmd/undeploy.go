@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+func NewUndeployCommand() *cobra.Command {
+
+	undeployCmd := &cobra.Command{
+		Use:   "undeploy",
+		Short: "Undeploy runtime artifacts",
+		Long: `Undeploy artifacts from the runtime
+of SAP Integration Suite tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate the artifact ID pattern
+			pattern := config.GetString(cmd, "artifact-pattern")
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid value for --artifact-pattern = %v", pattern)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = runUndeploy(cmd); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	undeployCmd.Flags().StringSlice("artifact-ids", nil, "Comma separated list of artifact IDs")
+	undeployCmd.Flags().StringSlice("package-ids", nil, "Comma separated list of Integration Package IDs whose artifacts are undeployed")
+	undeployCmd.Flags().String("artifact-pattern", "", "Pattern of IDs of runtime artifacts to undeploy, e.g. Interface_*")
+	undeployCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact undeployment status")
+	undeployCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact undeployment status")
+
+	undeployCmd.MarkFlagsOneRequired("artifact-ids", "package-ids", "artifact-pattern")
+	return undeployCmd
+}
+
+func runUndeploy(cmd *cobra.Command) error {
+	log.Info().Msg("Executing undeploy command")
+
+	artifactIds := config.GetStringSlice(cmd, "artifact-ids")
+	packageIds := config.GetStringSlice(cmd, "package-ids")
+	pattern := config.GetString(cmd, "artifact-pattern")
+	delayLength := config.GetInt(cmd, "delay-length")
+	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+	rt := api.NewRuntime(exe)
+
+	ids, err := getUndeployIds(str.TrimSlice(artifactIds), str.TrimSlice(packageIds), pattern, api.NewIntegrationPackage(exe), rt)
+	if err != nil {
+		return err
+	}
+	return sync.UndeployArtifacts(ids, delayLength, maxCheckLimit, rt)
+}
+
+// getUndeployIds returns the unique IDs of the artifacts, the artifacts of the packages
+// and the runtime artifacts matching the pattern
+func getUndeployIds(artifactIds []string, packageIds []string, pattern string, ip *api.IntegrationPackage, rt *api.Runtime) ([]string, error) {
+	var ids []string
+	add := func(id string) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range artifactIds {
+		add(id)
+	}
+	for _, packageId := range packageIds {
+		artifacts, err := ip.GetAllArtifacts(packageId)
+		if err != nil {
+			return nil, err
+		}
+		for _, artifact := range artifacts {
+			add(artifact.Id)
+		}
+	}
+	if pattern != "" {
+		runtimeArtifacts, err := rt.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		var matchedIds []string
+		for _, artifact := range runtimeArtifacts {
+			if matched, _ := path.Match(pattern, artifact.Id); matched {
+				matchedIds = append(matchedIds, artifact.Id)
+				add(artifact.Id)
+			}
+		}
+		log.Info().Msgf("Runtime artifacts matching pattern %v: %v", pattern, strings.Join(matchedIds, ", "))
+	}
+	return ids, nil
+}
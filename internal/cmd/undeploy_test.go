@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newRuntimeServer returns a server with the runtime artifacts that are removed when they are undeployed
+func newRuntimeServer(deployed ...string) *httptest.Server {
+	var mu sync.Mutex
+	artifacts := map[string]bool{}
+	for _, id := range deployed {
+		artifacts[id] = true
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		results := ""
+		for _, id := range deployed {
+			if artifacts[id] {
+				if results != "" {
+					results += ","
+				}
+				results += `{"Id":"` + id + `","Version":"1.0.0","Status":"STARTED"}`
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"results":[` + results + `]}}`))
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/api/v1/" {
+			// CSRF token
+			w.Header().Set("x-csrf-token", "token123")
+			return
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/IntegrationRuntimeArtifacts('"), "')")
+		if !artifacts[id] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(artifacts, id)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"d":{"Version":"1.0.0","Status":"STARTED"}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGetUndeployIds(t *testing.T) {
+	svr := newRuntimeServer("Interface_A", "Interface_B", "Other")
+	defer svr.Close()
+
+	host, port := httpclnt.GetHostPort(svr.URL)
+	rt := api.NewRuntime(httpclnt.New("", "", "", "", "dummy", "dummy", host, "http", port, true))
+
+	ids, err := getUndeployIds([]string{"Other", "Interface_B"}, nil, "Interface_*", nil, rt)
+	if err != nil {
+		t.Fatalf("getUndeployIds failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"Other", "Interface_B", "Interface_A"}, ids, "Incorrect artifact IDs")
+}
@@ -67,6 +67,10 @@ func verifyNoSensitiveContent(input string) (bool, error) {
 		"tmn-password",
 		"oauth-clientid",
 		"oauth-clientsecret",
+		"source-tmn-userid",
+		"source-tmn-password",
+		"source-oauth-clientid",
+		"source-oauth-clientsecret",
 	}
 
 	for _, sensContConfigParam := range sensContConfigParams {
@@ -75,5 +79,17 @@ func verifyNoSensitiveContent(input string) (bool, error) {
 		}
 	}
 
+	// Check also the credentials of the tenant profiles in the config file
+	for tenant := range viper.GetStringMap("tenants") {
+		for _, prefix := range []string{"tenants." + tenant + ".", "tenants." + tenant + ".apim."} {
+			for _, sensContConfigParam := range []string{"tmn-userid", "tmn-password", "oauth-clientid", "oauth-clientsecret"} {
+				key := prefix + sensContConfigParam
+				if viper.GetString(key) != "" && strings.Contains(input, viper.GetString(key)) {
+					return false, fmt.Errorf("Input contains sensitive content from configuration parameter %v", key)
+				}
+			}
+		}
+	}
+
 	return true, nil
 }
@@ -0,0 +1,304 @@
+package dependency
+
+import (
+	"fmt"
+	"github.com/beevik/etree"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	EdgeScriptCollection = "script-collection"
+	EdgeMessageMapping   = "message-mapping"
+	EdgeProcessDirect    = "process-direct"
+	EdgeValueMapping     = "value-mapping"
+)
+
+// Node is an artifact of the graph. External nodes are referenced by other artifacts but not found in the directory
+type Node struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	PackageId string `json:"packageId,omitempty"`
+	Dir       string `json:"dir,omitempty"`
+	External  bool   `json:"external,omitempty"`
+}
+
+// Edge is a dependency of artifact From on artifact To, e.g. an IFlow using a script collection
+type Edge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Graph are the dependencies between the artifacts of a directory
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+	nodes map[string]*Node
+}
+
+func NewGraph() *Graph {
+	g := new(Graph)
+	g.Nodes = []*Node{}
+	g.Edges = []*Edge{}
+	g.nodes = map[string]*Node{}
+	return g
+}
+
+// Node returns the node of the artifact, or nil if it is not in the graph
+func (g *Graph) Node(id string) *Node {
+	return g.nodes[id]
+}
+
+// AddNode adds the artifact to the graph. An external node of the same ID is replaced
+func (g *Graph) AddNode(node *Node) {
+	if existing, found := g.nodes[node.Id]; found {
+		if !existing.External {
+			log.Warn().Msgf("Artifact %v found in both %v and %v", node.Id, existing.Dir, node.Dir)
+			return
+		}
+		*existing = *node
+		return
+	}
+	g.nodes[node.Id] = node
+	g.Nodes = append(g.Nodes, node)
+}
+
+// AddEdge adds the dependency of from on to. If there is no node for to, an external node of type toType is added
+func (g *Graph) AddEdge(from string, to string, kind string, detail string, toType string) {
+	if from == to {
+		return
+	}
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to && e.Kind == kind {
+			return
+		}
+	}
+	if _, found := g.nodes[to]; !found {
+		g.AddNode(&Node{Id: to, Type: toType, External: true})
+	}
+	g.Edges = append(g.Edges, &Edge{From: from, To: to, Kind: kind, Detail: detail})
+}
+
+// Build returns the dependency graph of the artifacts in the base directory or below it, e.g. the artifacts
+// directory of a package synced to Git, or the directory of a tenant snapshot with a subdirectory per package
+func Build(baseDir string) (*Graph, error) {
+	baseDir = filepath.Clean(baseDir)
+	g := NewGraph()
+	artifactDirs, err := file.FindArtifactDirs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range artifactDirs {
+		headers, err := file.ReadManifest(filepath.Join(dir, "META-INF", "MANIFEST.MF"))
+		if err != nil {
+			return nil, err
+		}
+		node := &Node{Id: file.ManifestId(headers), Type: headers.Get("SAP-BundleType"), Dir: dir}
+		// Snapshots have a subdirectory per package
+		if parent := filepath.Dir(dir); parent != baseDir {
+			node.PackageId = filepath.Base(parent)
+		}
+		g.AddNode(node)
+	}
+
+	// Only IFlows have BPMN2 files, so the references are collected from them first
+	references := map[string][]*file.ArtifactReference{}
+	endpoints := map[string][]string{}
+	for _, node := range g.Nodes {
+		if node.Type != "IntegrationFlow" {
+			continue
+		}
+		refs, err := file.FindArtifactReferences(node.Dir)
+		if err != nil {
+			return nil, err
+		}
+		references[node.Id] = refs
+		for _, ref := range refs {
+			if ref.Kind == file.ReferenceProcessDirectEndpoint {
+				endpoints[ref.Value] = append(endpoints[ref.Value], node.Id)
+			}
+		}
+	}
+
+	for _, node := range slices.Clone(g.Nodes) {
+		for _, ref := range references[node.Id] {
+			switch ref.Kind {
+			case file.ReferenceScriptCollection:
+				g.AddEdge(node.Id, ref.Value, EdgeScriptCollection, ref.Key, "ScriptCollection")
+			case file.ReferenceMessageMapping:
+				g.AddEdge(node.Id, ref.Value, EdgeMessageMapping, ref.Key, "MessageMapping")
+			case file.ReferenceProcessDirectCall:
+				if len(endpoints[ref.Value]) == 0 {
+					log.Debug().Msgf("No IFlow found for ProcessDirect address %v called by %v", ref.Value, node.Id)
+				}
+				for _, callee := range endpoints[ref.Value] {
+					g.AddEdge(node.Id, callee, EdgeProcessDirect, ref.Value, "IntegrationFlow")
+				}
+			}
+		}
+	}
+
+	if err = g.addValueMappingEdges(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// addValueMappingEdges adds the dependencies on value mappings. Value mappings are not referenced by ID but looked up
+// by agency and identifier at runtime, so an artifact is assumed to use a value mapping if both the agency and
+// identifier of one of its entries are quoted in a file of the artifact, e.g. in a script or message mapping
+func (g *Graph) addValueMappingEdges() error {
+	keys := map[string][][2]string{}
+	for _, node := range g.Nodes {
+		if node.Type != "ValueMapping" || node.External {
+			continue
+		}
+		vmKeys, err := readValueMappingKeys(filepath.Join(node.Dir, "value_mapping.xml"))
+		if err != nil {
+			return err
+		}
+		keys[node.Id] = vmKeys
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, node := range slices.Clone(g.Nodes) {
+		if node.Type == "ValueMapping" || node.External {
+			continue
+		}
+		resourcesDir := filepath.Join(node.Dir, "src", "main", "resources")
+		err := filepath.WalkDir(resourcesDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() || filepath.Base(path) == "parameters.prop" {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, vm := range g.Nodes {
+				for _, key := range keys[vm.Id] {
+					if quoted(content, key[0]) && quoted(content, key[1]) {
+						g.AddEdge(node.Id, vm.Id, EdgeValueMapping, key[0]+"/"+key[1], "ValueMapping")
+						break
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// readValueMappingKeys returns the distinct agency and identifier pairs of the entries of value_mapping.xml
+func readValueMappingKeys(filePath string) ([][2]string, error) {
+	if !file.Exists(filePath) {
+		return nil, nil
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromFile(filePath); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var keys [][2]string
+	for _, entry := range doc.FindElements("//entry") {
+		agency, schema := entry.SelectElement("agency"), entry.SelectElement("schema")
+		if agency == nil || schema == nil {
+			continue
+		}
+		key := [2]string{strings.TrimSpace(agency.Text()), strings.TrimSpace(schema.Text())}
+		if key[0] != "" && key[1] != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// quoted checks if the value is in the content as a string literal or XML text or attribute
+func quoted(content []byte, value string) bool {
+	text := string(content)
+	for _, pattern := range []string{`"%v"`, `'%v'`, `>%v<`} {
+		if strings.Contains(text, fmt.Sprintf(pattern, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Levels returns the IDs of the artifacts grouped so that the artifacts of each level only depend on artifacts of
+// the previous levels. The artifacts of a level can be processed in parallel. Artifacts in or depending on a cycle,
+// e.g. IFlows calling each other with ProcessDirect, cannot be ordered and are returned in the last level and as cyclic
+func (g *Graph) Levels() (levels [][]string, cyclic []string) {
+	dependencies := map[string][]string{}
+	for _, e := range g.Edges {
+		dependencies[e.From] = append(dependencies[e.From], e.To)
+	}
+	done := map[string]bool{}
+	remaining := slices.Clone(g.Nodes)
+	for len(remaining) > 0 {
+		var level []string
+		var next []*Node
+		for _, node := range remaining {
+			ready := true
+			for _, dependency := range dependencies[node.Id] {
+				if !done[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, node.Id)
+			} else {
+				next = append(next, node)
+			}
+		}
+		if len(level) == 0 {
+			for _, node := range next {
+				cyclic = append(cyclic, node.Id)
+			}
+			return append(levels, cyclic), cyclic
+		}
+		for _, id := range level {
+			done[id] = true
+		}
+		levels = append(levels, level)
+		remaining = next
+	}
+	return levels, nil
+}
+
+// Order returns the IDs sorted so that each artifact comes after the artifacts it depends on, including dependencies
+// through artifacts that are not in ids. IDs that are not in the graph are kept at the end in their original order
+func (g *Graph) Order(ids []string) [][]string {
+	levels, _ := g.Levels()
+	levelOf := map[string]int{}
+	for i, level := range levels {
+		for _, id := range level {
+			levelOf[id] = i
+		}
+	}
+	ordered := make([][]string, len(levels)+1)
+	for _, id := range ids {
+		if i, found := levelOf[id]; found {
+			ordered[i] = append(ordered[i], id)
+		} else {
+			ordered[len(levels)] = append(ordered[len(levels)], id)
+		}
+	}
+	return slices.DeleteFunc(ordered, func(level []string) bool { return len(level) == 0 })
+}
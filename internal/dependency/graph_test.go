@@ -0,0 +1,166 @@
+package dependency
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const iflowTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd">
+  <bpmn2:collaboration id="Collaboration_1">
+    <bpmn2:messageFlow id="MessageFlow_1">
+      <bpmn2:extensionElements>
+        <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+        <ifl:property><key>direction</key><value>%DIRECTION%</value></ifl:property>
+        <ifl:property><key>address</key><value>%ADDRESS%</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:messageFlow>
+  </bpmn2:collaboration>
+  <bpmn2:process id="Process_1">
+    <bpmn2:callActivity id="CallActivity_1">
+      <bpmn2:extensionElements>
+        %PROPERTIES%
+      </bpmn2:extensionElements>
+    </bpmn2:callActivity>
+  </bpmn2:process>
+</bpmn2:definitions>
+`
+
+func iflow(direction string, address string, properties string) string {
+	return strings.NewReplacer("%DIRECTION%", direction, "%ADDRESS%", address, "%PROPERTIES%", properties).Replace(iflowTemplate)
+}
+
+func manifest(id string, bundleType string) string {
+	return "Manifest-Version: 1.0\nBundle-SymbolicName: " + id + "; singleton:=true\nSAP-BundleType: " + bundleType + "\n\n"
+}
+
+// writeSnapshot writes artifacts in the layout of a snapshot, with a subdirectory per package
+func writeSnapshot(t *testing.T) string {
+	baseDir := t.TempDir()
+	files := map[string]string{
+		"Common/SC_Common/META-INF/MANIFEST.MF":         manifest("SC_Common", "ScriptCollection"),
+		"Common/MM_Order/META-INF/MANIFEST.MF":          manifest("MM_Order", "MessageMapping"),
+		"Common/MM_Order/src/main/resources/Order.mmap": `<mapping><function name="valueMap" sourceAgency="S4" sourceIdentifier="Plant"/></mapping>`,
+		"Common/VM_Plants/META-INF/MANIFEST.MF":         manifest("VM_Plants", "ValueMapping"),
+		"Common/VM_Plants/value_mapping.xml":            `<vm version="2.0"><group id="1"><entry><agency>S4</agency><schema>Plant</schema><value>1000</value></entry></group></vm>`,
+		"Orders/Order_Create/META-INF/MANIFEST.MF":      manifest("Order_Create", "IntegrationFlow"),
+		"Orders/Order_Create/src/main/resources/scenarioflows/integrationflow/Order_Create.iflw": iflow("Receiver", "{{Invoice_Address}}",
+			`<ifl:property><key>scriptBundleId</key><value>SC_Common</value></ifl:property>
+        <ifl:property><key>scriptBundleId</key><value>SC_Missing</value></ifl:property>
+        <ifl:property><key>mappinguri</key><value>mmap://MM_Order/src/main/resources/mapping/Order.mmap</value></ifl:property>`),
+		"Orders/Order_Create/src/main/resources/parameters.prop":                                     "Invoice_Address=/invoices\n",
+		"Orders/Invoice_Create/META-INF/MANIFEST.MF":                                                 manifest("Invoice_Create", "IntegrationFlow"),
+		"Orders/Invoice_Create/src/main/resources/scenarioflows/integrationflow/Invoice_Create.iflw": iflow("Sender", "/invoices", ""),
+		"Orders/Invoice_Create/src/main/resources/script/lookup.groovy":                              `def plant = service.getMappedValue('S4', 'Plant', plantId, 'ERP', 'Plant')`,
+	}
+	for name, content := range files {
+		path := filepath.Join(baseDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+	return baseDir
+}
+
+func edges(g *Graph) []string {
+	var result []string
+	for _, e := range g.Edges {
+		result = append(result, e.From+" -"+e.Kind+"-> "+e.To)
+	}
+	return result
+}
+
+func TestBuild_Snapshot(t *testing.T) {
+	g, err := Build(writeSnapshot(t))
+	if err != nil {
+		t.Fatalf("Build failed with error - %v", err)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"MM_Order -value-mapping-> VM_Plants",
+		"Order_Create -script-collection-> SC_Common",
+		"Order_Create -script-collection-> SC_Missing",
+		"Order_Create -message-mapping-> MM_Order",
+		"Order_Create -process-direct-> Invoice_Create",
+		"Invoice_Create -value-mapping-> VM_Plants",
+	}, edges(g), "Incorrect edges")
+	assert.Equal(t, "Orders", g.Node("Order_Create").PackageId, "Incorrect package of IFlow")
+	assert.True(t, g.Node("SC_Missing").External, "Missing script collection not external")
+	assert.Equal(t, "ScriptCollection", g.Node("SC_Missing").Type, "Incorrect type of external node")
+}
+
+func TestGraph_Levels(t *testing.T) {
+	g, err := Build(writeSnapshot(t))
+	if err != nil {
+		t.Fatalf("Build failed with error - %v", err)
+	}
+
+	levels, cyclic := g.Levels()
+	assert.Nil(t, cyclic, "Unexpected cycle")
+	if assert.Len(t, levels, 3, "Incorrect number of levels") {
+		assert.ElementsMatch(t, []string{"SC_Common", "VM_Plants", "SC_Missing"}, levels[0], "Incorrect first level")
+		assert.ElementsMatch(t, []string{"MM_Order", "Invoice_Create"}, levels[1], "Incorrect second level")
+		assert.Equal(t, []string{"Order_Create"}, levels[2], "Incorrect last level")
+	}
+	assert.Equal(t, [][]string{{"Invoice_Create"}, {"Order_Create"}, {"Unknown"}}, g.Order([]string{"Order_Create", "Unknown", "Invoice_Create"}), "Incorrect order")
+}
+
+func TestGraph_Cycle(t *testing.T) {
+	g := NewGraph()
+	for _, id := range []string{"A", "B", "C", "D"} {
+		g.AddNode(&Node{Id: id, Type: "IntegrationFlow"})
+	}
+	g.AddEdge("A", "B", EdgeProcessDirect, "/b", "IntegrationFlow")
+	g.AddEdge("B", "A", EdgeProcessDirect, "/a", "IntegrationFlow")
+	g.AddEdge("C", "D", EdgeProcessDirect, "/d", "IntegrationFlow")
+
+	levels, cyclic := g.Levels()
+	assert.Equal(t, [][]string{{"D"}, {"C"}, {"A", "B"}}, levels, "Incorrect levels")
+	assert.Equal(t, []string{"A", "B"}, cyclic, "Cycle not detected")
+}
+
+func TestWrite_Formats(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{Id: "Order.Create", Type: "IntegrationFlow", PackageId: "Orders"})
+	g.AddEdge("Order.Create", "SC_Common", EdgeScriptCollection, "scriptBundleId", "ScriptCollection")
+
+	var dot bytes.Buffer
+	if err := WriteDOT(&dot, g); err != nil {
+		t.Fatalf("WriteDOT failed with error - %v", err)
+	}
+	assert.Contains(t, dot.String(), "subgraph cluster_0 {\n    label=\"Orders\";\n    \"Order.Create\" [label=\"Order.Create\\nIntegrationFlow\", tooltip=\"IntegrationFlow\"];\n  }", "Incorrect DOT cluster")
+	assert.Contains(t, dot.String(), `"SC_Common" [label="SC_Common\nScriptCollection", tooltip="ScriptCollection", style=dashed];`, "Incorrect DOT external node")
+	assert.Contains(t, dot.String(), `"Order.Create" -> "SC_Common" [label="script-collection"];`, "Incorrect DOT edge")
+
+	var mermaid bytes.Buffer
+	if err := WriteMermaid(&mermaid, g); err != nil {
+		t.Fatalf("WriteMermaid failed with error - %v", err)
+	}
+	assert.Equal(t, `flowchart LR
+  subgraph p0["Orders"]
+    n0_Order_Create["Order.Create<br/>IntegrationFlow"]
+  end
+  n1_SC_Common(["SC_Common<br/>ScriptCollection"])
+  n0_Order_Create -->|script-collection| n1_SC_Common
+`, mermaid.String(), "Incorrect Mermaid flowchart")
+
+	var json bytes.Buffer
+	if err := WriteJSON(&json, g); err != nil {
+		t.Fatalf("WriteJSON failed with error - %v", err)
+	}
+	assert.Contains(t, json.String(), `"levels": [
+    [
+      "SC_Common"
+    ],
+    [
+      "Order.Create"
+    ]
+  ]`, "Levels not included in JSON")
+}
@@ -0,0 +1,129 @@
+package dependency
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-errors/errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// WriteJSON writes the nodes and edges of the graph as JSON, together with the levels of the artifacts in the order
+// they can be created or deployed
+func WriteJSON(w io.Writer, g *Graph) error {
+	levels, cyclic := g.Levels()
+	content, err := json.MarshalIndent(struct {
+		*Graph
+		Levels [][]string `json:"levels"`
+		Cyclic []string   `json:"cyclic,omitempty"`
+	}{g, levels, cyclic}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", content)
+	return err
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz, with a cluster per package
+func WriteDOT(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	writeNode := func(indent string, n *Node) {
+		attributes := fmt.Sprintf("label=%v, tooltip=%v", dotQuote(n.Id+"\n"+n.Type), dotQuote(n.Type))
+		if n.External {
+			attributes += ", style=dashed"
+		}
+		sb.WriteString(fmt.Sprintf("%v%v [%v];\n", indent, dotQuote(n.Id), attributes))
+	}
+	for i, packageId := range packageIds(g) {
+		if packageId == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+		sb.WriteString(fmt.Sprintf("    label=%v;\n", dotQuote(packageId)))
+		for _, n := range g.Nodes {
+			if n.PackageId == packageId {
+				writeNode("    ", n)
+			}
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if n.PackageId == "" {
+			writeNode("  ", n)
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %v -> %v [label=%v];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Kind)))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var mermaidIdPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// WriteMermaid writes the graph as a Mermaid flowchart, e.g. for rendering in Markdown files of GitHub
+func WriteMermaid(w io.Writer, g *Graph) error {
+	// Artifact IDs may contain characters that are not allowed in Mermaid IDs, so the nodes are numbered
+	mermaidIds := map[string]string{}
+	for i, n := range g.Nodes {
+		mermaidIds[n.Id] = fmt.Sprintf("n%d_%v", i, mermaidIdPattern.ReplaceAllString(n.Id, "_"))
+	}
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	writeNode := func(indent string, n *Node) {
+		label := mermaidQuote(n.Id + "<br/>" + n.Type)
+		if n.External {
+			sb.WriteString(fmt.Sprintf("%v%v([%v])\n", indent, mermaidIds[n.Id], label))
+		} else {
+			sb.WriteString(fmt.Sprintf("%v%v[%v]\n", indent, mermaidIds[n.Id], label))
+		}
+	}
+	for i, packageId := range packageIds(g) {
+		if packageId == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  subgraph p%d[%v]\n", i, mermaidQuote(packageId)))
+		for _, n := range g.Nodes {
+			if n.PackageId == packageId {
+				writeNode("    ", n)
+			}
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, n := range g.Nodes {
+		if n.PackageId == "" {
+			writeNode("  ", n)
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %v -->|%v| %v\n", mermaidIds[e.From], e.Kind, mermaidIds[e.To]))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// packageIds returns the distinct package IDs of the nodes in the order they are found
+func packageIds(g *Graph) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, n := range g.Nodes {
+		if !seen[n.PackageId] {
+			seen[n.PackageId] = true
+			ids = append(ids, n.PackageId)
+		}
+	}
+	return ids
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
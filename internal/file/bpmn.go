@@ -4,10 +4,18 @@ import (
 	"fmt"
 	"github.com/beevik/etree"
 	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/magiconair/properties"
 	"github.com/rs/zerolog/log"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 )
 
+// BPMNDir is the directory of the BPMN2 files of an IFlow relative to the artifact directory
+const BPMNDir = "src/main/resources/scenarioflows/integrationflow"
+
 func UpdateBPMN(artifactDir string, scriptMap []string) error {
 	if len(scriptMap) > 0 {
 		// Extract collection into key pairs
@@ -20,7 +28,7 @@ func UpdateBPMN(artifactDir string, scriptMap []string) error {
 		}
 
 		if len(output) != 0 {
-			bpmnDir := fmt.Sprintf("%v/src/main/resources/scenarioflows/integrationflow", artifactDir)
+			bpmnDir := fmt.Sprintf("%v/%v", artifactDir, BPMNDir)
 			entries, err := os.ReadDir(bpmnDir)
 			if err != nil {
 				return err
@@ -70,3 +78,273 @@ func updateXML(filePath string, scripts map[string]string) error {
 	}
 	return nil
 }
+
+const bpmnDiagramNamespace = "http://www.omg.org/spec/BPMN/20100524/DI"
+
+// CompareBPMNFiles compares two BPMN2 files semantically. Attribute order, namespace prefixes and
+// formatting are ignored, and so are the diagram elements (bpmndi) if ignoreDiagram is set.
+// It returns nil if there is no difference.
+func CompareBPMNFiles(firstFile string, secondFile string, ignoreDiagram bool, contextLines int) (*FileDiff, error) {
+	first, err := CanonicalBPMN(firstFile, ignoreDiagram)
+	if err != nil {
+		return nil, err
+	}
+	second, err := CanonicalBPMN(secondFile, ignoreDiagram)
+	if err != nil {
+		return nil, err
+	}
+	return CompareContent(first, second, &DiffOptions{ContextLines: contextLines}), nil
+}
+
+// CanonicalBPMN returns the content of a BPMN2 file in a normalised form with one element,
+// attribute or text per line, so that equivalent XML documents have the same content
+func CanonicalBPMN(filePath string, ignoreDiagram bool) ([]byte, error) {
+	doc := etree.NewDocument()
+	err := doc.ReadFromFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	root := doc.Root()
+	if root == nil {
+		return nil, fmt.Errorf("BPMN2 file %v does not have a root element", filePath)
+	}
+	var sb strings.Builder
+	writeCanonicalElement(&sb, root, 0, ignoreDiagram)
+	return []byte(sb.String()), nil
+}
+
+func writeCanonicalElement(sb *strings.Builder, e *etree.Element, depth int, ignoreDiagram bool) {
+	namespace := e.NamespaceURI()
+	if ignoreDiagram && namespace == bpmnDiagramNamespace {
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	sb.WriteString(fmt.Sprintf("%v<{%v}%v>\n", indent, namespace, e.Tag))
+
+	// Namespace declarations are dropped as prefixes are replaced by the namespace URI
+	var attrs []string
+	for _, attr := range e.Attr {
+		if attr.Space == "xmlns" || (attr.Space == "" && attr.Key == "xmlns") {
+			continue
+		}
+		key := attr.Key
+		if attr.Space != "" {
+			key = fmt.Sprintf("{%v}%v", attr.NamespaceURI(), attr.Key)
+		}
+		attrs = append(attrs, fmt.Sprintf("%v  @%v=%q\n", indent, key, attr.Value))
+	}
+	slices.Sort(attrs)
+	for _, attr := range attrs {
+		sb.WriteString(attr)
+	}
+
+	if text := strings.TrimSpace(e.Text()); text != "" {
+		sb.WriteString(fmt.Sprintf("%v  %q\n", indent, text))
+	}
+	for _, child := range e.ChildElements() {
+		writeCanonicalElement(sb, child, depth+1, ignoreDiagram)
+	}
+}
+
+// Kinds of the security material of the tenant referenced in IFlows
+const (
+	AliasCredential = "credential"
+	AliasKeystore   = "keystore"
+)
+
+// AliasReference is a reference of an IFlow to security material of the tenant
+type AliasReference struct {
+	Alias string
+	Kind  string
+	Key   string
+	File  string
+}
+
+// ExternalParameterPattern matches the references {{name}} to externalised parameters in the BPMN2 files of IFlows
+var ExternalParameterPattern = regexp.MustCompile(`\{\{([^{}]+)}}`)
+
+// FindSecurityAliases returns the references to credentials and keystore entries in the properties of the BPMN2
+// files of the IFlow, e.g. credentialName or privateKeyAlias of the adapters. Externalised parameters are resolved
+// with parameters.prop of the IFlow, and dynamic values like ${header.name} are skipped as they are only known at runtime
+func FindSecurityAliases(artifactDir string) ([]*AliasReference, error) {
+	bpmnFiles, parameters, err := readBPMNFiles(artifactDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []*AliasReference
+	for filePath, doc := range bpmnFiles {
+		for _, property := range doc.FindElements("//ifl:property") {
+			key, value := property.SelectElement("key"), property.SelectElement("value")
+			if key == nil || value == nil {
+				continue
+			}
+			kind := aliasKind(key.Text())
+			if kind == "" {
+				continue
+			}
+			alias := resolveParameters(value.Text(), parameters, filePath)
+			if alias == "" {
+				continue
+			}
+			references = append(references, &AliasReference{Alias: alias, Kind: kind, Key: key.Text(), File: filePath})
+		}
+	}
+	slices.SortStableFunc(references, func(a, b *AliasReference) int { return strings.Compare(a.File, b.File) })
+	return references, nil
+}
+
+// readBPMNFiles returns the parsed BPMN2 files of the IFlow by path, and the parameters of parameters.prop
+func readBPMNFiles(artifactDir string) (map[string]*etree.Document, *properties.Properties, error) {
+	bpmnDir := fmt.Sprintf("%v/%v", artifactDir, BPMNDir)
+	entries, err := os.ReadDir(bpmnDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, 0)
+	}
+	parameters := properties.NewProperties()
+	parametersFile := fmt.Sprintf("%v/src/main/resources/parameters.prop", artifactDir)
+	if Exists(parametersFile) {
+		parameters, err = properties.LoadFile(parametersFile, properties.UTF8)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, 0)
+		}
+	}
+
+	bpmnFiles := map[string]*etree.Document{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".iflw") {
+			continue
+		}
+		filePath := fmt.Sprintf("%v/%v", bpmnDir, entry.Name())
+		doc := etree.NewDocument()
+		if err = doc.ReadFromFile(filePath); err != nil {
+			return nil, nil, errors.Wrap(err, 0)
+		}
+		bpmnFiles[filePath] = doc
+	}
+	return bpmnFiles, parameters, nil
+}
+
+// resolveParameters replaces the externalised parameters in the value of a property with parameters.prop. It returns
+// an empty string if the value is only known at runtime or a parameter is not found
+func resolveParameters(value string, parameters *properties.Properties, filePath string) string {
+	resolved := ExternalParameterPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := ExternalParameterPattern.FindStringSubmatch(placeholder)[1]
+		if parameterValue, found := parameters.Get(name); found {
+			return parameterValue
+		}
+		log.Warn().Msgf("Externalised parameter %v of %v not found in parameters.prop", name, filePath)
+		return placeholder
+	})
+	resolved = strings.TrimSpace(resolved)
+	if strings.Contains(resolved, "${") || ExternalParameterPattern.MatchString(resolved) {
+		return ""
+	}
+	return resolved
+}
+
+// aliasKind returns the kind of security material referenced by the property of the BPMN2 file, or an empty string
+// if the property does not reference security material
+func aliasKind(key string) string {
+	normalised := strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	switch {
+	case strings.HasSuffix(normalised, "credentialname"):
+		return AliasCredential
+	case strings.HasSuffix(normalised, "keyalias"), strings.HasSuffix(normalised, "certificatealias"), strings.HasSuffix(normalised, "signeralias"):
+		return AliasKeystore
+	default:
+		return ""
+	}
+}
+
+const (
+	ReferenceScriptCollection      = "script-collection"
+	ReferenceMessageMapping        = "message-mapping"
+	ReferenceProcessDirectCall     = "process-direct-call"
+	ReferenceProcessDirectEndpoint = "process-direct-endpoint"
+)
+
+// ArtifactReference is a reference of an IFlow to another artifact, e.g. a script collection, or an address of a
+// ProcessDirect adapter. For ProcessDirect, the receiver channels call the address (ReferenceProcessDirectCall) of the
+// sender channel of another IFlow (ReferenceProcessDirectEndpoint)
+type ArtifactReference struct {
+	Kind  string
+	Value string
+	Key   string
+	File  string
+}
+
+// FindArtifactReferences returns the references of the IFlow to other artifacts in the BPMN2 files, i.e.
+// scriptBundleId of script steps, mappinguri of mapping steps that use a message mapping artifact, and the addresses
+// of ProcessDirect channels. Externalised parameters are resolved with parameters.prop of the IFlow
+func FindArtifactReferences(artifactDir string) ([]*ArtifactReference, error) {
+	bpmnFiles, parameters, err := readBPMNFiles(artifactDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []*ArtifactReference
+	for filePath, doc := range bpmnFiles {
+		for _, property := range doc.FindElements("//ifl:property") {
+			key, value := property.SelectElement("key"), property.SelectElement("value")
+			if key == nil || value == nil {
+				continue
+			}
+			var kind string
+			resolved := resolveParameters(value.Text(), parameters, filePath)
+			switch key.Text() {
+			case "scriptBundleId":
+				kind = ReferenceScriptCollection
+			case "mappinguri":
+				kind = ReferenceMessageMapping
+				resolved = mappingArtifactId(resolved)
+			}
+			if kind != "" && resolved != "" {
+				references = append(references, &ArtifactReference{Kind: kind, Value: resolved, Key: key.Text(), File: filePath})
+			}
+		}
+
+		for _, channel := range doc.FindElements("//bpmn2:messageFlow") {
+			channelProperties := map[string]string{}
+			for _, property := range channel.FindElements(".//ifl:property") {
+				key, value := property.SelectElement("key"), property.SelectElement("value")
+				if key != nil && value != nil {
+					channelProperties[key.Text()] = value.Text()
+				}
+			}
+			if channelProperties["ComponentType"] != "ProcessDirect" {
+				continue
+			}
+			address := resolveParameters(channelProperties["address"], parameters, filePath)
+			if address == "" {
+				continue
+			}
+			kind := ReferenceProcessDirectCall
+			if channelProperties["direction"] == "Sender" {
+				kind = ReferenceProcessDirectEndpoint
+			}
+			references = append(references, &ArtifactReference{Kind: kind, Value: address, Key: "address", File: filePath})
+		}
+	}
+	slices.SortStableFunc(references, func(a, b *ArtifactReference) int { return strings.Compare(a.File, b.File) })
+	return references, nil
+}
+
+// mappingArtifactId returns the ID of the message mapping artifact in the mappinguri of a mapping step, or an empty
+// string if the mapping is part of the IFlow, e.g. dir://mmap/src/main/resources/mapping/Order.mmap
+func mappingArtifactId(uri string) string {
+	if strings.HasPrefix(uri, "dir://") || strings.HasPrefix(uri, "src/main/resources/") || strings.HasPrefix(uri, "/") {
+		return ""
+	}
+	if _, rest, found := strings.Cut(uri, "://"); found {
+		uri = rest
+	} else if _, rest, found := strings.Cut(uri, ":"); found {
+		uri = rest
+	}
+	id, _, _ := strings.Cut(uri, "/")
+	id, _, _ = strings.Cut(id, ":")
+	return id
+}
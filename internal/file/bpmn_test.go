@@ -0,0 +1,165 @@
+package file
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBPMN = `<?xml version="1.0" encoding="UTF-8"?><bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:bpmndi="http://www.omg.org/spec/BPMN/20100524/DI" xmlns:dc="http://www.omg.org/spec/DD/20100524/DC" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd" id="Definitions_1">
+    <bpmn2:process id="Process_1" name="Integration Process">
+        <bpmn2:callActivity id="CallActivity_1" name="Script">
+            <bpmn2:extensionElements>
+                <ifl:property>
+                    <key>script</key>
+                    <value>script1.groovy</value>
+                </ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:callActivity>
+    </bpmn2:process>
+    <bpmndi:BPMNDiagram id="BPMNDiagram_1">
+        <bpmndi:BPMNShape bpmnElement="CallActivity_1" id="BPMNShape_CallActivity_1">
+            <dc:Bounds height="60.0" width="100.0" x="400.0" y="140.0"/>
+        </bpmndi:BPMNShape>
+    </bpmndi:BPMNDiagram>
+</bpmn2:definitions>`
+
+// Same content as testBPMN with different prefixes, attribute order and formatting
+const testBPMNReserialised = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:di="http://www.omg.org/spec/BPMN/20100524/DI" xmlns:dc="http://www.omg.org/spec/DD/20100524/DC" xmlns:sap="http:///com.sap.ifl.model/Ifl.xsd" id="Definitions_1">
+  <bpmn:process name="Integration Process" id="Process_1">
+    <bpmn:callActivity name="Script" id="CallActivity_1">
+      <bpmn:extensionElements>
+        <sap:property><key>script</key><value>script1.groovy</value></sap:property>
+      </bpmn:extensionElements>
+    </bpmn:callActivity>
+  </bpmn:process>
+  <di:BPMNDiagram id="BPMNDiagram_1">
+    <di:BPMNShape id="BPMNShape_CallActivity_1" bpmnElement="CallActivity_1">
+      <dc:Bounds x="400.0" y="140.0" width="100.0" height="60.0"/>
+    </di:BPMNShape>
+  </di:BPMNDiagram>
+</bpmn:definitions>`
+
+func compareTestBPMN(t *testing.T, first string, second string, ignoreDiagram bool) *FileDiff {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "first.iflw", first)
+	writeTestFile(t, dir, "second.iflw", second)
+	fileDiff, err := CompareBPMNFiles(filepath.Join(dir, "first.iflw"), filepath.Join(dir, "second.iflw"), ignoreDiagram, 3)
+	if err != nil {
+		t.Fatalf("CompareBPMNFiles failed with error - %v", err)
+	}
+	return fileDiff
+}
+
+func TestCompareBPMNFiles_Reserialised(t *testing.T) {
+	assert.Nil(t, compareTestBPMN(t, testBPMN, testBPMNReserialised, false), "BPMN contents differ")
+}
+
+func TestCompareBPMNFiles_DiagramChange(t *testing.T) {
+	second := replaceOnce(t, testBPMN, `x="400.0" y="140.0"`, `x="480.0" y="140.0"`)
+
+	assert.NotNil(t, compareTestBPMN(t, testBPMN, second, false), "BPMN contents do not differ")
+	assert.Nil(t, compareTestBPMN(t, testBPMN, second, true), "BPMN contents differ when ignoring diagram")
+}
+
+func TestCompareBPMNFiles_PropertyChange(t *testing.T) {
+	second := replaceOnce(t, testBPMN, "script1.groovy", "script2.groovy")
+
+	fileDiff := compareTestBPMN(t, testBPMN, second, true)
+	if assert.NotNil(t, fileDiff, "BPMN contents do not differ") {
+		assert.Contains(t, fileDiff.Hunks[0], `-            "script1.groovy"`)
+		assert.Contains(t, fileDiff.Hunks[0], `+            "script2.groovy"`)
+	}
+}
+
+func TestCompareDirectories_SemanticBPMN(t *testing.T) {
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+	writeTestFile(t, firstDir, "scenarioflows/integrationflow/flow.iflw", testBPMN)
+	writeTestFile(t, secondDir, "scenarioflows/integrationflow/flow.iflw", testBPMNReserialised)
+
+	result, err := CompareDirectories(firstDir, secondDir, DirectoryDiffOptions())
+	if err != nil {
+		t.Fatalf("CompareDirectories failed with error - %v", err)
+	}
+	assert.False(t, result.HasChanges(), "Directory contents differ")
+}
+
+func replaceOnce(t *testing.T, s string, old string, new string) string {
+	replaced := strings.Replace(s, old, new, 1)
+	if replaced == s {
+		t.Fatalf("%v not found in test content", old)
+	}
+	return replaced
+}
+
+func TestFindSecurityAliases(t *testing.T) {
+	artifactDir := t.TempDir()
+	properties := map[string]string{
+		"credentialName":  "SFTP_User",
+		"credential_name": "{{OAuth_Credential}}",
+		"privateKeyAlias": "{{Missing_Parameter}}",
+		"publicKeyAlias":  "partner_cert",
+		"CredentialName":  "${header.credential}",
+		"script":          "script1.groovy",
+	}
+	var content strings.Builder
+	for _, key := range []string{"credentialName", "credential_name", "privateKeyAlias", "publicKeyAlias", "CredentialName", "script"} {
+		content.WriteString("<ifl:property><key>" + key + "</key><value>" + properties[key] + "</value></ifl:property>\n")
+	}
+	iflow := replaceOnce(t, testBPMN, `<ifl:property>`, content.String()+`<ifl:property>`)
+	writeTestFile(t, artifactDir, "src/main/resources/scenarioflows/integrationflow/flow.iflw", iflow)
+	writeTestFile(t, artifactDir, "src/main/resources/parameters.prop", "OAuth_Credential=S4_OAuth\n")
+
+	references, err := FindSecurityAliases(artifactDir)
+	if err != nil {
+		t.Fatalf("FindSecurityAliases failed with error - %v", err)
+	}
+	if assert.Len(t, references, 3, "Incorrect number of references") {
+		assert.Equal(t, &AliasReference{Alias: "SFTP_User", Kind: AliasCredential, Key: "credentialName", File: filepath.Join(artifactDir, "src/main/resources/scenarioflows/integrationflow/flow.iflw")}, references[0], "Incorrect credential reference")
+		assert.Equal(t, "S4_OAuth", references[1].Alias, "Externalised parameter not resolved")
+		assert.Equal(t, AliasKeystore, references[2].Kind, "Incorrect kind of keystore reference")
+		assert.Equal(t, "partner_cert", references[2].Alias, "Incorrect keystore alias")
+	}
+}
+
+func TestFindArtifactReferences(t *testing.T) {
+	artifactDir := t.TempDir()
+	steps := `<ifl:property><key>scriptBundleId</key><value>{{Script_Collection}}</value></ifl:property>
+<ifl:property><key>mappinguri</key><value>dir://mmap/src/main/resources/mapping/Local.mmap</value></ifl:property>
+<ifl:property><key>mappinguri</key><value>mmap://MM_Order/src/main/resources/mapping/Order.mmap</value></ifl:property>
+`
+	channels := `<bpmn2:collaboration id="Collaboration_1">
+        <bpmn2:messageFlow id="MessageFlow_1" name="ProcessDirect" sourceRef="Participant_1" targetRef="StartEvent_1">
+            <bpmn2:extensionElements>
+                <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+                <ifl:property><key>direction</key><value>Sender</value></ifl:property>
+                <ifl:property><key>address</key><value>/orders</value></ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:messageFlow>
+        <bpmn2:messageFlow id="MessageFlow_2" name="ProcessDirect" sourceRef="EndEvent_1" targetRef="Participant_2">
+            <bpmn2:extensionElements>
+                <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+                <ifl:property><key>direction</key><value>Receiver</value></ifl:property>
+                <ifl:property><key>address</key><value>{{Invoice_Address}}</value></ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:messageFlow>
+    </bpmn2:collaboration>
+    <bpmn2:process`
+	iflow := replaceOnce(t, testBPMN, `<ifl:property>`, steps+`<ifl:property>`)
+	iflow = replaceOnce(t, iflow, `<bpmn2:process`, channels)
+	writeTestFile(t, artifactDir, "src/main/resources/scenarioflows/integrationflow/flow.iflw", iflow)
+	writeTestFile(t, artifactDir, "src/main/resources/parameters.prop", "Script_Collection=SC_Common\nInvoice_Address=/invoices\n")
+
+	references, err := FindArtifactReferences(artifactDir)
+	if err != nil {
+		t.Fatalf("FindArtifactReferences failed with error - %v", err)
+	}
+	var found []string
+	for _, reference := range references {
+		found = append(found, reference.Kind+"="+reference.Value)
+	}
+	assert.ElementsMatch(t, []string{"script-collection=SC_Common", "message-mapping=MM_Order", "process-direct-endpoint=/orders", "process-direct-call=/invoices"}, found, "Incorrect references")
+}
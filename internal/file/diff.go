@@ -1,34 +1,432 @@
 package file
 
 import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
-	"os/exec"
 )
 
-func DiffDirectories(firstDir string, secondDir string) bool {
-	log.Info().Msgf("Executing command: diff --ignore-matching-lines=^Origin.* --strip-trailing-cr --recursive --ignore-all-space --ignore-blank-lines --exclude=parameters.prop --exclude=.DS_Store %v %v", firstDir, secondDir)
-	cmd := exec.Command("diff", "--ignore-matching-lines=^Origin.*", "--strip-trailing-cr", "--recursive", "--ignore-all-space", "--ignore-blank-lines", "--exclude=parameters.prop", "--exclude=.DS_Store", firstDir, secondDir)
+// DiffOptions controls which differences are ignored during comparison
+type DiffOptions struct {
+	// Lines matching any of these patterns are ignored
+	IgnoreLines []*regexp.Regexp
+	// Files with base names matching these patterns are excluded from directory comparison
+	ExcludeFiles []string
+	// Number of unchanged lines shown around each change
+	ContextLines int
+	// BPMN2 files (*.iflw) are compared semantically instead of line by line
+	SemanticBPMN bool
+	// Diagram elements (bpmndi) are ignored in the semantic comparison of BPMN2 files
+	IgnoreDiagram bool
+}
+
+// DirectoryDiffOptions returns the options used for comparing artifact directories
+func DirectoryDiffOptions() *DiffOptions {
+	return &DiffOptions{
+		IgnoreLines:  []*regexp.Regexp{regexp.MustCompile(`^Origin.*`)},
+		ExcludeFiles: []string{"parameters.prop", "parameters.*.prop", ".DS_Store"},
+		ContextLines: 3,
+		SemanticBPMN: true,
+	}
+}
 
-	stdoutStderr, err := cmd.CombinedOutput()
-	// An error means there is a difference
+// FileDiffOptions returns the options used for comparing single files like parameters.prop
+func FileDiffOptions() *DiffOptions {
+	return &DiffOptions{
+		IgnoreLines:  []*regexp.Regexp{regexp.MustCompile(`^#.*`)},
+		ContextLines: 3,
+	}
+}
+
+// FileDiff is the difference of a single file that exists in both sides
+type FileDiff struct {
+	Path   string
+	Binary bool
+	Hunks  []string
+}
+
+// DiffResult lists the files that are added, removed and changed between two directories.
+// Added files exist only in the second directory, removed files only in the first.
+type DiffResult struct {
+	Added   []string
+	Removed []string
+	Changed []*FileDiff
+}
+
+func (r *DiffResult) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// ChangedFiles returns the relative paths of all added, removed and changed files in sorted order
+func (r *DiffResult) ChangedFiles() []string {
+	var paths []string
+	paths = append(paths, r.Added...)
+	paths = append(paths, r.Removed...)
+	for _, c := range r.Changed {
+		paths = append(paths, c.Path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (r *DiffResult) String() string {
+	var sb strings.Builder
+	for _, path := range r.Removed {
+		sb.WriteString(fmt.Sprintf("Only in first: %v\n", path))
+	}
+	for _, path := range r.Added {
+		sb.WriteString(fmt.Sprintf("Only in second: %v\n", path))
+	}
+	for _, c := range r.Changed {
+		if c.Binary {
+			sb.WriteString(fmt.Sprintf("Binary files %v differ\n", c.Path))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("--- a/%v\n+++ b/%v\n", c.Path, c.Path))
+		for _, hunk := range c.Hunks {
+			sb.WriteString(hunk)
+		}
+	}
+	return sb.String()
+}
+
+// DiffDirectories compares two directories with the default options and logs the differences
+func DiffDirectories(firstDir string, secondDir string) *DiffResult {
+	return DiffDirectoriesWithOptions(firstDir, secondDir, DirectoryDiffOptions())
+}
+
+// DiffDirectoriesWithOptions compares two directories and logs the differences.
+// If the directories cannot be compared, the directory itself is reported as changed with path ".".
+func DiffDirectoriesWithOptions(firstDir string, secondDir string, options *DiffOptions) *DiffResult {
+	log.Info().Msgf("Comparing directories %v and %v", firstDir, secondDir)
+	result, err := CompareDirectories(firstDir, secondDir, options)
+	if err != nil {
+		log.Info().Msgf("Diff results:\n%v", err)
+		return &DiffResult{Changed: []*FileDiff{{Path: "."}}}
+	}
+	if result.HasChanges() {
+		log.Info().Msgf("Diff results:\n%v", result)
+	}
+	return result
+}
+
+// DiffFile compares two files ignoring commented lines, blank lines and extra white space, and logs the differences.
+// It returns nil if there is no difference. If the files cannot be compared, they are treated as different.
+func DiffFile(firstFile string, secondFile string) *FileDiff {
+	log.Info().Msgf("Comparing files %v and %v", firstFile, secondFile)
+	fileDiff, err := CompareFiles(firstFile, secondFile, FileDiffOptions())
+	if err != nil {
+		log.Info().Msgf("Diff results:\n%v", err)
+		return &FileDiff{Path: filepath.Base(firstFile)}
+	}
+	if fileDiff != nil {
+		fileDiff.Path = filepath.Base(firstFile)
+		log.Info().Msgf("Diff results:\n%v", strings.Join(fileDiff.Hunks, ""))
+	}
+	return fileDiff
+}
+
+// CompareDirectories recursively compares the files of two directories
+func CompareDirectories(firstDir string, secondDir string, options *DiffOptions) (*DiffResult, error) {
+	firstFiles, err := listFiles(firstDir, options.ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+	secondFiles, err := listFiles(secondDir, options.ExcludeFiles)
 	if err != nil {
-		log.Info().Msgf("Diff results:\n%v", string(stdoutStderr))
+		return nil, err
 	}
 
-	return err != nil
+	result := &DiffResult{}
+	for _, path := range firstFiles {
+		if !slices.Contains(secondFiles, path) {
+			result.Removed = append(result.Removed, path)
+			continue
+		}
+		fileDiff, err := CompareFiles(filepath.Join(firstDir, path), filepath.Join(secondDir, path), options)
+		if err != nil {
+			return nil, err
+		}
+		if fileDiff != nil {
+			fileDiff.Path = path
+			result.Changed = append(result.Changed, fileDiff)
+		}
+	}
+	for _, path := range secondFiles {
+		if !slices.Contains(firstFiles, path) {
+			result.Added = append(result.Added, path)
+		}
+	}
+	return result, nil
+}
+
+// CompareFiles compares the content of two files. It returns nil if there is no difference.
+func CompareFiles(firstFile string, secondFile string, options *DiffOptions) (*FileDiff, error) {
+	if options.SemanticBPMN && filepath.Ext(firstFile) == ".iflw" {
+		return CompareBPMNFiles(firstFile, secondFile, options.IgnoreDiagram, options.ContextLines)
+	}
+	first, err := os.ReadFile(firstFile)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	second, err := os.ReadFile(secondFile)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return CompareContent(first, second, options), nil
 }
 
-func DiffFile(firstFile string, secondFile string) bool {
-	// - ignoring commented lines (beginning with #)
-	// - ignoring blank lines and extra white space
-	log.Info().Msgf("Executing command: diff --ignore-matching-lines=^#.* --strip-trailing-cr --ignore-all-space --ignore-blank-lines %v %v", firstFile, secondFile)
-	cmd := exec.Command("diff", "--ignore-matching-lines=^#.*", "--strip-trailing-cr", "--ignore-all-space", "--ignore-blank-lines", firstFile, secondFile)
+// CompareContent compares two contents line by line. It returns nil if there is no difference.
+func CompareContent(first []byte, second []byte, options *DiffOptions) *FileDiff {
+	if bytes.IndexByte(first, 0) >= 0 || bytes.IndexByte(second, 0) >= 0 {
+		if bytes.Equal(first, second) {
+			return nil
+		}
+		return &FileDiff{Binary: true}
+	}
+	a := splitLines(first, options.IgnoreLines)
+	b := splitLines(second, options.IgnoreLines)
+	edits := diffLines(a, b)
+	hunks := unifiedHunks(edits, options.ContextLines)
+	if len(hunks) == 0 {
+		return nil
+	}
+	return &FileDiff{Hunks: hunks}
+}
 
-	stdoutStderr, err := cmd.CombinedOutput()
-	// An error means there is a difference
+func listFiles(dir string, excludeFiles []string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || isExcluded(d.Name(), excludeFiles) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
 	if err != nil {
-		log.Info().Msgf("Diff results:\n%v", string(stdoutStderr))
+		return nil, errors.Wrap(err, 0)
 	}
+	return files, nil
+}
+
+func isExcluded(name string, excludeFiles []string) bool {
+	for _, pattern := range excludeFiles {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type line struct {
+	number int
+	text   string
+	key    string
+}
+
+// splitLines returns the lines for comparison, dropping blank and ignored lines.
+// Trailing CR and all whitespace are excluded from the comparison key.
+func splitLines(content []byte, ignoreLines []*regexp.Regexp) []line {
+	var lines []line
+	for i, text := range strings.Split(string(content), "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		key := strings.Join(strings.Fields(text), "")
+		if key == "" || matchesAny(text, ignoreLines) {
+			continue
+		}
+		lines = append(lines, line{number: i + 1, text: text, key: key})
+	}
+	return lines
+}
+
+func matchesAny(text string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+type edit struct {
+	op byte // ' ' unchanged, '-' removed, '+' added
+	a  line
+	b  line
+}
 
-	return err != nil
+// Above this number of differences, the lines are reported as fully replaced. The trace of the algorithm
+// grows with the square of the differences, which is about 8 MB per compared file at this limit
+const maxEditDistance = 1000
+
+func diffLines(a []line, b []line) []edit {
+	// Common prefix and suffix do not need the full algorithm
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix].key == b[prefix].key {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix].key == b[len(b)-1-suffix].key {
+		suffix++
+	}
+
+	var edits []edit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{op: ' ', a: a[i], b: b[i]})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{op: ' ', a: a[len(a)-i], b: b[len(b)-i]})
+	}
+	return edits
+}
+
+// myers computes the shortest edit script between a and b
+// Reference - http://www.xmailserver.org/diff2.pdf
+func myers(a []line, b []line) []edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	// Only the diagonals -d to d can be reached in step d, so only this window of v is kept for backtracking
+	var trace [][]int
+	found := false
+	for d := 0; d <= n+m && d <= maxEditDistance; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x].key == b[y].key {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		var edits []edit
+		for _, l := range a {
+			edits = append(edits, edit{op: '-', a: l})
+		}
+		for _, l := range b {
+			edits = append(edits, edit{op: '+', b: l})
+		}
+		return edits
+	}
+
+	// Backtrack through the trace to build the edit script in reverse
+	var reversed []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		// Diagonal k is at index d+k of the window of step d
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{op: ' ', a: a[x], b: b[y]})
+		}
+		if x == prevX {
+			reversed = append(reversed, edit{op: '+', b: b[prevY]})
+		} else {
+			reversed = append(reversed, edit{op: '-', a: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, edit{op: ' ', a: a[x], b: b[y]})
+	}
+	slices.Reverse(reversed)
+	return reversed
+}
+
+func unifiedHunks(edits []edit, contextLines int) []string {
+	var hunks []string
+	i := 0
+	for i < len(edits) {
+		// Find next change
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := max(i-contextLines, 0)
+		// Extend the hunk until there are more than 2 x context unchanged lines
+		end := i
+		unchanged := 0
+		for end < len(edits) && unchanged <= 2*contextLines {
+			if edits[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		end = min(end-unchanged+contextLines, len(edits))
+		hunks = append(hunks, formatHunk(edits[start:end]))
+		i = end
+	}
+	return hunks
+}
+
+func formatHunk(edits []edit) string {
+	var body strings.Builder
+	aStart, bStart, aLen, bLen := 0, 0, 0, 0
+	for _, e := range edits {
+		text := e.a.text
+		if e.op != '+' {
+			if aStart == 0 {
+				aStart = e.a.number
+			}
+			aLen++
+		}
+		if e.op != '-' {
+			if bStart == 0 {
+				bStart = e.b.number
+			}
+			bLen++
+			text = e.b.text
+		}
+		body.WriteString(fmt.Sprintf("%c%v\n", e.op, text))
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%v", aStart, aLen, bStart, bLen, body.String())
 }
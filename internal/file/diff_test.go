@@ -1,24 +1,103 @@
 package file
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestDiffDirectories_SameIgnoringOrigin(t *testing.T) {
-	contentDiffer := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir2/")
+	result := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir2/")
 
-	assert.False(t, contentDiffer, "Directory contents differ")
+	assert.False(t, result.HasChanges(), "Directory contents differ")
 }
 
 func TestDiffDirectories_Different(t *testing.T) {
-	contentDiffer := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir3/")
+	result := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir3/")
 
-	assert.True(t, contentDiffer, "Directory contents do not differ")
+	assert.True(t, result.HasChanges(), "Directory contents do not differ")
+	assert.Contains(t, result.ChangedFiles(), "MANIFEST.MF", "Changed file not reported")
 }
 
 func TestDiffFile_Different(t *testing.T) {
-	fileDiffer := DiffFile("../../test/testdata/DiffComparison/Dir1/MANIFEST.MF", "../../test/testdata/DiffComparison/Dir3/MANIFEST.MF")
+	fileDiff := DiffFile("../../test/testdata/DiffComparison/Dir1/MANIFEST.MF", "../../test/testdata/DiffComparison/Dir3/MANIFEST.MF")
 
-	assert.True(t, fileDiffer, "File contents do not differ")
+	if assert.NotNil(t, fileDiff, "File contents do not differ") {
+		assert.Equal(t, "MANIFEST.MF", fileDiff.Path)
+	}
+}
+
+func TestDiffFile_Same(t *testing.T) {
+	fileDiff := DiffFile("../../test/testdata/DiffComparison/Dir1/MANIFEST.MF", "../../test/testdata/DiffComparison/Dir1/MANIFEST.MF")
+
+	assert.Nil(t, fileDiff, "File contents differ")
+}
+
+func TestCompareContent_IgnoreWhitespaceAndBlankLines(t *testing.T) {
+	first := []byte("Bundle-Name: Dummy\r\nBundle-Version: 1.0.0\r\n")
+	second := []byte("Bundle-Name:  Dummy\n\n   Bundle-Version: 1.0.0\n# Comment\n")
+
+	assert.Nil(t, CompareContent(first, second, FileDiffOptions()), "Contents differ")
+}
+
+func TestCompareContent_Hunks(t *testing.T) {
+	first := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n")
+	second := []byte("a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\n")
+
+	fileDiff := CompareContent(first, second, DirectoryDiffOptions())
+	if assert.NotNil(t, fileDiff, "Contents do not differ") {
+		assert.Equal(t, 1, len(fileDiff.Hunks), "Expected number of hunks = 1")
+		assert.Equal(t, "@@ -2,9 +2,10 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n i\n j\n+k\n", fileDiff.Hunks[0], "Incorrect hunk")
+	}
+}
+
+func TestMyers_AboveMaxEditDistance(t *testing.T) {
+	var a, b []line
+	for i := 0; i < maxEditDistance; i++ {
+		a = append(a, line{key: fmt.Sprintf("a%d", i)})
+		b = append(b, line{key: fmt.Sprintf("b%d", i)})
+	}
+
+	edits := myers(a, b)
+	if assert.Len(t, edits, 2*maxEditDistance, "Incorrect number of edits") {
+		assert.Equal(t, byte('-'), edits[0].op, "Lines not reported as removed")
+		assert.Equal(t, byte('+'), edits[maxEditDistance].op, "Lines not reported as added")
+	}
+}
+
+func TestCompareDirectories_AddedRemovedChanged(t *testing.T) {
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+	writeTestFile(t, firstDir, "META-INF/MANIFEST.MF", "Bundle-Version: 1.0.0\nOrigin-Bundle-Version: 1.0.0\n")
+	writeTestFile(t, secondDir, "META-INF/MANIFEST.MF", "Bundle-Version: 1.0.1\nOrigin-Bundle-Version: 1.0.1\n")
+	writeTestFile(t, firstDir, "script/Removed.groovy", "println 'removed'")
+	writeTestFile(t, secondDir, "script/Added.groovy", "println 'added'")
+	writeTestFile(t, firstDir, "parameters.prop", "key=value1")
+	writeTestFile(t, secondDir, "parameters.prop", "key=value2")
+
+	result, err := CompareDirectories(firstDir, secondDir, DirectoryDiffOptions())
+	if err != nil {
+		t.Fatalf("CompareDirectories failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"script/Added.groovy"}, result.Added, "Incorrect added files")
+	assert.Equal(t, []string{"script/Removed.groovy"}, result.Removed, "Incorrect removed files")
+	if assert.Equal(t, 1, len(result.Changed), "Expected number of changed files = 1") {
+		assert.Equal(t, "META-INF/MANIFEST.MF", result.Changed[0].Path, "Incorrect changed file")
+		assert.Equal(t, "@@ -1,1 +1,1 @@\n-Bundle-Version: 1.0.0\n+Bundle-Version: 1.0.1\n", result.Changed[0].Hunks[0], "Incorrect hunk")
+	}
+	assert.Equal(t, []string{"META-INF/MANIFEST.MF", "script/Added.groovy", "script/Removed.groovy"}, result.ChangedFiles(), "Incorrect changed files")
+}
+
+func writeTestFile(t *testing.T, dir string, name string, content string) {
+	path := filepath.Join(dir, name)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatalf("Directory creation failed with error - %v", err)
+	}
+	err = os.WriteFile(path, []byte(content), os.ModePerm)
+	if err != nil {
+		t.Fatalf("File creation failed with error - %v", err)
+	}
 }
@@ -0,0 +1,68 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/go-errors/errors"
+	"io/fs"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestLineWidth is the maximum length of a line in MANIFEST.MF in bytes, excluding the line break
+const ManifestLineWidth = 72
+
+// ReadManifest returns the headers of the MANIFEST.MF file
+func ReadManifest(manifestPath string) (textproto.MIMEHeader, error) {
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	defer f.Close()
+	headers, err := textproto.NewReader(bufio.NewReader(f)).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("invalid MANIFEST.MF %v: %w", manifestPath, err)
+	}
+	return headers, nil
+}
+
+// ManifestId returns the artifact ID in Bundle-SymbolicName of the MANIFEST.MF headers, without directives like singleton:=true
+func ManifestId(headers textproto.MIMEHeader) string {
+	id, _, _ := strings.Cut(strings.ReplaceAll(headers.Get("Bundle-SymbolicName"), " ", ""), ";")
+	return id
+}
+
+// IsArtifactDir returns true if the directory contains the contents of an artifact with META-INF/MANIFEST.MF
+func IsArtifactDir(dir string) bool {
+	return Exists(filepath.Join(dir, "META-INF", "MANIFEST.MF"))
+}
+
+// FindArtifactDirs returns the artifact directories in the base directory or below it
+func FindArtifactDirs(baseDir string) ([]string, error) {
+	return FindDirs(baseDir, IsArtifactDir)
+}
+
+// FindDirs returns the directories matching match in the base directory or below it. The subdirectories of a
+// matching directory are not searched.
+func FindDirs(baseDir string, match func(dir string) bool) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if match(path) {
+			dirs = append(dirs, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return dirs, nil
+}
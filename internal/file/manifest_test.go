@@ -0,0 +1,25 @@
+package file
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	headers, err := ReadManifest("../../test/testdata/artifacts/create/Integration_Test_IFlow/META-INF/MANIFEST.MF")
+	if err != nil {
+		t.Fatalf("ReadManifest failed with error - %v", err)
+	}
+	assert.Equal(t, "Integration_Test_IFlow", ManifestId(headers), "Incorrect artifact ID")
+	assert.Equal(t, "IntegrationFlow", headers.Get("SAP-BundleType"))
+}
+
+func TestFindArtifactDirs(t *testing.T) {
+	dirs, err := FindArtifactDirs("../../test/testdata/artifacts/create")
+	if err != nil {
+		t.Fatalf("FindArtifactDirs failed with error - %v", err)
+	}
+	assert.Contains(t, dirs, filepath.Clean("../../test/testdata/artifacts/create/Integration_Test_IFlow"))
+	assert.NotContains(t, dirs, filepath.Clean("../../test/testdata/artifacts/create"))
+}
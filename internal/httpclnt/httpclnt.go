@@ -1,12 +1,19 @@
 package httpclnt
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -19,6 +26,11 @@ type HTTPExecuter struct {
 	httpClient    *http.Client
 	AuthType      string
 	showLogs      bool
+	retryPolicy   *RetryPolicy
+	tokenCtx      context.Context
+	tokenSource   oauth2.TokenSource
+	tokenCacheId  string
+	tokenSecret   string
 }
 
 // New returns an initialised HTTPExecuter instance.
@@ -44,10 +56,7 @@ func New(oauthHost string, oauthPath string, clientId string, clientSecret strin
 			ClientSecret: clientSecret,
 			TokenURL:     tokenURL,
 		}
-
-		ctx := context.Background()
-		e.httpClient = conf.Client(ctx)
-		e.AuthType = "OAUTH"
+		e.initOAuth(conf, http.DefaultTransport, clientSecret)
 	} else {
 		if showLogs {
 			log.Debug().Msg("Initialising HTTP client with Basic Authentication")
@@ -60,9 +69,119 @@ func New(oauthHost string, oauthPath string, clientId string, clientSecret strin
 	return e
 }
 
+// NewWithClientCert returns an initialised HTTPExecuter instance that gets the OAuth 2.0 token
+// with an X.509 client certificate (mTLS) instead of a client secret.
+// The certificate and key are either PEM contents or paths to PEM files.
+func NewWithClientCert(oauthHost string, oauthPath string, clientId string, certificate string, key string, host string, scheme string, port int, showLogs bool) *HTTPExecuter {
+	e := new(HTTPExecuter)
+	e.host = host
+	e.scheme = scheme
+	e.port = port
+	e.showLogs = showLogs
+
+	tokenURL := fmt.Sprintf("%v://%v:%d%v", scheme, oauthHost, port, oauthPath)
+	if showLogs {
+		log.Debug().Msgf("Setting up OAuth 2.0 client with client certificate and token URL %v", tokenURL)
+	}
+	conf := &clientcredentials.Config{
+		ClientID:  clientId,
+		TokenURL:  tokenURL,
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+
+	// The certificate is only loaded when the token server requests it, so that errors are returned with the token request
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := LoadClientCertificate(certificate, key)
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		},
+	}
+	// The token cache is encrypted with the content of the private key, not with its path.
+	// If the key cannot be read, the token request fails and nothing is cached.
+	keyPEM, _ := readPEM(key)
+	e.initOAuth(conf, transport, string(keyPEM))
+	return e
+}
+
+func (e *HTTPExecuter) initOAuth(conf *clientcredentials.Config, transport http.RoundTripper, secret string) {
+	// Token requests are retried with the same policy as the API calls
+	tokenClient := &http.Client{Transport: &retryTransport{base: transport, exe: e}}
+	e.tokenCtx = context.WithValue(context.Background(), oauth2.HTTPClient, tokenClient)
+	e.tokenSource = &retryTokenSource{base: conf.TokenSource(e.tokenCtx)}
+	e.tokenCacheId = conf.TokenURL + "|" + conf.ClientID
+	e.tokenSecret = secret
+	e.httpClient = oauth2.NewClient(e.tokenCtx, e.tokenSource)
+	e.AuthType = "OAUTH"
+}
+
+// SetRetryPolicy enables retry of requests that fail with a transient error
+func (e *HTTPExecuter) SetRetryPolicy(policy *RetryPolicy) {
+	e.retryPolicy = policy
+}
+
 func (e *HTTPExecuter) ExecRequestWithCookies(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
+	if e.retryPolicy == nil || e.retryPolicy.MaxRetries <= 0 || (!isIdempotent(method) && !e.retryPolicy.RetryModifying) {
+		return e.execRequest(method, path, body, headers, cookies)
+	}
+
+	// Keep the content of the body so that it can be sent again on retry
+	var content []byte
+	if body != nil && body != http.NoBody {
+		content, err = io.ReadAll(body)
+		if err != nil {
+			return
+		}
+	}
+	return withRetry(e.retryPolicy, context.Background(), method, path, func() (*http.Response, error) {
+		var reqBody io.Reader = http.NoBody
+		if content != nil {
+			reqBody = bytes.NewReader(content)
+		}
+		return e.execRequest(method, path, reqBody, headers, cookies)
+	})
+}
+
+func (e *HTTPExecuter) execRequest(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
+	return e.execURLRequest(method, fmt.Sprintf("%v://%v:%d%v", e.scheme, e.host, e.port, path), body, headers, cookies)
+}
+
+// ExecRequestToURL executes the request to the absolute URL, e.g. of a runtime endpoint of the tenant,
+// with the authentication of the executer. The request is not retried.
+// The credentials are only sent to the hosts of the tenant, see IsTenantHost
+func (e *HTTPExecuter) ExecRequestToURL(method string, rawURL string, body io.Reader, headers map[string]string) (resp *http.Response, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	if !e.IsTenantHost(u.Hostname()) {
+		return nil, fmt.Errorf("Credentials of tenant %v are not sent to host %v outside of the tenant", e.host, u.Hostname())
+	}
+	return e.execURLRequest(method, rawURL, body, headers, nil)
+}
+
+// IsTenantHost returns true if the host is the host of the executer, or a host of the same tenant in the same
+// subaccount domain, e.g. the runtime host mytenant.it-cpi018-rt.cfapps.eu10-003.hana.ondemand.com for the host
+// mytenant.it-cpi018.cfapps.eu10-003.hana.ondemand.com
+func (e *HTTPExecuter) IsTenantHost(host string) bool {
+	if strings.EqualFold(host, e.host) {
+		return true
+	}
+	labels := strings.Split(strings.ToLower(host), ".")
+	tenantLabels := strings.Split(strings.ToLower(e.host), ".")
+	if len(labels) < 3 || len(labels) != len(tenantLabels) {
+		return false
+	}
+	if labels[0] != tenantLabels[0] || !slices.Equal(labels[2:], tenantLabels[2:]) {
+		return false
+	}
+	return labels[1] == tenantLabels[1] || labels[1] == tenantLabels[1]+"-rt"
+}
 
-	url := fmt.Sprintf("%v://%v:%d%v", e.scheme, e.host, e.port, path)
+func (e *HTTPExecuter) execURLRequest(method string, url string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
 	if e.showLogs {
 		log.Debug().Msgf("Executing HTTP request: %v %v", method, url)
 	}
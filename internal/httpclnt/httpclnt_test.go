@@ -185,3 +185,26 @@ func TestBasicAuth(t *testing.T) {
 		t.Fatalf("HTTP call failed with response code - %v", resp.StatusCode)
 	}
 }
+
+func TestIsTenantHost(t *testing.T) {
+	exe := New("", "", "", "", "user", "password", "mytenant.it-cpi018.cfapps.eu10-003.hana.ondemand.com", "https", 443, false)
+	hosts := map[string]bool{
+		"mytenant.it-cpi018.cfapps.eu10-003.hana.ondemand.com":    true,
+		"mytenant.it-cpi018-rt.cfapps.eu10-003.hana.ondemand.com": true,
+		"MyTenant.it-cpi018-rt.cfapps.eu10-003.hana.ondemand.com": true,
+		"other.it-cpi018-rt.cfapps.eu10-003.hana.ondemand.com":    false,
+		"mytenant.it-cpi018-rt.cfapps.eu20-001.hana.ondemand.com": false,
+		"mytenant.it-cpi018-rt.example.com":                       false,
+		"example.com":                                             false,
+	}
+	for host, expected := range hosts {
+		if exe.IsTenantHost(host) != expected {
+			t.Errorf("IsTenantHost(%v) = %v, expected %v", host, !expected, expected)
+		}
+	}
+
+	_, err := exe.ExecRequestToURL(http.MethodGet, "https://example.com/http/orders", http.NoBody, nil)
+	if err == nil {
+		t.Errorf("Credentials sent to host outside of the tenant")
+	}
+}
@@ -0,0 +1,170 @@
+package httpclnt
+
+import (
+	"context"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls the retry of HTTP requests that fail with a transient error
+type RetryPolicy struct {
+	// Maximum number of retries after the first attempt, 0 disables retry
+	MaxRetries int
+	// Delay before the first retry, doubled for each subsequent retry
+	InitialDelay time.Duration
+	// Upper limit of the delay between retries
+	MaxDelay time.Duration
+	// Retry also non-idempotent requests like POST and PUT
+	RetryModifying bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by FlashPipe commands
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:   3,
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     60 * time.Second,
+	}
+}
+
+// retryTransport retries all requests according to the retry policy of the executer.
+// It is used for the OAuth token request, which does not go through ExecRequestWithCookies.
+type retryTransport struct {
+	base http.RoundTripper
+	exe  *HTTPExecuter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A request body that cannot be read again cannot be resent
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return t.base.RoundTrip(req)
+	}
+	attempt := 0
+	return withRetry(t.exe.retryPolicy, req.Context(), req.Method, req.URL.Path, func() (*http.Response, error) {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		attempt++
+		return t.base.RoundTrip(r)
+	})
+}
+
+// retryTokenSource marks the errors of the token requests, which have already been retried by retryTransport
+type retryTokenSource struct {
+	base oauth2.TokenSource
+}
+
+func (s *retryTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, &tokenError{err: err}
+	}
+	return token, nil
+}
+
+// tokenError is the error of a token request that is not retried again by the API call using the token
+type tokenError struct {
+	err error
+}
+
+func (e *tokenError) Error() string {
+	return e.err.Error()
+}
+
+func (e *tokenError) Unwrap() error {
+	return e.err
+}
+
+// withRetry calls send until it succeeds, fails with a non-transient error or the retries are exhausted
+func withRetry(policy *RetryPolicy, ctx context.Context, method string, path string, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := send()
+		if policy == nil || attempt >= policy.MaxRetries || !isTransient(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := backoff(policy, attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			log.Warn().Msgf("HTTP request %v %v failed with response code = %d, retrying in %v (%d/%d)", method, path, resp.StatusCode, delay, attempt+1, policy.MaxRetries)
+			// Drain the body so that the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			log.Warn().Msgf("HTTP request %v %v failed with error = %v, retrying in %v (%d/%d)", method, path, err, delay, attempt+1, policy.MaxRetries)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isIdempotent returns true for the methods that are retried without RetryModifying
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		// Failed token requests have already been retried by retryTransport
+		var tokenErr *tokenError
+		return !errors.As(err, &tokenErr)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the exponential delay for the attempt with jitter between 50% and 100% of the delay
+func backoff(policy *RetryPolicy, attempt int) time.Duration {
+	delay := policy.InitialDelay << attempt
+	if delay <= 0 || delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter parses the Retry-After header value in delay seconds or HTTP date format
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
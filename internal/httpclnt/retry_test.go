@@ -0,0 +1,213 @@
+package httpclnt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:   3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+	}
+}
+
+// newFlakyServer returns a server that responds with failureCode to the first failures calls of each path
+func newFlakyServer(failures int32, failureCode int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{ "access_token": "token123" }`))
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(failureCode)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	return httptest.NewServer(mux), &calls
+}
+
+func TestRetry_GetTransientError(t *testing.T) {
+	svr, calls := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	resp, err := exe.ExecGetRequest("/api/v1/", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load(), "Unexpected number of calls")
+}
+
+func TestRetry_RetriesExhausted(t *testing.T) {
+	svr, calls := newFlakyServer(10, http.StatusTooManyRequests)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	resp, err := exe.ExecGetRequest("/api/v1/", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(4), calls.Load(), "Unexpected number of calls")
+}
+
+func TestRetry_NonTransientError(t *testing.T) {
+	svr, calls := newFlakyServer(2, http.StatusInternalServerError)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	resp, err := exe.ExecGetRequest("/api/v1/", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load(), "Unexpected number of calls")
+}
+
+func TestRetry_ModifyingNotRetriedByDefault(t *testing.T) {
+	svr, calls := newFlakyServer(1, http.StatusBadGateway)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	resp, err := exe.ExecRequestWithCookies(http.MethodPost, "/api/v1/", strings.NewReader(`{}`), nil, nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load(), "Unexpected number of calls")
+}
+
+func TestRetry_ModifyingOptIn(t *testing.T) {
+	var bodies []string
+	var calls atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummy", "dummy", host, "http", port, true)
+	policy := testRetryPolicy()
+	policy.RetryModifying = true
+	exe.SetRetryPolicy(policy)
+
+	resp, err := exe.ExecRequestWithCookies(http.MethodPost, "/api/v1/", strings.NewReader(`{"Id":"Dummy"}`), nil, nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	// Body is sent again on retry
+	assert.Equal(t, []string{`{"Id":"Dummy"}`, `{"Id":"Dummy"}`}, bodies)
+}
+
+func TestRetry_OauthToken(t *testing.T) {
+	var tokenCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if tokenCalls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{ "access_token": "token123" }`))
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New(host, "/oauth/token", "dummyid", "dummysecret", "", "", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	resp, err := exe.ExecGetRequest("/api/v1/", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), tokenCalls.Load(), "Unexpected number of token calls")
+}
+
+func TestRetry_OauthTokenConnectionError(t *testing.T) {
+	var tokenCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+		// Close the connection without response
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	host, port := GetHostPort(svr.URL)
+	exe := New(host, "/oauth/token", "dummyid", "dummysecret", "", "", host, "http", port, true)
+	exe.SetRetryPolicy(testRetryPolicy())
+
+	_, err := exe.ExecGetRequest("/api/v1/", nil)
+	assert.Error(t, err, "HTTP call succeeded without token")
+	// The token request is retried by the token client only, not again by the API call.
+	// Both auth styles (header and params) are tried as the auth style of the token server is not known
+	assert.Equal(t, int32(2*(1+3)), tokenCalls.Load(), "Unexpected number of token calls")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt := 0; attempt < 5; attempt++ {
+		delay := backoff(policy, attempt)
+		expected := min(time.Second<<attempt, policy.MaxDelay)
+		assert.GreaterOrEqual(t, delay, expected/2)
+		assert.LessOrEqual(t, delay, expected)
+	}
+}
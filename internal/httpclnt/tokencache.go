@@ -0,0 +1,196 @@
+package httpclnt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cached tokens that expire within the margin are not reused
+const tokenExpiryMargin = time.Minute
+
+// LoadClientCertificate parses the X.509 certificate and private key, which are either PEM contents or paths to PEM files
+func LoadClientCertificate(certificate string, key string) (tls.Certificate, error) {
+	certPEM, err := readPEM(certificate)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := readPEM(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate or key: %w", err)
+	}
+	return cert, nil
+}
+
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	content, err := os.ReadFile(value)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return content, nil
+}
+
+// DefaultTokenCacheDir returns the directory of the token cache in the user's cache directory
+func DefaultTokenCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "flashpipe")
+}
+
+// SetTokenCache stores the OAuth 2.0 token encrypted in dir, so that it is reused by subsequent
+// invocations with the same credentials until it expires
+func (e *HTTPExecuter) SetTokenCache(dir string) {
+	if e.tokenSource == nil {
+		return
+	}
+	// The encryption key is derived from the secret, so there is nothing to protect the token without it
+	if e.tokenSecret == "" {
+		log.Debug().Msg("OAuth token not cached as no client secret or private key is available")
+		return
+	}
+	id := sha256.Sum256([]byte(e.tokenCacheId))
+	// The token can only be decrypted with the credentials used to get it
+	key := sha256.Sum256([]byte(e.tokenCacheId + "\x00" + e.tokenSecret))
+	cache := &tokenCache{
+		base: e.tokenSource,
+		file: filepath.Join(dir, hex.EncodeToString(id[:])+".token"),
+		key:  key[:],
+	}
+	e.httpClient = oauth2.NewClient(e.tokenCtx, oauth2.ReuseTokenSource(nil, cache))
+}
+
+// tokenCache gets the token from the cache file, or from the token server when there is no valid cached token
+type tokenCache struct {
+	base oauth2.TokenSource
+	file string
+	key  []byte
+}
+
+func (c *tokenCache) Token() (*oauth2.Token, error) {
+	token, err := c.load()
+	if err != nil {
+		log.Debug().Msgf("Cached OAuth token not used - %v", err)
+	} else if token != nil {
+		log.Debug().Msgf("Using cached OAuth token from %v", c.file)
+		return token, nil
+	}
+
+	token, err = c.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	// The token is still valid for this invocation when it cannot be cached
+	if err = c.save(token); err != nil {
+		log.Warn().Msgf("OAuth token could not be cached - %v", err)
+	}
+	return token, nil
+}
+
+func (c *tokenCache) load() (*oauth2.Token, error) {
+	content, err := os.ReadFile(c.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	plain, err := c.decrypt(content)
+	if err != nil {
+		return nil, err
+	}
+	token := new(oauth2.Token)
+	err = json.Unmarshal(plain, token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Expiry.Before(time.Now().Add(tokenExpiryMargin)) {
+		return nil, nil
+	}
+	return token, nil
+}
+
+func (c *tokenCache) save(token *oauth2.Token) error {
+	// Tokens without expiry cannot be checked for validity when reused
+	if token.Expiry.IsZero() {
+		return nil
+	}
+	plain, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	content, err := c.encrypt(plain)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(c.file), 0700)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent invocations never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(c.file), "token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file)
+}
+
+func (c *tokenCache) encrypt(plain []byte) ([]byte, error) {
+	gcm, err := c.newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (c *tokenCache) decrypt(content []byte) ([]byte, error) {
+	gcm, err := c.newGCM()
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid content of token cache %v", c.file)
+	}
+	nonce, sealed := content[:gcm.NonceSize()], content[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func (c *tokenCache) newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,139 @@
+package httpclnt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer returns a server that counts the token requests
+func newTokenServer() (*httptest.Server, *atomic.Int32) {
+	var tokenCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{ "access_token": "token123", "token_type": "bearer", "expires_in": 3600 }`))
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token123" {
+			http.Error(w, "Invalid token for endpoint authorization", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	return httptest.NewServer(mux), &tokenCalls
+}
+
+func TestTokenCache(t *testing.T) {
+	svr, tokenCalls := newTokenServer()
+	defer svr.Close()
+	host, port := GetHostPort(svr.URL)
+	cacheDir := t.TempDir()
+
+	// Consecutive executers with the same credentials share the cached token
+	for i := 0; i < 2; i++ {
+		exe := New(host, "/oauth/token", "dummyid", "dummysecret", "", "", host, "http", port, true)
+		exe.SetTokenCache(cacheDir)
+		resp, err := exe.ExecGetRequest("/api/v1/", nil)
+		if err != nil {
+			t.Fatalf("HTTP call failed with error - %v", err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "HTTP call failed")
+	}
+	assert.Equal(t, int32(1), tokenCalls.Load(), "Cached token not reused")
+
+	// The cached token cannot be decrypted with different credentials
+	exe := New(host, "/oauth/token", "dummyid", "othersecret", "", "", host, "http", port, true)
+	exe.SetTokenCache(cacheDir)
+	_, err := exe.ExecGetRequest("/api/v1/", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	assert.Equal(t, int32(2), tokenCalls.Load(), "Cached token reused with different credentials")
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("ReadDir failed with error - %v", err)
+	}
+	assert.Len(t, entries, 1, "Incorrect number of cached tokens")
+	content, err := os.ReadFile(cacheDir + "/" + entries[0].Name())
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	assert.NotContains(t, string(content), "token123", "Cached token not encrypted")
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	certPEM, keyPEM := generateTestCertificate(t)
+	_, err := LoadClientCertificate(string(certPEM), string(keyPEM))
+	if err != nil {
+		t.Fatalf("LoadClientCertificate from PEM contents failed with error - %v", err)
+	}
+
+	dir := t.TempDir()
+	err = os.WriteFile(dir+"/cert.pem", certPEM, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	err = os.WriteFile(dir+"/key.pem", keyPEM, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	_, err = LoadClientCertificate(dir+"/cert.pem", dir+"/key.pem")
+	if err != nil {
+		t.Fatalf("LoadClientCertificate from PEM files failed with error - %v", err)
+	}
+
+	_, err = LoadClientCertificate(string(certPEM), string(certPEM))
+	assert.Error(t, err, "Certificate accepted as private key")
+}
+
+func generateTestCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed with error - %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed with error - %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed with error - %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestTokenCache_ClientCertKeyContent(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM := generateTestCertificate(t)
+	err := os.WriteFile(dir+"/key.pem", keyPEM, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+
+	// The cache is encrypted with the content of the private key, so that a replaced key at the same path does not reuse the token
+	exe := NewWithClientCert("localhost", "/oauth/token", "dummyid", string(certPEM), dir+"/key.pem", "localhost", "https", 443, false)
+	assert.Equal(t, string(keyPEM), exe.tokenSecret, "Token cache not encrypted with private key content")
+
+	exe = NewWithClientCert("localhost", "/oauth/token", "dummyid", string(certPEM), dir+"/missing.pem", "localhost", "https", 443, false)
+	client := exe.httpClient
+	exe.SetTokenCache(dir)
+	assert.Same(t, client, exe.httpClient, "Token cached without private key")
+}
@@ -0,0 +1,202 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/beevik/etree"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"github.com/magiconair/properties"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	parametersFile = "src/main/resources/parameters.prop"
+	resourcesDir   = "src/main/resources/"
+)
+
+var (
+	// Resources referenced by path relative to src/main/resources, e.g. /xsd/Order.xsd or mapping/Order.mmap
+	resourcePathPattern = regexp.MustCompile(`^/?(script|mapping|xsd|wsdl|edmx|json|xslt?)/[^\s,;]+\.\w+$`)
+)
+
+// checkIFlow checks the BPMN2 files of the IFlow and the resources and parameters they reference
+func (l *linter) checkIFlow() error {
+	dir := filepath.Join(l.artifactDir, file.BPMNDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, 0)
+	}
+	var iflowFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".iflw") {
+			iflowFiles = append(iflowFiles, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(iflowFiles) == 0 {
+		l.add(RuleIFlowXML, SeverityError, dir, 0, "no IFlow BPMN2 file (.iflw) found")
+		return nil
+	}
+
+	parameters, err := l.loadParameters()
+	if err != nil {
+		return err
+	}
+	for _, iflowFile := range iflowFiles {
+		if err = l.checkBPMN(iflowFile, parameters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadParameters returns the keys of parameters.prop. It returns nil if the file is invalid, so that the
+// externalised parameters are not checked against it
+func (l *linter) loadParameters() (map[string]bool, error) {
+	path := filepath.Join(l.artifactDir, parametersFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, errors.Wrap(err, 0)
+	}
+	props, err := properties.Load(content, properties.UTF8)
+	if err != nil {
+		l.add(RuleParametersInvalid, SeverityError, path, 0, "invalid properties file: %v", err)
+		return nil, nil
+	}
+	keys := map[string]bool{}
+	for _, key := range props.Keys() {
+		keys[key] = true
+	}
+	return keys, nil
+}
+
+func (l *linter) checkBPMN(iflowFile string, parameters map[string]bool) error {
+	content, err := os.ReadFile(iflowFile)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	// etree does not return the line of syntax errors, so the XML is checked with the decoder first
+	if line, err := checkWellFormed(content); err != nil {
+		l.add(RuleIFlowXML, SeverityError, iflowFile, line, "invalid XML: %v", err)
+		return nil
+	}
+	doc := etree.NewDocument()
+	if err = doc.ReadFromBytes(content); err != nil {
+		l.add(RuleIFlowXML, SeverityError, iflowFile, 0, "invalid XML: %v", err)
+		return nil
+	}
+	if doc.Root() == nil {
+		l.add(RuleIFlowXML, SeverityError, iflowFile, 0, "no root element")
+		return nil
+	}
+
+	if parameters != nil {
+		reported := map[string]bool{}
+		for _, match := range file.ExternalParameterPattern.FindAllSubmatchIndex(content, -1) {
+			name := string(content[match[2]:match[3]])
+			if parameters[name] || reported[name] {
+				continue
+			}
+			reported[name] = true
+			l.add(RuleIFlowParameter, SeverityError, iflowFile, lineAt(content, match[0]), "externalised parameter %v is not in %v", name, parametersFile)
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, property := range doc.FindElements("//ifl:property") {
+		key := property.SelectElement("key")
+		value := property.SelectElement("value")
+		if key == nil || value == nil {
+			continue
+		}
+		path := resourcePath(key.Text(), value.Text(), hasScriptBundle(property))
+		if path == "" || reported[path] {
+			continue
+		}
+		if _, err = os.Stat(filepath.Join(l.artifactDir, filepath.FromSlash(path))); err == nil {
+			continue
+		}
+		reported[path] = true
+		line := 0
+		if index := bytes.Index(content, []byte(value.Text())); index >= 0 {
+			line = lineAt(content, index)
+		}
+		l.add(RuleIFlowResource, SeverityError, iflowFile, line, "%v referenced by %v does not exist", path, key.Text())
+	}
+	return nil
+}
+
+// checkWellFormed returns the syntax error of the XML content and its line
+func checkWellFormed(content []byte) (int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			line := 0
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line = syntaxErr.Line
+			}
+			return line, err
+		}
+	}
+}
+
+// hasScriptBundle checks if the script of the step is from a script collection instead of the IFlow
+func hasScriptBundle(property *etree.Element) bool {
+	parent := property.Parent()
+	if parent == nil {
+		return false
+	}
+	for _, sibling := range parent.SelectElements(property.Tag) {
+		key := sibling.SelectElement("key")
+		value := sibling.SelectElement("value")
+		if key != nil && value != nil && key.Text() == "scriptBundleId" && strings.TrimSpace(value.Text()) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resourcePath returns the path of the resource referenced by the property, relative to the artifact directory,
+// or an empty string if the property does not reference a resource of the artifact
+func resourcePath(key string, value string, scriptBundle bool) string {
+	value = strings.TrimSpace(value)
+	if value == "" || file.ExternalParameterPattern.MatchString(value) || strings.Contains(value, "${") {
+		return ""
+	}
+	if key == "script" {
+		if scriptBundle {
+			return ""
+		}
+		return resourcesDir + "script/" + value
+	}
+	// e.g. dir://mmap/src/main/resources/mapping/Order.mmap
+	if rest, found := strings.CutPrefix(value, "dir://"); found {
+		if _, path, found := strings.Cut(rest, "/"); found && strings.HasPrefix(path, resourcesDir) {
+			return path
+		}
+		return ""
+	}
+	if strings.HasPrefix(value, resourcesDir) && filepath.Ext(value) != "" {
+		return value
+	}
+	if resourcePathPattern.MatchString(value) {
+		return resourcesDir + strings.TrimPrefix(value, "/")
+	}
+	return ""
+}
+
+func lineAt(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,112 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/file"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rule is a check of the artifact contents
+type Rule struct {
+	Id          string
+	Description string
+}
+
+var (
+	RuleManifestMissing   = &Rule{"manifest-missing", "META-INF/MANIFEST.MF must exist"}
+	RuleManifestFormat    = &Rule{"manifest-format", "MANIFEST.MF must have valid headers and line wrapping"}
+	RuleManifestId        = &Rule{"manifest-symbolic-name", "Bundle-SymbolicName must be a valid ID matching the directory"}
+	RuleManifestType      = &Rule{"manifest-bundle-type", "SAP-BundleType must be set to a known artifact type"}
+	RuleIFlowXML          = &Rule{"iflow-well-formed", "IFlow BPMN2 files must be well-formed XML"}
+	RuleIFlowResource     = &Rule{"iflow-resource-missing", "Scripts, mappings and schemas referenced by the IFlow must exist"}
+	RuleIFlowParameter    = &Rule{"iflow-parameter-missing", "Externalised parameters of the IFlow must be in parameters.prop"}
+	RuleParametersInvalid = &Rule{"parameters-invalid", "parameters.prop must be a valid properties file"}
+)
+
+// Rules are all rules in the order they are documented
+var Rules = []*Rule{RuleManifestMissing, RuleManifestFormat, RuleManifestId, RuleManifestType, RuleIFlowXML, RuleIFlowResource, RuleIFlowParameter, RuleParametersInvalid}
+
+// Finding is a violation of a rule in a file of an artifact. Line is 0 if the finding applies to the whole file
+type Finding struct {
+	RuleId   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// Result are the findings of the linted artifacts
+type Result struct {
+	Artifacts []string   `json:"artifacts"`
+	Findings  []*Finding `json:"findings"`
+}
+
+// Errors returns the number of findings with severity error
+func (r *Result) Errors() int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+type linter struct {
+	artifactDir string
+	findings    []*Finding
+}
+
+func (l *linter) add(rule *Rule, severity string, file string, line int, format string, args ...any) {
+	l.findings = append(l.findings, &Finding{RuleId: rule.Id, Severity: severity, Message: fmt.Sprintf(format, args...), File: file, Line: line})
+}
+
+// Run lints the artifacts in the base directory or below it. A directory is an artifact if it contains
+// META-INF/MANIFEST.MF or src/main/resources
+func Run(baseDir string) (*Result, error) {
+	// Directories with resources but without MANIFEST.MF are also checked, so that the missing manifest is reported
+	artifactDirs, err := file.FindDirs(baseDir, func(dir string) bool {
+		return file.IsArtifactDir(dir) || file.Exists(filepath.Join(dir, "src", "main", "resources"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Artifacts: []string{}, Findings: []*Finding{}}
+	for _, artifactDir := range artifactDirs {
+		findings, err := Artifact(artifactDir)
+		if err != nil {
+			return nil, err
+		}
+		result.Artifacts = append(result.Artifacts, artifactDir)
+		result.Findings = append(result.Findings, findings...)
+	}
+	return result, nil
+}
+
+// Artifact lints the contents of the artifact directory
+func Artifact(artifactDir string) ([]*Finding, error) {
+	l := &linter{artifactDir: artifactDir}
+	bundleType, err := l.checkManifest()
+	if err != nil {
+		return nil, err
+	}
+	// Without MANIFEST.MF, the IFlow is still checked if there is a BPMN2 directory
+	if bundleType == "IntegrationFlow" || (bundleType == "" && file.Exists(filepath.Join(artifactDir, file.BPMNDir))) {
+		if err = l.checkIFlow(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		if l.findings[i].File != l.findings[j].File {
+			return l.findings[i].File < l.findings[j].File
+		}
+		return l.findings[i].Line < l.findings[j].Line
+	})
+	return l.findings, nil
+}
@@ -0,0 +1,159 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validIFlow = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd">
+  <bpmn2:process id="Process_1">
+    <bpmn2:callActivity id="CallActivity_1">
+      <bpmn2:extensionElements>
+        <ifl:property><key>script</key><value>Transform.groovy</value></ifl:property>
+        <ifl:property><key>scriptBundleId</key><value/></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:callActivity>
+    <bpmn2:callActivity id="CallActivity_2">
+      <bpmn2:extensionElements>
+        <ifl:property><key>script</key><value>Shared.groovy</value></ifl:property>
+        <ifl:property><key>scriptBundleId</key><value>ScriptCollection1</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:callActivity>
+    <bpmn2:callActivity id="CallActivity_3">
+      <bpmn2:extensionElements>
+        <ifl:property><key>mappinguri</key><value>dir://mmap/src/main/resources/mapping/Order.mmap</value></ifl:property>
+        <ifl:property><key>xsdFile</key><value>/xsd/Order.xsd</value></ifl:property>
+        <ifl:property><key>address</key><value>{{Receiver_Address}}</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:callActivity>
+  </bpmn2:process>
+</bpmn2:definitions>
+`
+
+func writeFiles(t *testing.T, baseDir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(baseDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+}
+
+func validArtifact() map[string]string {
+	return map[string]string{
+		"IFlow1/META-INF/MANIFEST.MF":                                         "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow1; singleton:=true\nBundle-Name: Order \n Replication\nSAP-BundleType: IntegrationFlow\n\n",
+		"IFlow1/src/main/resources/scenarioflows/integrationflow/IFlow1.iflw": validIFlow,
+		"IFlow1/src/main/resources/script/Transform.groovy":                   "",
+		"IFlow1/src/main/resources/mapping/Order.mmap":                        "",
+		"IFlow1/src/main/resources/xsd/Order.xsd":                             "",
+		"IFlow1/src/main/resources/parameters.prop":                           "Receiver_Address=https\\://example.com\n",
+	}
+}
+
+func ruleIds(findings []*Finding) []string {
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.RuleId)
+	}
+	return ids
+}
+
+func TestArtifact_Valid(t *testing.T) {
+	baseDir := t.TempDir()
+	writeFiles(t, baseDir, validArtifact())
+
+	findings, err := Artifact(filepath.Join(baseDir, "IFlow1"))
+	if err != nil {
+		t.Fatalf("Artifact failed with error - %v", err)
+	}
+	assert.Empty(t, findings, "Valid artifact has findings")
+}
+
+func TestArtifact_Manifest(t *testing.T) {
+	baseDir := t.TempDir()
+	files := validArtifact()
+	files["IFlow1/META-INF/MANIFEST.MF"] = "Manifest-Version: 1.0\nBundle-SymbolicName: IFlow2\nBundle-Description: " + strings.Repeat("x", 60) + "\ninvalid\nSAP-BundleType: Unknown"
+	writeFiles(t, baseDir, files)
+
+	findings, err := Artifact(filepath.Join(baseDir, "IFlow1"))
+	if err != nil {
+		t.Fatalf("Artifact failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"manifest-symbolic-name", "manifest-format", "manifest-format", "manifest-format", "manifest-bundle-type"}, ruleIds(findings), "Incorrect findings")
+	assert.Equal(t, 2, findings[0].Line, "Incorrect line of Bundle-SymbolicName")
+	assert.Equal(t, SeverityWarning, findings[4].Severity, "Unknown bundle type is not a warning")
+	assert.Contains(t, findings[3].Message, "line break", "Missing line break not detected")
+}
+
+func TestArtifact_IFlow(t *testing.T) {
+	baseDir := t.TempDir()
+	files := validArtifact()
+	delete(files, "IFlow1/src/main/resources/script/Transform.groovy")
+	delete(files, "IFlow1/src/main/resources/xsd/Order.xsd")
+	files["IFlow1/src/main/resources/parameters.prop"] = "Other=value\n"
+	writeFiles(t, baseDir, files)
+
+	findings, err := Artifact(filepath.Join(baseDir, "IFlow1"))
+	if err != nil {
+		t.Fatalf("Artifact failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"iflow-resource-missing", "iflow-resource-missing", "iflow-parameter-missing"}, ruleIds(findings), "Incorrect findings")
+	assert.Equal(t, "src/main/resources/script/Transform.groovy referenced by script does not exist", findings[0].Message, "Incorrect message")
+	assert.Equal(t, 6, findings[0].Line, "Incorrect line of script")
+	assert.Equal(t, 20, findings[2].Line, "Incorrect line of parameter")
+}
+
+func TestArtifact_InvalidXML(t *testing.T) {
+	baseDir := t.TempDir()
+	files := validArtifact()
+	files["IFlow1/src/main/resources/scenarioflows/integrationflow/IFlow1.iflw"] = "<bpmn2:definitions>\n  <bpmn2:process>\n</bpmn2:definitions>\n"
+	files["IFlow1/src/main/resources/parameters.prop"] = "Key=\\u00"
+	writeFiles(t, baseDir, files)
+
+	findings, err := Artifact(filepath.Join(baseDir, "IFlow1"))
+	if err != nil {
+		t.Fatalf("Artifact failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"parameters-invalid", "iflow-well-formed"}, ruleIds(findings), "Incorrect findings")
+	assert.Equal(t, 3, findings[1].Line, "Incorrect line of XML error")
+}
+
+func TestRun_SARIF(t *testing.T) {
+	baseDir := t.TempDir()
+	files := validArtifact()
+	files["Package1/IFlow2/src/main/resources/scenarioflows/integrationflow/IFlow2.iflw"] = validIFlow
+	writeFiles(t, baseDir, files)
+
+	result, err := Run(baseDir)
+	if err != nil {
+		t.Fatalf("Run failed with error - %v", err)
+	}
+	assert.Len(t, result.Artifacts, 2, "Artifacts not found")
+	assert.Equal(t, 5, result.Errors(), "Incorrect number of errors")
+
+	var buf bytes.Buffer
+	if err = WriteSARIF(&buf, result, "1.0.0"); err != nil {
+		t.Fatalf("WriteSARIF failed with error - %v", err)
+	}
+	var sarif map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatalf("Unmarshal failed with error - %v", err)
+	}
+	assert.Equal(t, "2.1.0", sarif["version"], "Incorrect SARIF version")
+	run := sarif["runs"].([]any)[0].(map[string]any)
+	assert.Len(t, run["tool"].(map[string]any)["driver"].(map[string]any)["rules"], len(Rules), "Rules not included")
+	results := run["results"].([]any)
+	assert.Len(t, results, 5, "Incorrect number of results")
+	location := results[0].(map[string]any)["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+	assert.True(t, strings.HasSuffix(location["artifactLocation"].(map[string]any)["uri"].(string), "Package1/IFlow2/META-INF/MANIFEST.MF"), "Incorrect file")
+	assert.Nil(t, location["region"], "Region without line")
+}
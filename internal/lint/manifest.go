@@ -0,0 +1,96 @@
+package lint
+
+import (
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var artifactIdPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+var bundleTypes = []string{"IntegrationFlow", "MessageMapping", "ScriptCollection", "ValueMapping"}
+
+// checkManifest checks MANIFEST.MF and returns its SAP-BundleType
+func (l *linter) checkManifest() (string, error) {
+	manifestPath := filepath.Join(l.artifactDir, "META-INF", "MANIFEST.MF")
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			l.add(RuleManifestMissing, SeverityError, manifestPath, 0, "META-INF/MANIFEST.MF not found")
+			return "", nil
+		}
+		return "", errors.Wrap(err, 0)
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		l.add(RuleManifestFormat, SeverityError, manifestPath, strings.Count(text, "\n")+1, "last line does not end with a line break and is ignored")
+	}
+
+	// Only the headers of the main section, up to the first blank line, are collected
+	headers := map[string]string{}
+	headerLines := map[string]int{}
+	mainSection := true
+	current := ""
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		lineNo := i + 1
+		if len(line) > file.ManifestLineWidth {
+			l.add(RuleManifestFormat, SeverityError, manifestPath, lineNo, "line is longer than %d bytes and has to be wrapped with continuation lines starting with a space", file.ManifestLineWidth)
+		}
+		switch {
+		case line == "":
+			mainSection = false
+			current = ""
+		case strings.HasPrefix(line, " "):
+			if current == "" {
+				l.add(RuleManifestFormat, SeverityError, manifestPath, lineNo, "continuation line without header")
+			} else if mainSection {
+				headers[current] += line[1:]
+			}
+		default:
+			name, value, found := strings.Cut(line, ":")
+			if !found || name == "" || strings.ContainsAny(name, " \t") || !strings.HasPrefix(value, " ") {
+				l.add(RuleManifestFormat, SeverityError, manifestPath, lineNo, "invalid header %q, expected <name>: <value>", line)
+				current = ""
+				continue
+			}
+			current = name
+			if !mainSection {
+				continue
+			}
+			if _, duplicate := headers[name]; duplicate {
+				l.add(RuleManifestFormat, SeverityWarning, manifestPath, lineNo, "duplicate header %v", name)
+			}
+			headers[name] = value[1:]
+			headerLines[name] = lineNo
+		}
+	}
+
+	symbolicName, found := headers["Bundle-SymbolicName"]
+	if !found {
+		l.add(RuleManifestId, SeverityError, manifestPath, 0, "Bundle-SymbolicName is missing")
+	} else {
+		id, _, _ := strings.Cut(symbolicName, ";")
+		id = strings.TrimSpace(id)
+		dirName := filepath.Base(l.artifactDir)
+		switch {
+		case !artifactIdPattern.MatchString(id):
+			l.add(RuleManifestId, SeverityError, manifestPath, headerLines["Bundle-SymbolicName"], "Bundle-SymbolicName %q is not a valid ID, only letters, digits, '_', '-' and '.' are allowed", id)
+		case dirName != id && dirName != strings.TrimSpace(headers["Bundle-Name"]):
+			// Directories are named after the ID or the name of the artifact, see --dir-naming-type of sync
+			l.add(RuleManifestId, SeverityError, manifestPath, headerLines["Bundle-SymbolicName"], "Bundle-SymbolicName %v does not match the directory %v", id, dirName)
+		}
+	}
+
+	bundleType := strings.TrimSpace(headers["SAP-BundleType"])
+	switch {
+	case bundleType == "":
+		l.add(RuleManifestType, SeverityError, manifestPath, 0, "SAP-BundleType is missing")
+	case !slices.Contains(bundleTypes, bundleType):
+		l.add(RuleManifestType, SeverityWarning, manifestPath, headerLines["SAP-BundleType"], "unknown SAP-BundleType %v, expected one of %v", bundleType, strings.Join(bundleTypes, ", "))
+	}
+	return bundleType, nil
+}
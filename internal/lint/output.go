@@ -0,0 +1,130 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-errors/errors"
+	"io"
+	"path/filepath"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// WriteText writes one line per finding, in the format file:line: severity: message [rule]
+func WriteText(w io.Writer, result *Result) error {
+	for _, f := range result.Findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%v:%d", f.File, f.Line)
+		}
+		if _, err := fmt.Fprintf(w, "%v: %v: %v [%v]\n", location, f.Severity, f.Message, f.RuleId); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the result as JSON
+func WriteJSON(w io.Writer, result *Result) error {
+	return writeJSON(w, result)
+}
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationUri string       `json:"informationUri"`
+	Version        string       `json:"version"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the result in SARIF 2.1.0 format, e.g. for code scanning of GitHub. File paths are
+// relative to the working directory where possible
+func WriteSARIF(w io.Writer, result *Result, toolVersion string) error {
+	driver := &sarifDriver{Name: "flashpipe", InformationUri: "https://github.com/engswee/flashpipe", Version: toolVersion}
+	for _, rule := range Rules {
+		driver.Rules = append(driver.Rules, &sarifRule{Id: rule.Id, ShortDescription: &sarifMessage{Text: rule.Description}})
+	}
+	run := &sarifRun{Tool: &sarifTool{Driver: driver}, Results: []*sarifResult{}}
+	for _, f := range result.Findings {
+		location := &sarifPhysicalLocation{ArtifactLocation: &sarifArtifactLocation{Uri: sarifUri(f.File)}}
+		if f.Line > 0 {
+			location.Region = &sarifRegion{StartLine: f.Line}
+		}
+		run.Results = append(run.Results, &sarifResult{
+			RuleId:    f.RuleId,
+			Level:     f.Severity,
+			Message:   &sarifMessage{Text: f.Message},
+			Locations: []*sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+	return writeJSON(w, &sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []*sarifRun{run}})
+}
+
+func sarifUri(file string) string {
+	if filepath.IsAbs(file) {
+		if abs, err := filepath.Abs("."); err == nil {
+			if rel, err := filepath.Rel(abs, file); err == nil && filepath.IsLocal(rel) {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+func writeJSON(w io.Writer, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if _, err = fmt.Fprintf(w, "%s\n", content); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package mock
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var apiProxyPattern = regexp.MustCompile(`^Management\.svc/APIProxies\('([^']*)'\)$`)
+
+func (s *Server) serveAPIM(w http.ResponseWriter, r *http.Request, urlPath string) {
+	var m []string
+	switch {
+	case urlPath == "ContentArchive.svc" && r.Method == http.MethodGet:
+		s.downloadAPIProxy(w, r)
+	case urlPath == "ContentArchive.svc" && r.Method == http.MethodPost:
+		s.uploadAPIProxy(w, r)
+	case urlPath == "Management.svc/APIProxies" && r.Method == http.MethodGet:
+		s.listAPIProxies(w, r)
+	case func() bool { m = apiProxyPattern.FindStringSubmatch(urlPath); return m != nil }():
+		if s.proxies[m[1]] == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("APIProxy %v not found", m[1]))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]any{"d": apiProxyMetadata(m[1])})
+		case http.MethodDelete:
+			delete(s.proxies, m[1])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %v not found", r.URL.Path))
+	}
+}
+
+// downloadAPIProxy returns the content archive of the API proxy selected in the request body
+func (s *Server) downloadAPIProxy(w http.ResponseWriter, r *http.Request) {
+	var query struct {
+		Selection struct {
+			Proxies struct {
+				Entities []struct {
+					Name string `json:"name"`
+				} `json:"entities"`
+			} `json:"apiproxies"`
+		} `json:"selection"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil || len(query.Selection.Proxies.Entities) == 0 {
+		writeError(w, http.StatusBadRequest, "APIProxy selection is required")
+		return
+	}
+	name := query.Selection.Proxies.Entities[0].Name
+	content := s.proxies[name]
+	if content == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("APIProxy %v not found", name))
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Write(content)
+}
+
+// uploadAPIProxy stores the content archive of the multipart file under the name of the API proxy in the archive
+func (s *Server) uploadAPIProxy(w http.ResponseWriter, r *http.Request) {
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Multipart file is required: %v", err))
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid content archive: %v", err))
+		return
+	}
+	name := strings.TrimSuffix(header.Filename, path.Ext(header.Filename))
+	for _, zf := range reader.File {
+		if proxy, found := strings.CutPrefix(zf.Name, "APIProxies/"); found && proxy != "" {
+			name = strings.SplitN(proxy, "/", 2)[0]
+			break
+		}
+	}
+	s.proxies[name] = content
+	writeJSON(w, http.StatusOK, map[string]any{"d": apiProxyMetadata(name)})
+}
+
+// listAPIProxies returns the API proxies with client-driven paging with $top and $skip
+func (s *Server) listAPIProxies(w http.ResponseWriter, r *http.Request) {
+	names := sortedKeys(s.proxies)
+	skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+	if skip > len(names) {
+		skip = len(names)
+	}
+	names = names[skip:]
+	if top, err := strconv.Atoi(r.URL.Query().Get("$top")); err == nil && top < len(names) {
+		names = names[:top]
+	}
+	results := []any{}
+	for _, name := range names {
+		results = append(results, apiProxyMetadata(name))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"d": map[string]any{"results": results}})
+}
+
+func apiProxyMetadata(name string) map[string]string {
+	return map[string]string{"name": name, "version": "1", "state": "DEPLOYED"}
+}
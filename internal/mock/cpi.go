@@ -0,0 +1,517 @@
+package mock
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/magiconair/properties"
+	"io"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var artifactTypes = []string{"Integration", "MessageMapping", "ScriptCollection", "ValueMapping"}
+
+var (
+	packagesPattern         = regexp.MustCompile(`^IntegrationPackages$`)
+	packagePattern          = regexp.MustCompile(`^IntegrationPackages\('([^']*)'\)$`)
+	packageArtifactsPattern = regexp.MustCompile(`^IntegrationPackages\('([^']*)'\)/(\w+)DesigntimeArtifacts$`)
+	artifactsPattern        = regexp.MustCompile(`^(\w+)DesigntimeArtifacts$`)
+	artifactPattern         = regexp.MustCompile(`^(\w+)DesigntimeArtifacts\(Id='([^']*)',Version='([^']*)'\)$`)
+	artifactContentPattern  = regexp.MustCompile(`^(\w+)DesigntimeArtifacts\(Id='([^']*)',Version='([^']*)'\)/\$value$`)
+	configurationsPattern   = regexp.MustCompile(`^IntegrationDesigntimeArtifacts\(Id='([^']*)',Version='([^']*)'\)/Configurations$`)
+	configurationPattern    = regexp.MustCompile(`^IntegrationDesigntimeArtifacts\(Id='([^']*)',Version='([^']*)'\)/\$links/Configurations\('([^']*)'\)$`)
+	deployPattern           = regexp.MustCompile(`^Deploy(\w+)DesigntimeArtifact$`)
+	runtimeArtifactsPattern = regexp.MustCompile(`^IntegrationRuntimeArtifacts$`)
+	runtimeArtifactPattern  = regexp.MustCompile(`^IntegrationRuntimeArtifacts\('([^']*)'\)$`)
+	runtimeErrorPattern     = regexp.MustCompile(`^IntegrationRuntimeArtifacts\('([^']*)'\)/ErrorInformation/\$value$`)
+)
+
+type artifact struct {
+	Id          string
+	Name        string
+	Type        string
+	PackageId   string
+	Version     string
+	Description string
+	content     []byte
+}
+
+type runtimeArtifact struct {
+	Id         string
+	Version    string
+	Name       string
+	Type       string
+	Status     string
+	DeployedOn string
+	errorInfo  string
+}
+
+type artifactBody struct {
+	Id              string `json:"Id"`
+	Name            string `json:"Name"`
+	PackageId       string `json:"PackageId"`
+	ArtifactContent string `json:"ArtifactContent"`
+}
+
+func (s *Server) serveCPI(w http.ResponseWriter, r *http.Request, path string) {
+	var m []string
+	match := func(pattern *regexp.Regexp) bool {
+		m = pattern.FindStringSubmatch(path)
+		return m != nil
+	}
+	if s.serveMessageLogs(w, r, path) || s.serveSecurityMaterial(w, r, path) {
+		return
+	}
+	switch {
+	case path == "":
+		w.WriteHeader(http.StatusOK)
+	case path == "ServiceEndpoints" && r.Method == http.MethodGet:
+		s.listServiceEndpoints(w, r)
+	case match(packagesPattern):
+		switch r.Method {
+		case http.MethodGet:
+			s.listPackages(w, r)
+		case http.MethodPost:
+			s.upsertPackage(w, r, "")
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case match(packagePattern):
+		s.servePackage(w, r, m[1])
+	case match(packageArtifactsPattern):
+		s.listPackageArtifacts(w, r, m[1], m[2])
+	case match(artifactsPattern) && r.Method == http.MethodPost:
+		s.createArtifact(w, r, m[1])
+	case match(artifactPattern):
+		s.serveArtifact(w, r, m[1], m[2], m[3])
+	case match(artifactContentPattern):
+		if a := s.getArtifact(w, m[1], m[2], m[3]); a != nil {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(a.content)
+		}
+	case match(configurationsPattern):
+		s.listConfigurations(w, r, m[1], m[2])
+	case match(configurationPattern) && r.Method == http.MethodPut:
+		s.updateConfiguration(w, r, m[1], m[2], m[3])
+	case match(deployPattern) && r.Method == http.MethodPost:
+		s.deploy(w, r, m[1])
+	case match(runtimeArtifactsPattern):
+		var results []any
+		for _, id := range sortedKeys(s.runtime) {
+			results = append(results, s.runtime[id])
+		}
+		s.writePage(w, r, results)
+	case match(runtimeArtifactPattern):
+		s.serveRuntimeArtifact(w, r, m[1])
+	case match(runtimeErrorPattern):
+		rt := s.runtime[m[1]]
+		if rt == nil || rt.errorInfo == "" {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No error information for %v", m[1]))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"message": map[string]string{"subsytemName": "Runtime"}, "parameter": []string{rt.errorInfo}})
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %v not found", path))
+	}
+}
+
+func (s *Server) listPackages(w http.ResponseWriter, r *http.Request) {
+	var results []any
+	for _, id := range sortedKeys(s.packages) {
+		results = append(results, s.packages[id])
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) servePackage(w http.ResponseWriter, r *http.Request, id string) {
+	data := s.packages[id]
+	if data == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Integration package %v not found", id))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"d": data})
+	case http.MethodPut:
+		s.upsertPackage(w, r, id)
+	case http.MethodDelete:
+		for artifactId, a := range s.artifacts {
+			if a.PackageId == id {
+				delete(s.artifacts, artifactId)
+			}
+		}
+		delete(s.packages, id)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// upsertPackage creates the package from the request, or updates the package with id
+func (s *Server) upsertPackage(w http.ResponseWriter, r *http.Request, id string) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The body is accepted with or without the d root
+	if root, ok := body["d"].(map[string]any); ok {
+		body = root
+	}
+	if id == "" {
+		id, _ = body["Id"].(string)
+		if id == "" {
+			writeError(w, http.StatusBadRequest, "Id of integration package is required")
+			return
+		}
+		if s.packages[id] != nil {
+			writeError(w, http.StatusConflict, fmt.Sprintf("Integration package %v already exists", id))
+			return
+		}
+		s.packages[id] = map[string]any{"Id": id, "Version": "1.0.0", "Mode": "EDIT_ALLOWED"}
+	}
+	for key, value := range body {
+		if key != "Id" && key != "Mode" && key != "__metadata" {
+			s.packages[id][key] = value
+		}
+	}
+	if r.Method == http.MethodPost {
+		writeJSON(w, http.StatusCreated, map[string]any{"d": s.packages[id]})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) listPackageArtifacts(w http.ResponseWriter, r *http.Request, packageId string, artifactType string) {
+	if s.packages[packageId] == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Integration package %v not found", packageId))
+		return
+	}
+	var results []any
+	for _, id := range sortedKeys(s.artifacts) {
+		if a := s.artifacts[id]; a.PackageId == packageId && a.Type == artifactType {
+			results = append(results, a)
+		}
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) createArtifact(w http.ResponseWriter, r *http.Request, artifactType string) {
+	body, content, ok := readArtifactBody(w, r)
+	if !ok {
+		return
+	}
+	if !slices.Contains(artifactTypes, artifactType) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Artifact type %v not supported", artifactType))
+		return
+	}
+	if s.packages[body.PackageId] == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Integration package %v not found", body.PackageId))
+		return
+	}
+	if s.artifacts[body.Id] != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Artifact %v already exists", body.Id))
+		return
+	}
+	a := &artifact{Id: body.Id, Name: body.Name, Type: artifactType, PackageId: body.PackageId}
+	if err := a.setContent(content); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.artifacts[a.Id] = a
+	writeJSON(w, http.StatusCreated, map[string]any{"d": a})
+}
+
+func (s *Server) serveArtifact(w http.ResponseWriter, r *http.Request, artifactType string, id string, version string) {
+	a := s.getArtifact(w, artifactType, id, version)
+	if a == nil {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"d": a})
+	case http.MethodPut:
+		_, content, ok := readArtifactBody(w, r)
+		if !ok {
+			return
+		}
+		if err := a.setContent(content); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"d": a})
+	case http.MethodDelete:
+		delete(s.artifacts, id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getArtifact returns the artifact of the type and version, or writes the not found error
+func (s *Server) getArtifact(w http.ResponseWriter, artifactType string, id string, version string) *artifact {
+	a := s.artifacts[id]
+	if a == nil || a.Type != artifactType || (!strings.EqualFold(version, "active") && version != a.Version) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%v designtime artifact %v with version %v not found", artifactType, id, version))
+		return nil
+	}
+	return a
+}
+
+func readArtifactBody(w http.ResponseWriter, r *http.Request) (*artifactBody, []byte, bool) {
+	body := new(artifactBody)
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, nil, false
+	}
+	content, err := base64.StdEncoding.DecodeString(body.ArtifactContent)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid ArtifactContent: %v", err))
+		return nil, nil, false
+	}
+	return body, content, true
+}
+
+// setContent sets the content (zip) of the artifact, with the version and name from MANIFEST.MF
+// and the description from metainfo.prop
+func (a *artifact) setContent(content []byte) error {
+	files, err := unzip(content)
+	if err != nil {
+		return fmt.Errorf("Invalid ArtifactContent: %w", err)
+	}
+	manifest, ok := files["META-INF/MANIFEST.MF"]
+	if !ok {
+		return fmt.Errorf("META-INF/MANIFEST.MF not found in ArtifactContent")
+	}
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(manifest, "\r\n\r\n"...))))
+	headers, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return fmt.Errorf("Invalid META-INF/MANIFEST.MF: %w", err)
+	}
+	a.Version = headers.Get("Bundle-Version")
+	if name := headers.Get("Bundle-Name"); name != "" {
+		a.Name = name
+	}
+	a.Description = ""
+	if metainfo, ok := files["metainfo.prop"]; ok {
+		a.Description = loadProperties(metainfo).GetString("description", "")
+	}
+	a.content = content
+	return nil
+}
+
+func (s *Server) listConfigurations(w http.ResponseWriter, r *http.Request, id string, version string) {
+	a := s.getArtifact(w, "Integration", id, version)
+	if a == nil {
+		return
+	}
+	params, err := a.parameters()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	types, err := a.parameterTypes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var results []any
+	for _, key := range params.Keys() {
+		dataType := types[key]
+		if dataType == "" {
+			dataType = "xsd:string"
+		}
+		results = append(results, map[string]string{"ParameterKey": key, "ParameterValue": params.GetString(key, ""), "DataType": dataType})
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) updateConfiguration(w http.ResponseWriter, r *http.Request, id string, version string, key string) {
+	a := s.getArtifact(w, "Integration", id, version)
+	if a == nil {
+		return
+	}
+	var body struct {
+		ParameterValue string `json:"ParameterValue"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params, err := a.parameters()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, ok := params.Get(key); !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Configuration parameter %v not found", key))
+		return
+	}
+	_, _, _ = params.Set(key, body.ParameterValue)
+	// Configured values are part of the downloaded content, as in the tenant
+	var buf bytes.Buffer
+	if _, err = params.Write(&buf, properties.UTF8); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	content, err := replaceZipFile(a.content, "src/main/resources/parameters.prop", buf.Bytes())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.content = content
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// parameters returns the configuration parameters from parameters.prop of the content
+func (a *artifact) parameters() (*properties.Properties, error) {
+	files, err := unzip(a.content)
+	if err != nil {
+		return nil, err
+	}
+	return loadProperties(files["src/main/resources/parameters.prop"]), nil
+}
+
+// parameterTypes returns the data types of the configuration parameters from parameters.propdef of the content
+func (a *artifact) parameterTypes() (map[string]string, error) {
+	files, err := unzip(a.content)
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]string{}
+	propdef, ok := files["src/main/resources/parameters.propdef"]
+	if !ok {
+		return types, nil
+	}
+	var definitions struct {
+		Parameters []struct {
+			Name string `xml:"name"`
+			Type string `xml:"type"`
+		} `xml:"parameter"`
+	}
+	if err = xml.Unmarshal(propdef, &definitions); err != nil {
+		return nil, fmt.Errorf("Invalid parameters.propdef: %w", err)
+	}
+	for _, parameter := range definitions.Parameters {
+		types[parameter.Name] = parameter.Type
+	}
+	return types, nil
+}
+
+func (s *Server) deploy(w http.ResponseWriter, r *http.Request, artifactType string) {
+	id := strings.Trim(r.URL.Query().Get("Id"), "'")
+	a := s.getArtifact(w, artifactType, id, strings.Trim(r.URL.Query().Get("Version"), "'"))
+	if a == nil {
+		return
+	}
+	rt := &runtimeArtifact{Id: a.Id, Version: a.Version, Name: a.Name, Type: runtimeType(a.Type), Status: "STARTED", DeployedOn: time.Now().UTC().Format(time.RFC3339)}
+	if message, failed := s.deployErrors[id]; failed {
+		rt.Status = "ERROR"
+		rt.errorInfo = message
+	}
+	s.runtime[id] = rt
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(newToken()))
+}
+
+func (s *Server) serveRuntimeArtifact(w http.ResponseWriter, r *http.Request, id string) {
+	rt := s.runtime[id]
+	if rt == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Runtime artifact %v not found", id))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"d": rt})
+	case http.MethodDelete:
+		delete(s.runtime, id)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// runtimeType returns the type of the runtime artifact for the designtime artifact type
+func runtimeType(artifactType string) string {
+	switch artifactType {
+	case "Integration":
+		return "INTEGRATION_FLOW"
+	case "MessageMapping":
+		return "MESSAGE_MAPPING"
+	case "ScriptCollection":
+		return "SCRIPT_COLLECTION"
+	default:
+		return "VALUE_MAPPING"
+	}
+}
+
+func loadProperties(content []byte) *properties.Properties {
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadBytes(content)
+	if err != nil {
+		props = properties.NewProperties()
+	}
+	props.DisableExpansion = true
+	return props
+}
+
+func unzip(content []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name], err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// replaceZipFile returns the zip content with the file replaced or added
+func replaceZipFile(content []byte, name string, fileContent []byte) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, f := range reader.File {
+		if f.Name == name {
+			continue
+		}
+		if err = writer.Copy(f); err != nil {
+			return nil, err
+		}
+	}
+	fw, err := writer.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fw.Write(fileContent); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
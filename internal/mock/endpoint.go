@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// serviceEndpoint is a runtime endpoint of a deployed IFlow, e.g. of a HTTPS sender adapter
+type serviceEndpoint struct {
+	iflowId string
+	address string
+	handler http.Handler
+}
+
+// AddServiceEndpoint adds the runtime endpoint of the IFlow at /http<address> of the server. Messages sent to
+// the endpoint are processed by the handler
+func (s *Server) AddServiceEndpoint(iflowId string, address string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = append(s.endpoints, &serviceEndpoint{iflowId: iflowId, address: address, handler: handler})
+}
+
+func (s *Server) listServiceEndpoints(w http.ResponseWriter, r *http.Request) {
+	var results []any
+	for _, endpoint := range s.endpoints {
+		results = append(results, map[string]any{
+			"Id":       fmt.Sprintf("%v$endpointAddress=%v", endpoint.iflowId, endpoint.address),
+			"Name":     endpoint.iflowId,
+			"Protocol": "REST",
+			"EntryPoints": map[string]any{"results": []any{
+				map[string]string{"Name": endpoint.iflowId, "Url": fmt.Sprintf("http://%v/http%v", r.Host, endpoint.address), "Type": "PROD"},
+			}},
+		})
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) serveEndpoint(w http.ResponseWriter, r *http.Request) {
+	address := strings.TrimPrefix(r.URL.Path, "/http")
+	for _, endpoint := range s.endpoints {
+		if endpoint.address == address {
+			endpoint.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("No endpoint for %v", address))
+}
@@ -0,0 +1,185 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	messageLogsPattern           = regexp.MustCompile(`^MessageProcessingLogs$`)
+	messageLogAttachmentsPattern = regexp.MustCompile(`^MessageProcessingLogs\('([^']*)'\)/Attachments$`)
+	messageLogErrorPattern       = regexp.MustCompile(`^MessageProcessingLogs\('([^']*)'\)/ErrorInformation/\$value$`)
+	filterConditionPattern       = regexp.MustCompile(`^(\w+) (eq|ne|ge|gt|le|lt) (?:datetime)?'(.*)'$`)
+)
+
+// MessageLog is a message processing log of the mock server
+type MessageLog struct {
+	MessageGuid            string
+	CorrelationId          string
+	IFlowId                string
+	Status                 string
+	LogStart               time.Time
+	LogEnd                 time.Time
+	ErrorInformation       string
+	Attachments            []string
+	CustomHeaderProperties map[string]string
+}
+
+// AddMessageLog adds the message processing log, e.g. for messages processed by a deployed IFlow
+func (s *Server) AddMessageLog(mpl *MessageLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messageLogs = append(s.messageLogs, mpl)
+}
+
+func (s *Server) serveMessageLogs(w http.ResponseWriter, r *http.Request, path string) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if messageLogsPattern.MatchString(path) {
+		s.listMessageLogs(w, r)
+		return true
+	}
+	if m := messageLogAttachmentsPattern.FindStringSubmatch(path); m != nil {
+		mpl := s.getMessageLog(w, m[1])
+		if mpl == nil {
+			return true
+		}
+		results := []any{}
+		for i, name := range mpl.Attachments {
+			results = append(results, map[string]any{"Id": fmt.Sprintf("%v-%d", mpl.MessageGuid, i), "Name": name, "ContentType": "text/plain", "PayloadSize": 0})
+		}
+		s.writePage(w, r, results)
+		return true
+	}
+	if m := messageLogErrorPattern.FindStringSubmatch(path); m != nil {
+		mpl := s.getMessageLog(w, m[1])
+		if mpl == nil {
+			return true
+		}
+		if mpl.ErrorInformation == "" {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No error information for message %v", mpl.MessageGuid))
+			return true
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(mpl.ErrorInformation))
+		return true
+	}
+	return false
+}
+
+func (s *Server) getMessageLog(w http.ResponseWriter, messageGuid string) *MessageLog {
+	for _, mpl := range s.messageLogs {
+		if mpl.MessageGuid == messageGuid {
+			return mpl
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Message processing log %v not found", messageGuid))
+	return nil
+}
+
+// listMessageLogs returns the logs matching the $filter, most recent first. Only filters joined with "and"
+// of conditions or parenthesised conditions joined with "or" are supported
+func (s *Server) listMessageLogs(w http.ResponseWriter, r *http.Request) {
+	var logs []*MessageLog
+	for _, mpl := range s.messageLogs {
+		matched, err := matchFilter(r.URL.Query().Get("$filter"), mpl)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if matched {
+			logs = append(logs, mpl)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].LogEnd.After(logs[j].LogEnd) })
+
+	var results []any
+	for _, mpl := range logs {
+		properties := []any{}
+		for _, name := range sortedKeys(mpl.CustomHeaderProperties) {
+			properties = append(properties, map[string]string{"Name": name, "Value": mpl.CustomHeaderProperties[name]})
+		}
+		results = append(results, map[string]any{
+			"MessageGuid":            mpl.MessageGuid,
+			"CorrelationId":          mpl.CorrelationId,
+			"IntegrationFlowName":    mpl.IFlowId,
+			"Status":                 mpl.Status,
+			"LogStart":               fmt.Sprintf("/Date(%d)/", mpl.LogStart.UnixMilli()),
+			"LogEnd":                 fmt.Sprintf("/Date(%d)/", mpl.LogEnd.UnixMilli()),
+			"CustomHeaderProperties": map[string]any{"results": properties},
+		})
+	}
+	s.writePage(w, r, results)
+}
+
+func matchFilter(filter string, mpl *MessageLog) (bool, error) {
+	if filter == "" {
+		return true, nil
+	}
+	for _, clause := range strings.Split(filter, " and ") {
+		clause = strings.TrimSuffix(strings.TrimPrefix(clause, "("), ")")
+		matched := false
+		for _, condition := range strings.Split(clause, " or ") {
+			ok, err := matchCondition(condition, mpl)
+			if err != nil {
+				return false, err
+			}
+			matched = matched || ok
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchCondition(condition string, mpl *MessageLog) (bool, error) {
+	m := filterConditionPattern.FindStringSubmatch(condition)
+	if m == nil {
+		return false, fmt.Errorf("unsupported filter condition %v", condition)
+	}
+	property, operator, value := m[1], m[2], strings.ReplaceAll(m[3], "''", "'")
+	var compared int
+	switch property {
+	case "IntegrationFlowName":
+		compared = strings.Compare(mpl.IFlowId, value)
+	case "Status":
+		compared = strings.Compare(mpl.Status, value)
+	case "CorrelationId":
+		compared = strings.Compare(mpl.CorrelationId, value)
+	case "MessageGuid":
+		compared = strings.Compare(mpl.MessageGuid, value)
+	case "LogStart", "LogEnd":
+		t, err := time.Parse("2006-01-02T15:04:05", value)
+		if err != nil {
+			return false, fmt.Errorf("invalid datetime %v", value)
+		}
+		logTime := mpl.LogEnd
+		if property == "LogStart" {
+			logTime = mpl.LogStart
+		}
+		// Datetimes of the filter have a precision of seconds
+		compared = logTime.Truncate(time.Second).Compare(t)
+	default:
+		return false, fmt.Errorf("unsupported filter property %v", property)
+	}
+	switch operator {
+	case "eq":
+		return compared == 0, nil
+	case "ne":
+		return compared != 0, nil
+	case "ge":
+		return compared >= 0, nil
+	case "gt":
+		return compared > 0, nil
+	case "le":
+		return compared <= 0, nil
+	default:
+		return compared < 0, nil
+	}
+}
@@ -0,0 +1,164 @@
+package mock
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	securityMaterialsPattern   = regexp.MustCompile(`^(UserCredentials|OAuth2ClientCredentials|SecureParameters)$`)
+	securityMaterialPattern    = regexp.MustCompile(`^(UserCredentials|OAuth2ClientCredentials|SecureParameters)\('([^']*)'\)$`)
+	keystoreEntriesPattern     = regexp.MustCompile(`^KeystoreEntries$`)
+	certificateResourcePattern = regexp.MustCompile(`^CertificateResources\('([0-9a-fA-F]*)'\)/\$value$`)
+)
+
+// Fields of the security material that are write-only and not returned by the API
+var secretFields = []string{"Password", "ClientSecret", "SecureParam"}
+
+// SecurityMaterial returns the fields of the user credential, OAuth2 client credential or secure parameter
+// (collection UserCredentials, OAuth2ClientCredentials or SecureParameters) including the secret values,
+// or nil if it does not exist
+func (s *Server) SecurityMaterial(collection string, name string) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.security[collection][name]
+}
+
+// AddKeystoreEntry adds the entry to the tenant keystore, e.g. a key pair of type KeyPair
+func (s *Server) AddKeystoreEntry(alias string, entryType string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keystore[alias] = &keystoreEntry{Alias: alias, Type: entryType, content: content}
+}
+
+// KeystoreEntry returns the content of the keystore entry, or nil if it does not exist
+func (s *Server) KeystoreEntry(alias string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.keystore[alias]; entry != nil {
+		return entry.content
+	}
+	return nil
+}
+
+type keystoreEntry struct {
+	Alias   string
+	Type    string
+	content []byte
+}
+
+func (s *Server) serveSecurityMaterial(w http.ResponseWriter, r *http.Request, path string) bool {
+	if m := securityMaterialsPattern.FindStringSubmatch(path); m != nil {
+		switch r.Method {
+		case http.MethodGet:
+			var results []any
+			for _, name := range sortedKeys(s.security[m[1]]) {
+				results = append(results, withoutSecrets(s.security[m[1]][name]))
+			}
+			s.writePage(w, r, results)
+		case http.MethodPost:
+			s.upsertSecurityMaterial(w, r, m[1], "")
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return true
+	}
+	if m := securityMaterialPattern.FindStringSubmatch(path); m != nil {
+		name := strings.ReplaceAll(m[2], "''", "'")
+		switch r.Method {
+		case http.MethodGet:
+			if material := s.security[m[1]][name]; material != nil {
+				writeJSON(w, http.StatusOK, map[string]any{"d": withoutSecrets(material)})
+			} else {
+				writeError(w, http.StatusNotFound, fmt.Sprintf("%v %v not found", m[1], name))
+			}
+		case http.MethodPut:
+			s.upsertSecurityMaterial(w, r, m[1], name)
+		case http.MethodDelete:
+			delete(s.security[m[1]], name)
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return true
+	}
+	if keystoreEntriesPattern.MatchString(path) && r.Method == http.MethodGet {
+		var results []any
+		for _, alias := range sortedKeys(s.keystore) {
+			entry := s.keystore[alias]
+			results = append(results, map[string]string{"Hexalias": hex.EncodeToString([]byte(alias)), "Alias": alias, "Type": entry.Type, "Owner": "Tenant Administrator"})
+		}
+		s.writePage(w, r, results)
+		return true
+	}
+	if m := certificateResourcePattern.FindStringSubmatch(path); m != nil && r.Method == http.MethodPost {
+		alias, err := hex.DecodeString(m[1])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid hex alias %v", m[1]))
+			return true
+		}
+		content, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(content), "BEGIN CERTIFICATE") {
+			writeError(w, http.StatusBadRequest, "Invalid certificate")
+			return true
+		}
+		exists := s.keystore[string(alias)] != nil
+		if exists && r.URL.Query().Get("update") != "true" {
+			writeError(w, http.StatusConflict, fmt.Sprintf("Keystore entry %s already exists", alias))
+			return true
+		}
+		s.keystore[string(alias)] = &keystoreEntry{Alias: string(alias), Type: "Certificate", content: content}
+		w.WriteHeader(http.StatusCreated)
+		return true
+	}
+	return false
+}
+
+// upsertSecurityMaterial creates the security material with POST, or updates the existing one of the name with PUT
+func (s *Server) upsertSecurityMaterial(w http.ResponseWriter, r *http.Request, collection string, name string) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.security[collection] == nil {
+		s.security[collection] = map[string]map[string]any{}
+	}
+	if r.Method == http.MethodPost {
+		name, _ = body["Name"].(string)
+		if name == "" {
+			writeError(w, http.StatusBadRequest, "Name is mandatory")
+			return
+		}
+		if s.security[collection][name] != nil {
+			writeError(w, http.StatusConflict, fmt.Sprintf("%v %v already exists", collection, name))
+			return
+		}
+		s.security[collection][name] = body
+		writeJSON(w, http.StatusCreated, map[string]any{"d": withoutSecrets(body)})
+		return
+	}
+	if s.security[collection][name] == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%v %v not found", collection, name))
+		return
+	}
+	body["Name"] = name
+	s.security[collection][name] = body
+	w.WriteHeader(http.StatusOK)
+}
+
+func withoutSecrets(material map[string]any) map[string]any {
+	result := map[string]any{}
+	for key, value := range material {
+		result[key] = value
+	}
+	for _, field := range secretFields {
+		delete(result, field)
+	}
+	return result
+}
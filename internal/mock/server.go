@@ -0,0 +1,194 @@
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is an in-memory fake of the Cloud Integration and API Management APIs used by FlashPipe,
+// for running the commands and tests without a live tenant
+type Server struct {
+	// Credentials that are accepted, any credentials are accepted if they are empty
+	Userid       string
+	Password     string
+	ClientId     string
+	ClientSecret string
+	// PageSize is the maximum number of results per page of OData collections, no paging if it is 0
+	PageSize int
+
+	mu           sync.Mutex
+	csrfToken    string
+	tokens       map[string]bool
+	packages     map[string]map[string]any
+	artifacts    map[string]*artifact
+	runtime      map[string]*runtimeArtifact
+	deployErrors map[string]string
+	proxies      map[string][]byte
+	messageLogs  []*MessageLog
+	endpoints    []*serviceEndpoint
+	security     map[string]map[string]map[string]any
+	keystore     map[string]*keystoreEntry
+}
+
+// NewServer returns an initialised Server instance without any content.
+func NewServer() *Server {
+	s := new(Server)
+	s.csrfToken = newToken()
+	s.tokens = map[string]bool{}
+	s.packages = map[string]map[string]any{}
+	s.artifacts = map[string]*artifact{}
+	s.runtime = map[string]*runtimeArtifact{}
+	s.deployErrors = map[string]string{}
+	s.proxies = map[string][]byte{}
+	s.security = map[string]map[string]map[string]any{}
+	s.keystore = map[string]*keystoreEntry{}
+	return s
+}
+
+// Start starts the server on a local port, e.g. for tests. The caller should call Close when finished
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// SetDeployError lets the deployment of the artifact fail in the runtime with the error message
+func (s *Server) SetDeployError(id string, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deployErrors[id] = message
+}
+
+// AddAPIProxy adds the API proxy with the content archive (zip) to API Management
+func (s *Server) AddAPIProxy(name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proxies[name] = content
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/oauth/token" {
+		s.token(w, r)
+		return
+	}
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v1/"):
+		if !s.validCsrfToken(w, r) {
+			return
+		}
+		s.serveCPI(w, r, strings.TrimPrefix(r.URL.Path, "/api/v1/"))
+	case strings.HasPrefix(r.URL.Path, "/http/"):
+		s.serveEndpoint(w, r)
+	case strings.HasPrefix(r.URL.Path, "/apiportal/api/1.0/"):
+		s.serveAPIM(w, r, strings.TrimPrefix(r.URL.Path, "/apiportal/api/1.0/"))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %v not found", r.URL.Path))
+	}
+}
+
+// token issues an access token with the client credentials grant
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if (s.ClientId != "" && clientId != s.ClientId) || (s.ClientSecret != "" && clientSecret != s.ClientSecret) {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	token := newToken()
+	s.tokens[token] = true
+	writeJSON(w, http.StatusOK, map[string]any{"access_token": token, "token_type": "bearer", "expires_in": 3600})
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return s.tokens[token]
+	}
+	userid, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	return (s.Userid == "" || userid == s.Userid) && (s.Password == "" || password == s.Password)
+}
+
+// validCsrfToken fetches the CSRF token, and checks the token of modifying requests with Basic Auth
+func (s *Server) validCsrfToken(w http.ResponseWriter, r *http.Request) bool {
+	if strings.EqualFold(r.Header.Get("x-csrf-token"), "fetch") {
+		w.Header().Set("x-csrf-token", s.csrfToken)
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: s.csrfToken, Path: "/"})
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return true
+	}
+	if r.Header.Get("x-csrf-token") != s.csrfToken {
+		w.Header().Set("x-csrf-token", "Required")
+		writeError(w, http.StatusForbidden, "CSRF token validation failed")
+		return false
+	}
+	return true
+}
+
+// writePage writes the results of an OData collection, with the __next link if they exceed the page size
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, results []any) {
+	skip, _ := strconv.Atoi(r.URL.Query().Get("$skiptoken"))
+	data := map[string]any{}
+	if skip > len(results) {
+		skip = len(results)
+	}
+	results = results[skip:]
+	if s.PageSize > 0 && len(results) > s.PageSize {
+		results = results[:s.PageSize]
+		query := r.URL.Query()
+		query.Set("$skiptoken", strconv.Itoa(skip+s.PageSize))
+		data["__next"] = fmt.Sprintf("%v?%v", r.URL.Path, query.Encode())
+	}
+	if results == nil {
+		results = []any{}
+	}
+	data["results"] = results
+	writeJSON(w, http.StatusOK, map[string]any{"d": data})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    http.StatusText(status),
+			"message": map[string]string{"lang": "en", "value": message},
+		},
+	})
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_CsrfToken(t *testing.T) {
+	svr := NewServer().Start()
+	defer svr.Close()
+
+	body := `{"Id": "MockPackage", "Name": "MockPackage"}`
+	req, _ := http.NewRequest(http.MethodPost, svr.URL+"/api/v1/IntegrationPackages", strings.NewReader(body))
+	req.SetBasicAuth("user", "password")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed with error - %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "Request without CSRF token not rejected")
+
+	req, _ = http.NewRequest(http.MethodGet, svr.URL+"/api/v1/", nil)
+	req.SetBasicAuth("user", "password")
+	req.Header.Set("x-csrf-token", "fetch")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed with error - %v", err)
+	}
+	resp.Body.Close()
+	token := resp.Header.Get("x-csrf-token")
+
+	req, _ = http.NewRequest(http.MethodPost, svr.URL+"/api/v1/IntegrationPackages", strings.NewReader(body))
+	req.SetBasicAuth("user", "password")
+	req.Header.Set("x-csrf-token", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed with error - %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Request with CSRF token not accepted")
+}
+
+func TestServer_Paging(t *testing.T) {
+	s := NewServer()
+	s.PageSize = 2
+	for _, id := range []string{"Package1", "Package2", "Package3"} {
+		s.packages[id] = map[string]any{"Id": id}
+	}
+	svr := s.Start()
+	defer svr.Close()
+
+	var ids []string
+	next := svr.URL + "/api/v1/IntegrationPackages"
+	for next != "" {
+		req, _ := http.NewRequest(http.MethodGet, next, nil)
+		req.SetBasicAuth("user", "password")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed with error - %v", err)
+		}
+		var page struct {
+			Root struct {
+				Results []struct{ Id string } `json:"results"`
+				Next    string                `json:"__next"`
+			} `json:"d"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Decode failed with error - %v", err)
+		}
+		for _, result := range page.Root.Results {
+			ids = append(ids, result.Id)
+		}
+		next = ""
+		if page.Root.Next != "" {
+			next = svr.URL + page.Root.Next
+		}
+	}
+	assert.Equal(t, []string{"Package1", "Package2", "Package3"}, ids, "Incorrect packages from all pages")
+}
@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+)
+
+// StartTestTenant starts a server for the tests that otherwise require a live tenant, unless FLASHPIPE_TMN_HOST
+// is set, and sets the FLASHPIPE_* environment variables of the tests to its URL and credentials.
+// The returned function stops the server
+func StartTestTenant() (stop func()) {
+	if os.Getenv("FLASHPIPE_TMN_HOST") != "" {
+		return func() {}
+	}
+	s := NewServer()
+	s.Userid, s.Password = "mockuser", "mockpassword"
+	s.ClientId, s.ClientSecret = "mockclient", "mocksecret"
+	// As in the live tenant of the tests, the message mapping of the test data fails validation on deployment
+	s.SetDeployError("Integration_Test_Message_Mapping", "Validation of the artifact failed")
+	s.AddAPIProxy("HelloWorldAPI", apiProxyArchive("HelloWorldAPI"))
+	svr := s.Start()
+
+	env := map[string]string{
+		"FLASHPIPE_TMN_HOST":                     svr.URL,
+		"FLASHPIPE_TMN_USERID":                   s.Userid,
+		"FLASHPIPE_TMN_PASSWORD":                 s.Password,
+		"FLASHPIPE_OAUTH_HOST":                   svr.URL,
+		"FLASHPIPE_OAUTH_PATH":                   "/oauth/token",
+		"FLASHPIPE_OAUTH_CLIENTID":               s.ClientId,
+		"FLASHPIPE_OAUTH_CLIENTSECRET":           s.ClientSecret,
+		"FLASHPIPE_APIPORTAL_HOST":               svr.URL,
+		"FLASHPIPE_APIPORTAL_OAUTH_CLIENTID":     s.ClientId,
+		"FLASHPIPE_APIPORTAL_OAUTH_CLIENTSECRET": s.ClientSecret,
+	}
+	for key, value := range env {
+		_ = os.Setenv(key, value)
+	}
+	return func() {
+		svr.Close()
+		for key := range env {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+// apiProxyArchive returns a minimal content archive of an API proxy
+func apiProxyArchive(name string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	files := map[string]string{
+		"manifest.json": `{"contentType": "ContentArchive", "modelVersion": "1.0"}`,
+		"APIProxies/" + name + "/" + name + ".xml": `<?xml version="1.0" encoding="UTF-8"?><APIProxy><name>` + name + `</name><version>1</version></APIProxy>`,
+	}
+	for _, fileName := range sortedKeys(files) {
+		fw, _ := writer.Create(fileName)
+		_, _ = fw.Write([]byte(files[fileName]))
+	}
+	_ = writer.Close()
+	return buf.Bytes()
+}
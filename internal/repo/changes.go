@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog/log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArtifactChange is an artifact whose directory in the Git repository was added or updated
+type ArtifactChange struct {
+	Id            string
+	Type          string
+	Dir           string
+	VersionBefore string
+	VersionAfter  string
+}
+
+// Changes collects the changed artifacts for CommitArtifacts, also from concurrent goroutines
+type Changes struct {
+	mu        sync.Mutex
+	Artifacts []*ArtifactChange
+}
+
+func NewChanges() *Changes {
+	return new(Changes)
+}
+
+// Add records the changed artifact. The snapshot and sync commands pass a nil Changes when the changes are
+// committed together, in which case Add does nothing
+func (c *Changes) Add(change *ArtifactChange) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Artifacts = append(c.Artifacts, change)
+}
+
+// CommitArtifacts commits the changes in the directory of each artifact separately, with a message generated from
+// the artifact and its changed files. Changes outside the directories of the artifacts are left uncommitted
+func CommitArtifacts(gitRepoDir string, changes *Changes, commitUser string, commitEmail string) (err error) {
+	if changes == nil || len(changes.Artifacts) == 0 {
+		return
+	}
+	repo, err := git.PlainOpen(gitRepoDir)
+	if err != nil {
+		return
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return
+	}
+	status, err := w.Status()
+	if err != nil {
+		return
+	}
+	repoDir, err := filepath.Abs(gitRepoDir)
+	if err != nil {
+		return
+	}
+
+	// Sort by ID so that the history does not depend on the processing order of parallel runs
+	artifacts := append([]*ArtifactChange(nil), changes.Artifacts...)
+	sort.SliceStable(artifacts, func(i, j int) bool { return artifacts[i].Id < artifacts[j].Id })
+	for _, artifact := range artifacts {
+		var dir string
+		dir, err = filepath.Abs(artifact.Dir)
+		if err != nil {
+			return
+		}
+		dir, err = filepath.Rel(repoDir, dir)
+		if err != nil {
+			return
+		}
+		prefix := filepath.ToSlash(dir) + "/"
+
+		var files []string
+		for path, fileStatus := range status {
+			if !strings.HasPrefix(path, prefix) || fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified {
+				continue
+			}
+			if fileStatus.Worktree == git.Deleted {
+				_, err = w.Remove(path)
+			} else {
+				_, err = w.Add(path)
+			}
+			if err != nil {
+				return
+			}
+			files = append(files, strings.TrimPrefix(path, prefix))
+		}
+		if len(files) == 0 {
+			log.Info().Msgf("No changes to commit for artifact %v", artifact.Id)
+			continue
+		}
+		sort.Strings(files)
+
+		msg := artifactCommitMessage(artifact, files)
+		log.Info().Msgf("Committing changes of artifact %v", artifact.Id)
+		_, err = w.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  commitUser,
+				Email: commitEmail,
+				When:  time.Now(),
+			},
+		})
+		if err != nil {
+			return
+		}
+	}
+	log.Info().Msg("🏆 Changes of artifacts committed")
+	return
+}
+
+// artifactCommitMessage returns a message like "Update Integration MyIFlow 1.0.0 -> 1.0.1" followed by the changed files
+func artifactCommitMessage(artifact *ArtifactChange, files []string) string {
+	var b strings.Builder
+	switch {
+	case artifact.VersionBefore == "":
+		fmt.Fprintf(&b, "Add %v %v %v", artifact.Type, artifact.Id, artifact.VersionAfter)
+	case artifact.VersionBefore == artifact.VersionAfter:
+		fmt.Fprintf(&b, "Update %v %v %v", artifact.Type, artifact.Id, artifact.VersionAfter)
+	default:
+		fmt.Fprintf(&b, "Update %v %v %v -> %v", artifact.Type, artifact.Id, artifact.VersionBefore, artifact.VersionAfter)
+	}
+	b.WriteString("\n\nChanged files:\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- %v\n", f)
+	}
+	return b.String()
+}
@@ -1,13 +1,32 @@
 package repo
 
 import (
+	"fmt"
+	"github.com/go-errors/errors"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/rs/zerolog/log"
+	"os"
+	"strings"
 	"time"
 )
 
+// PushOptions are the remote and its credentials used by Push
+type PushOptions struct {
+	// Remote is the name of a configured remote or the URL of the remote repository
+	Remote string
+	// Token is used as password for HTTPS authentication
+	Token string
+	// SSHKey is the private key (PEM content or file) for SSH authentication
+	SSHKey         string
+	SSHKeyPassword string
+}
+
 func CommitToRepo(gitRepoDir string, commitMsg string, commitUser string, commitEmail string) (err error) {
 	// References:
 	// https://github.com/go-git/go-git/tree/master/_examples
@@ -70,3 +89,145 @@ func CommitToRepo(gitRepoDir string, commitMsg string, commitUser string, commit
 	}
 	return
 }
+
+// CheckoutBranch checks out the branch before changes are made in the working tree. If the branch does not exist,
+// it is created from the branch of the same name of the remote, or else from the current commit
+func CheckoutBranch(gitRepoDir string, branch string, remote string) (err error) {
+	repo, err := git.PlainOpen(gitRepoDir)
+	if err != nil {
+		return
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if head.Name() == branchRef {
+		log.Info().Msgf("Branch %v is already checked out", branch)
+		return
+	}
+
+	opts := &git.CheckoutOptions{Branch: branchRef}
+	_, err = repo.Reference(branchRef, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		opts.Create = true
+		if remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, branch), true); err == nil {
+			log.Info().Msgf("Creating branch %v from %v/%v", branch, remote, branch)
+			opts.Hash = remoteRef.Hash()
+		} else {
+			log.Info().Msgf("Creating branch %v from commit %v", branch, head.Hash())
+		}
+	} else if err != nil {
+		return
+	}
+	log.Info().Msgf("Checking out branch %v", branch)
+	err = w.Checkout(opts)
+	if err != nil {
+		return fmt.Errorf("checkout of branch %v failed: %w", branch, err)
+	}
+	return
+}
+
+// TagHead creates a lightweight tag on the current commit. It is not an error if the tag already exists on that commit
+func TagHead(gitRepoDir string, tag string) (err error) {
+	repo, err := git.PlainOpen(gitRepoDir)
+	if err != nil {
+		return
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return
+	}
+
+	existing, err := repo.Tag(tag)
+	if err == nil {
+		target := existing.Hash()
+		if obj, err := repo.TagObject(target); err == nil {
+			target = obj.Target
+		}
+		if target != head.Hash() {
+			return fmt.Errorf("tag %v already exists on commit %v", tag, target)
+		}
+		log.Info().Msgf("Tag %v already exists on commit %v", tag, target)
+		return nil
+	}
+	if !errors.Is(err, git.ErrTagNotFound) {
+		return
+	}
+	log.Info().Msgf("Creating tag %v on commit %v", tag, head.Hash())
+	_, err = repo.CreateTag(tag, head.Hash(), nil)
+	return
+}
+
+// Push pushes the current branch, and the tag if it is not empty, to the remote
+func Push(gitRepoDir string, tag string, opts *PushOptions) (err error) {
+	repo, err := git.PlainOpen(gitRepoDir)
+	if err != nil {
+		return
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return
+	}
+	if !head.Name().IsBranch() {
+		return fmt.Errorf("HEAD of %v is not on a branch, the branch to push must be checked out", gitRepoDir)
+	}
+
+	refSpecs := []config.RefSpec{config.RefSpec(fmt.Sprintf("%v:%v", head.Name(), head.Name()))}
+	if tag != "" {
+		tagRef := plumbing.NewTagReferenceName(tag)
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("%v:%v", tagRef, tagRef)))
+	}
+	auth, err := opts.auth()
+	if err != nil {
+		return
+	}
+	pushOpts := &git.PushOptions{RefSpecs: refSpecs, Auth: auth}
+
+	// The remote is either the name of a configured remote or a URL
+	remote, err := repo.Remote(opts.Remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		remote = git.NewRemote(repo.Storer, &config.RemoteConfig{Name: "flashpipe", URLs: []string{opts.Remote}})
+	} else if err != nil {
+		return
+	}
+	pushOpts.RemoteName = remote.Config().Name
+	log.Info().Msgf("Pushing %v to %v", strings.TrimPrefix(head.Name().String(), "refs/heads/"), remote.Config().URLs[0])
+	err = remote.Push(pushOpts)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		log.Info().Msg("🏆 Remote is already up to date")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("push to %v failed: %w", opts.Remote, err)
+	}
+	log.Info().Msg("🏆 Changes pushed")
+	return
+}
+
+func (o *PushOptions) auth() (transport.AuthMethod, error) {
+	switch {
+	case o.Token != "":
+		// Git hosting services accept any non-empty user name with a token
+		return &http.BasicAuth{Username: "flashpipe", Password: o.Token}, nil
+	case o.SSHKey != "":
+		key := []byte(o.SSHKey)
+		if !strings.HasPrefix(strings.TrimSpace(o.SSHKey), "-----BEGIN") {
+			content, err := os.ReadFile(o.SSHKey)
+			if err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+			key = content
+		}
+		auth, err := ssh.NewPublicKeys("git", key, o.SSHKeyPassword)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH key: %w", err)
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func initRepo(t *testing.T) (string, string) {
+	remoteDir := t.TempDir()
+	_, err := git.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatalf("PlainInit failed with error - %v", err)
+	}
+	gitRepoDir := t.TempDir()
+	_, err = git.PlainInit(gitRepoDir, false)
+	if err != nil {
+		t.Fatalf("PlainInit failed with error - %v", err)
+	}
+	writeFile(t, gitRepoDir, "README.md", "Initial")
+	err = CommitToRepo(gitRepoDir, "Initial commit", "flashpipe", "flashpipe@example.com")
+	if err != nil {
+		t.Fatalf("CommitToRepo failed with error - %v", err)
+	}
+	return gitRepoDir, "file://" + remoteDir
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) {
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+}
+
+func TestBranchTagAndPush(t *testing.T) {
+	gitRepoDir, remoteUrl := initRepo(t)
+
+	err := CheckoutBranch(gitRepoDir, "snapshot", "origin")
+	if err != nil {
+		t.Fatalf("CheckoutBranch failed with error - %v", err)
+	}
+	writeFile(t, gitRepoDir, "IFlow1.txt", "Snapshot")
+	err = CommitToRepo(gitRepoDir, "Tenant snapshot", "flashpipe", "flashpipe@example.com")
+	if err != nil {
+		t.Fatalf("CommitToRepo failed with error - %v", err)
+	}
+	err = TagHead(gitRepoDir, "snapshot-2024-06-28")
+	if err != nil {
+		t.Fatalf("TagHead failed with error - %v", err)
+	}
+	// Tagging the same commit again is not an error
+	err = TagHead(gitRepoDir, "snapshot-2024-06-28")
+	if err != nil {
+		t.Fatalf("TagHead failed with error - %v", err)
+	}
+	err = Push(gitRepoDir, "snapshot-2024-06-28", &PushOptions{Remote: remoteUrl})
+	if err != nil {
+		t.Fatalf("Push failed with error - %v", err)
+	}
+	// Pushing again without changes is not an error
+	err = Push(gitRepoDir, "snapshot-2024-06-28", &PushOptions{Remote: remoteUrl})
+	if err != nil {
+		t.Fatalf("Push failed with error - %v", err)
+	}
+
+	local, _ := git.PlainOpen(gitRepoDir)
+	head, _ := local.Head()
+	assert.Equal(t, plumbing.NewBranchReferenceName("snapshot"), head.Name(), "Branch not checked out")
+
+	remote, _ := git.PlainOpen(filepath.Join(remoteUrl[len("file://"):]))
+	branch, err := remote.Reference(plumbing.NewBranchReferenceName("snapshot"), true)
+	if assert.NoError(t, err, "Branch not pushed") {
+		assert.Equal(t, head.Hash(), branch.Hash(), "Incorrect commit of pushed branch")
+	}
+	tag, err := remote.Tag("snapshot-2024-06-28")
+	if assert.NoError(t, err, "Tag not pushed") {
+		assert.Equal(t, head.Hash(), tag.Hash(), "Incorrect commit of pushed tag")
+	}
+}
+
+func TestTagHead_ExistsOnOtherCommit(t *testing.T) {
+	gitRepoDir, _ := initRepo(t)
+
+	err := TagHead(gitRepoDir, "v1")
+	if err != nil {
+		t.Fatalf("TagHead failed with error - %v", err)
+	}
+	writeFile(t, gitRepoDir, "IFlow1.txt", "Change")
+	err = CommitToRepo(gitRepoDir, "Change", "flashpipe", "flashpipe@example.com")
+	if err != nil {
+		t.Fatalf("CommitToRepo failed with error - %v", err)
+	}
+	err = TagHead(gitRepoDir, "v1")
+	assert.ErrorContains(t, err, "tag v1 already exists on commit", "Existing tag not detected")
+}
+
+func TestPush_DetachedHead(t *testing.T) {
+	gitRepoDir, remoteUrl := initRepo(t)
+
+	local, _ := git.PlainOpen(gitRepoDir)
+	head, _ := local.Head()
+	w, _ := local.Worktree()
+	err := w.Checkout(&git.CheckoutOptions{Hash: head.Hash()})
+	if err != nil {
+		t.Fatalf("Checkout failed with error - %v", err)
+	}
+	err = Push(gitRepoDir, "", &PushOptions{Remote: remoteUrl})
+	assert.ErrorContains(t, err, "is not on a branch", "Detached HEAD not detected")
+}
+
+func TestCommitArtifacts(t *testing.T) {
+	gitRepoDir, _ := initRepo(t)
+	for _, dir := range []string{"IFlow1/META-INF", "IFlow2/META-INF"} {
+		err := os.MkdirAll(filepath.Join(gitRepoDir, dir), os.ModePerm)
+		if err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+	}
+	writeFile(t, gitRepoDir, "IFlow1/META-INF/MANIFEST.MF", "Bundle-Version: 1.0.0")
+	err := CommitToRepo(gitRepoDir, "Add IFlow1", "flashpipe", "flashpipe@example.com")
+	if err != nil {
+		t.Fatalf("CommitToRepo failed with error - %v", err)
+	}
+
+	writeFile(t, gitRepoDir, "IFlow1/META-INF/MANIFEST.MF", "Bundle-Version: 1.0.1")
+	writeFile(t, gitRepoDir, "IFlow2/META-INF/MANIFEST.MF", "Bundle-Version: 1.0.0")
+	writeFile(t, gitRepoDir, "Package.json", "{}")
+	changes := NewChanges()
+	changes.Add(&ArtifactChange{Id: "IFlow2", Type: "Integration", Dir: filepath.Join(gitRepoDir, "IFlow2"), VersionAfter: "1.0.0"})
+	changes.Add(&ArtifactChange{Id: "IFlow1", Type: "Integration", Dir: filepath.Join(gitRepoDir, "IFlow1"), VersionBefore: "1.0.0", VersionAfter: "1.0.1"})
+	err = CommitArtifacts(gitRepoDir, changes, "Snapshot Bot", "bot@example.com")
+	if err != nil {
+		t.Fatalf("CommitArtifacts failed with error - %v", err)
+	}
+
+	local, _ := git.PlainOpen(gitRepoDir)
+	head, _ := local.Head()
+	commit, _ := local.CommitObject(head.Hash())
+	assert.Equal(t, "Add Integration IFlow2 1.0.0\n\nChanged files:\n- META-INF/MANIFEST.MF\n", commit.Message, "Incorrect message of IFlow2 commit")
+	assert.Equal(t, "Snapshot Bot", commit.Author.Name, "Incorrect author")
+	parent, _ := commit.Parent(0)
+	assert.Equal(t, "Update Integration IFlow1 1.0.0 -> 1.0.1\n\nChanged files:\n- META-INF/MANIFEST.MF\n", parent.Message, "Incorrect message of IFlow1 commit")
+
+	// Changes outside the artifact directories remain uncommitted
+	w, _ := local.Worktree()
+	status, _ := w.Status()
+	assert.Len(t, status, 1, "Incorrect number of uncommitted files")
+	assert.Equal(t, git.Untracked, status.File("Package.json").Worktree, "Package.json not left uncommitted")
+}
+
+func TestCommitArtifacts_NilChanges(t *testing.T) {
+	err := CommitArtifacts("/non/existent/repo", nil, "flashpipe", "flashpipe@example.com")
+	assert.NoError(t, err, "Nil changes not ignored")
+}
@@ -0,0 +1,167 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/go-errors/errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	ActionCreated      = "created"
+	ActionUpdated      = "updated"
+	ActionUnchanged    = "unchanged"
+	ActionSkippedDraft = "skipped-draft"
+	ActionNotRun       = "not-run"
+	ActionDeployed     = "deployed"
+	ActionFailed       = "failed"
+	ActionPassed       = "passed"
+)
+
+// Entry is the outcome of processing a single artifact
+type Entry struct {
+	Id            string  `json:"id"`
+	Type          string  `json:"type"`
+	PackageId     string  `json:"packageId,omitempty"`
+	Action        string  `json:"action"`
+	VersionBefore string  `json:"versionBefore,omitempty"`
+	VersionAfter  string  `json:"versionAfter,omitempty"`
+	Duration      float64 `json:"durationSeconds"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// Report collects the outcome of the artifacts processed by a command
+type Report struct {
+	Command   string    `json:"command"`
+	StartTime time.Time `json:"startTime"`
+	Entries   []*Entry  `json:"artifacts"`
+	mu        sync.Mutex
+}
+
+// New returns an initialised Report instance.
+func New(command string) *Report {
+	return &Report{Command: command, StartTime: time.Now(), Entries: []*Entry{}}
+}
+
+// Add records the entry with the duration since start and the error, if any. Nothing is recorded on a nil Report,
+// so that callers do not need to check if a report is requested
+func (r *Report) Add(entry *Entry, start time.Time, err error) {
+	if r == nil {
+		return
+	}
+	entry.Duration = time.Since(start).Seconds()
+	if err != nil {
+		entry.Action = ActionFailed
+		entry.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Entries = append(r.Entries, entry)
+}
+
+// Write writes the report to file in json or junit format
+func (r *Report) Write(file string, format string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var content []byte
+	var err error
+	switch format {
+	case "json":
+		content, err = json.MarshalIndent(r, "", "  ")
+	case "junit":
+		content, err = xml.MarshalIndent(r.junit(), "", "  ")
+		content = append([]byte(xml.Header), content...)
+	default:
+		return fmt.Errorf("invalid report format %v", format)
+	}
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	err = os.WriteFile(file, append(content, '\n'), 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr"`
+	Cases     []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit converts the report into a single test suite with a test case per artifact
+func (r *Report) junit() *junitTestSuites {
+	suite := &junitTestSuite{
+		Name:      r.Command,
+		Time:      fmt.Sprintf("%.3f", time.Since(r.StartTime).Seconds()),
+		Timestamp: r.StartTime.Format(time.RFC3339),
+	}
+	for _, entry := range r.Entries {
+		className := entry.Type
+		if entry.PackageId != "" {
+			className = entry.PackageId + "." + entry.Type
+		}
+		testCase := &junitTestCase{
+			Name:      entry.Id,
+			ClassName: className,
+			Time:      fmt.Sprintf("%.3f", entry.Duration),
+			SystemOut: fmt.Sprintf("action: %v", entry.Action),
+		}
+		if entry.VersionBefore != "" || entry.VersionAfter != "" {
+			testCase.SystemOut += fmt.Sprintf(", version: %v -> %v", entry.VersionBefore, entry.VersionAfter)
+		}
+		switch entry.Action {
+		case ActionFailed:
+			testCase.Failure = &junitMessage{Message: entry.Error, Text: entry.Error}
+			suite.Failures++
+		case ActionSkippedDraft:
+			testCase.Skipped = &junitMessage{Message: "Artifact is in draft version"}
+			suite.Skipped++
+		case ActionNotRun:
+			testCase.Skipped = &junitMessage{Message: "Artifact not processed as an earlier artifact failed"}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+	return &junitTestSuites{
+		Name:     r.Command,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []*junitTestSuite{suite},
+	}
+}
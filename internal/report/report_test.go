@@ -0,0 +1,74 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestReport() *Report {
+	r := New("flashpipe sync")
+	r.Add(&Entry{Id: "IFlow1", Type: "Integration", PackageId: "Pkg", Action: ActionUpdated, VersionBefore: "1.0.0", VersionAfter: "1.0.1"}, time.Now(), nil)
+	r.Add(&Entry{Id: "IFlow2", Type: "Integration", PackageId: "Pkg", Action: ActionCreated}, time.Now(), fmt.Errorf("upload failed"))
+	r.Add(&Entry{Id: "IFlow3", Type: "Integration", PackageId: "Pkg", Action: ActionSkippedDraft}, time.Now(), nil)
+	r.Add(&Entry{Id: "IFlow4", Type: "Integration", PackageId: "Pkg", Action: ActionNotRun}, time.Now(), nil)
+	return r
+}
+
+func TestAdd_NilReport(t *testing.T) {
+	var r *Report
+	assert.NotPanics(t, func() {
+		r.Add(&Entry{Id: "IFlow1"}, time.Now(), nil)
+	}, "Add on nil report panics")
+}
+
+func TestWrite_JSON(t *testing.T) {
+	file := t.TempDir() + "/report.json"
+	err := newTestReport().Write(file, "json")
+	if err != nil {
+		t.Fatalf("Write failed with error - %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	var r *Report
+	err = json.Unmarshal(content, &r)
+	if err != nil {
+		t.Fatalf("Unmarshal failed with error - %v", err)
+	}
+	assert.Equal(t, "flashpipe sync", r.Command, "Incorrect command")
+	if assert.Len(t, r.Entries, 4, "Incorrect number of artifacts") {
+		assert.Equal(t, ActionUpdated, r.Entries[0].Action, "Incorrect action of IFlow1")
+		assert.Equal(t, "1.0.0", r.Entries[0].VersionBefore, "Incorrect version before of IFlow1")
+		assert.Equal(t, "1.0.1", r.Entries[0].VersionAfter, "Incorrect version after of IFlow1")
+		assert.Equal(t, ActionFailed, r.Entries[1].Action, "Incorrect action of IFlow2")
+		assert.Equal(t, "upload failed", r.Entries[1].Error, "Incorrect error of IFlow2")
+		assert.Equal(t, ActionNotRun, r.Entries[3].Action, "Incorrect action of IFlow4")
+	}
+}
+
+func TestWrite_JUnit(t *testing.T) {
+	file := t.TempDir() + "/report.xml"
+	err := newTestReport().Write(file, "junit")
+	if err != nil {
+		t.Fatalf("Write failed with error - %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	assert.Contains(t, string(content), `<testsuite name="flashpipe sync" tests="4" failures="1" skipped="2"`, "Incorrect test suite")
+	assert.Contains(t, string(content), `<testcase name="IFlow1" classname="Pkg.Integration"`, "Incorrect test case")
+	assert.Contains(t, string(content), `<failure message="upload failed">upload failed</failure>`, "Failure not reported")
+	assert.Contains(t, string(content), `<skipped message="Artifact not processed as an earlier artifact failed"></skipped>`, "Artifact not run not reported")
+	assert.Contains(t, string(content), `<system-out>action: updated, version: 1.0.0 -&gt; 1.0.1</system-out>`, "Action not reported")
+}
+
+func TestWrite_InvalidFormat(t *testing.T) {
+	err := newTestReport().Write(t.TempDir()+"/report.txt", "csv")
+	assert.Error(t, err, "Invalid format accepted")
+}
@@ -0,0 +1,188 @@
+package security
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-errors/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest is the security material of a tenant maintained as code. Secret values are not part of the manifest
+// but read from environment variables or files, so that the manifest can be stored in Git
+type Manifest struct {
+	UserCredentials         []*UserCredential         `yaml:"userCredentials"`
+	OAuth2ClientCredentials []*OAuth2ClientCredential `yaml:"oauth2ClientCredentials"`
+	SecureParameters        []*SecureParameter        `yaml:"secureParameters"`
+	Certificates            []*Certificate            `yaml:"certificates"`
+	dir                     string
+}
+
+type UserCredential struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Kind defaults to default, other values are e.g. successfactors or openconnectors
+	Kind      string `yaml:"kind"`
+	User      string `yaml:"user"`
+	Password  Secret `yaml:"password"`
+	CompanyId string `yaml:"companyId"`
+}
+
+type OAuth2ClientCredential struct {
+	Name            string `yaml:"name"`
+	Description     string `yaml:"description"`
+	TokenServiceUrl string `yaml:"tokenServiceUrl"`
+	ClientId        string `yaml:"clientId"`
+	ClientSecret    Secret `yaml:"clientSecret"`
+	// ClientAuthentication defaults to SendAsBodyParameter, the other value is SendAsBasicAuthHeader
+	ClientAuthentication string `yaml:"clientAuthentication"`
+	Scope                string `yaml:"scope"`
+	ScopeContentType     string `yaml:"scopeContentType"`
+	Resource             string `yaml:"resource"`
+	Audience             string `yaml:"audience"`
+}
+
+type SecureParameter struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Value       Secret `yaml:"value"`
+}
+
+// Certificate is a public certificate of the tenant keystore. File is the path of the PEM file, relative to the
+// directory of the manifest
+type Certificate struct {
+	Alias string `yaml:"alias"`
+	File  string `yaml:"file"`
+}
+
+// Secret is a secret value that is read from the environment variable Env or the file File
+type Secret struct {
+	Env  string `yaml:"env"`
+	File string `yaml:"file"`
+}
+
+// UnmarshalYAML rejects secret values in the manifest itself
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: secret values must be read from an environment variable (env) or a file (file), not stored in the manifest", node.Line)
+	}
+	type plain Secret
+	return node.Decode((*plain)(s))
+}
+
+// Value returns the secret value of the environment variable or the content of the file, without trailing line breaks
+func (s *Secret) Value() (string, error) {
+	if s.Env != "" {
+		value, found := os.LookupEnv(s.Env)
+		if !found || value == "" {
+			return "", fmt.Errorf("environment variable %v is not set", s.Env)
+		}
+		return value, nil
+	}
+	content, err := os.ReadFile(s.File)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// LoadManifest reads and validates the manifest file
+func LoadManifest(file string) (*Manifest, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	m := new(Manifest)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid security manifest %v: %w", file, err)
+	}
+	m.dir = filepath.Dir(file)
+	if err = m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid security manifest %v: %w", file, err)
+	}
+	return m, nil
+}
+
+func (m *Manifest) validate() error {
+	names := map[string]bool{}
+	checkName := func(kind string, name string) error {
+		if name == "" {
+			return fmt.Errorf("%v without name", kind)
+		}
+		// User credentials, OAuth2 client credentials and secure parameters share the names of the credential store
+		if names[name] {
+			return fmt.Errorf("duplicate name %v", name)
+		}
+		names[name] = true
+		return nil
+	}
+	checkSecret := func(kind string, name string, field string, secret Secret) error {
+		if (secret.Env == "") == (secret.File == "") {
+			return fmt.Errorf("%v %v requires either env or file for %v", kind, name, field)
+		}
+		return nil
+	}
+
+	for _, c := range m.UserCredentials {
+		if err := checkName("user credential", c.Name); err != nil {
+			return err
+		}
+		if err := checkSecret("user credential", c.Name, "password", c.Password); err != nil {
+			return err
+		}
+		if c.Kind == "" {
+			c.Kind = "default"
+		}
+	}
+	for _, c := range m.OAuth2ClientCredentials {
+		if err := checkName("OAuth2 client credential", c.Name); err != nil {
+			return err
+		}
+		if err := checkSecret("OAuth2 client credential", c.Name, "clientSecret", c.ClientSecret); err != nil {
+			return err
+		}
+		switch c.ClientAuthentication {
+		case "":
+			c.ClientAuthentication = "SendAsBodyParameter"
+		case "SendAsBodyParameter", "SendAsBasicAuthHeader":
+		default:
+			return fmt.Errorf("OAuth2 client credential %v has invalid clientAuthentication %v", c.Name, c.ClientAuthentication)
+		}
+	}
+	for _, p := range m.SecureParameters {
+		if err := checkName("secure parameter", p.Name); err != nil {
+			return err
+		}
+		if err := checkSecret("secure parameter", p.Name, "value", p.Value); err != nil {
+			return err
+		}
+	}
+	aliases := map[string]bool{}
+	for _, c := range m.Certificates {
+		if c.Alias == "" || c.File == "" {
+			return fmt.Errorf("certificate requires alias and file")
+		}
+		if aliases[c.Alias] {
+			return fmt.Errorf("duplicate certificate alias %v", c.Alias)
+		}
+		aliases[c.Alias] = true
+	}
+	return nil
+}
+
+// CertificateContent returns the content of the certificate file
+func (m *Manifest) CertificateContent(c *Certificate) ([]byte, error) {
+	file := c.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(m.dir, file)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return content, nil
+}
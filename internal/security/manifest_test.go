@@ -0,0 +1,88 @@
+package security
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "security.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	return file
+}
+
+func TestLoadManifest(t *testing.T) {
+	file := writeManifest(t, `
+userCredentials:
+  - name: SFTP_User
+    user: sftpuser
+    password:
+      env: SFTP_PASSWORD
+oauth2ClientCredentials:
+  - name: S4_OAuth
+    tokenServiceUrl: https://s4.example.com/oauth/token
+    clientId: client
+    clientSecret:
+      file: /run/secrets/s4
+secureParameters:
+  - name: ApiKey
+    value:
+      env: API_KEY
+certificates:
+  - alias: partner_cert
+    file: certs/partner.pem
+`)
+	m, err := LoadManifest(file)
+	if err != nil {
+		t.Fatalf("LoadManifest failed with error - %v", err)
+	}
+	if assert.Len(t, m.UserCredentials, 1, "Incorrect number of user credentials") {
+		assert.Equal(t, "default", m.UserCredentials[0].Kind, "Kind does not default to default")
+		assert.Equal(t, "SFTP_PASSWORD", m.UserCredentials[0].Password.Env, "Incorrect password env")
+	}
+	if assert.Len(t, m.OAuth2ClientCredentials, 1, "Incorrect number of OAuth2 client credentials") {
+		assert.Equal(t, "SendAsBodyParameter", m.OAuth2ClientCredentials[0].ClientAuthentication, "Incorrect default client authentication")
+	}
+	assert.Len(t, m.SecureParameters, 1, "Incorrect number of secure parameters")
+	assert.Len(t, m.Certificates, 1, "Incorrect number of certificates")
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"secret values must be read from an environment variable": "userCredentials:\n  - name: SFTP_User\n    password: secret\n",
+		"requires either env or file for password":                "userCredentials:\n  - name: SFTP_User\n    password:\n      env: A\n      file: b\n",
+		"duplicate name SFTP_User":                                "userCredentials:\n  - name: SFTP_User\n    password:\n      env: A\nsecureParameters:\n  - name: SFTP_User\n    value:\n      env: B\n",
+		"invalid clientAuthentication":                            "oauth2ClientCredentials:\n  - name: S4_OAuth\n    clientSecret:\n      env: A\n    clientAuthentication: Header\n",
+		"field user not found":                                    "secureParameters:\n  - name: ApiKey\n    user: someone\n    value:\n      env: A\n",
+	}
+	for expected, content := range tests {
+		_, err := LoadManifest(writeManifest(t, content))
+		assert.ErrorContains(t, err, expected, "Invalid manifest not detected")
+	}
+}
+
+func TestSecretValue(t *testing.T) {
+	t.Setenv("FLASHPIPE_TEST_SECRET", "from-env")
+	value, err := (&Secret{Env: "FLASHPIPE_TEST_SECRET"}).Value()
+	if err != nil {
+		t.Fatalf("Value failed with error - %v", err)
+	}
+	assert.Equal(t, "from-env", value, "Incorrect value of environment variable")
+
+	file := filepath.Join(t.TempDir(), "secret")
+	if err = os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	value, err = (&Secret{File: file}).Value()
+	if err != nil {
+		t.Fatalf("Value failed with error - %v", err)
+	}
+	assert.Equal(t, "from-file", value, "Incorrect value of file")
+
+	_, err = (&Secret{Env: "FLASHPIPE_TEST_SECRET_NOT_SET"}).Value()
+	assert.ErrorContains(t, err, "is not set", "Missing environment variable not detected")
+}
@@ -0,0 +1,188 @@
+package smoketest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Case is a test case that sends a message to the runtime endpoint of a deployed IFlow
+type Case struct {
+	// Name defaults to the name of the file without extension
+	Name string `yaml:"name"`
+	// IFlowId overrides the ID of the artifact that contains the test case
+	IFlowId string `yaml:"iflow"`
+	// Method defaults to POST
+	Method string `yaml:"method"`
+	// Endpoint selects the entry point with the URL ending with it if the IFlow has several
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	Payload     string            `yaml:"payload"`
+	PayloadFile string            `yaml:"payloadFile"`
+	Expect      Expectation       `yaml:"expect"`
+	File        string            `yaml:"-"`
+}
+
+// Expectation are the assertions on the response. If Status is 0, any 2xx status is expected
+type Expectation struct {
+	Status       int               `yaml:"status"`
+	Headers      map[string]string `yaml:"headers"`
+	BodyContains []string          `yaml:"bodyContains"`
+	BodyMatches  string            `yaml:"bodyMatches"`
+}
+
+// LoadCases reads the test cases of the YAML files (*.yaml, *.yml) in the directory, in order of the file names.
+// A missing directory has no test cases
+func LoadCases(dir string) ([]*Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, 0)
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	var cases []*Case
+	for _, file := range files {
+		c, err := loadCase(file)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+func loadCase(file string) (*Case, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	c := new(Case)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(c); err != nil {
+		return nil, fmt.Errorf("invalid test case %v: %w", file, err)
+	}
+	c.File = file
+	if c.Name == "" {
+		c.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	c.Method = strings.ToUpper(c.Method)
+	if c.Payload != "" && c.PayloadFile != "" {
+		return nil, fmt.Errorf("invalid test case %v: payload and payloadFile are mutually exclusive", file)
+	}
+	if c.PayloadFile != "" {
+		// The payload file is relative to the test case file
+		payload, err := os.ReadFile(filepath.Join(filepath.Dir(file), c.PayloadFile))
+		if err != nil {
+			return nil, fmt.Errorf("invalid test case %v: %w", file, err)
+		}
+		c.Payload = string(payload)
+	}
+	if c.Expect.BodyMatches != "" {
+		if _, err = regexp.Compile(c.Expect.BodyMatches); err != nil {
+			return nil, fmt.Errorf("invalid test case %v: bodyMatches: %w", file, err)
+		}
+	}
+	return c, nil
+}
+
+// SelectURL returns the URL of the entry points that ends with the endpoint of the test case. If the test case
+// has no endpoint, the IFlow must have a single entry point
+func (c *Case) SelectURL(urls []string) (string, error) {
+	if len(urls) == 0 {
+		return "", fmt.Errorf("no service endpoint found, check that the IFlow is deployed and has a HTTP based sender adapter")
+	}
+	if c.Endpoint == "" {
+		if len(urls) > 1 {
+			return "", fmt.Errorf("IFlow has several service endpoints %v, select one with endpoint", urls)
+		}
+		return urls[0], nil
+	}
+	for _, url := range urls {
+		if strings.HasSuffix(url, c.Endpoint) {
+			return url, nil
+		}
+	}
+	return "", fmt.Errorf("no service endpoint ending with %v in %v", c.Endpoint, urls)
+}
+
+// Run sends the message of the test case to the URL and checks the response against the expectation.
+// All failed assertions are returned together in the error
+func (c *Case) Run(exe *httpclnt.HTTPExecuter, url string) error {
+	log.Debug().Msgf("Sending %v request to %v", c.Method, url)
+	var body io.Reader = http.NoBody
+	if c.Payload != "" {
+		body = strings.NewReader(c.Payload)
+	}
+	resp, err := exe.ExecRequestToURL(c.Method, url, body, c.Headers)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	respBody, err := exe.ReadRespBody(resp)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return c.Expect.check(resp, respBody)
+}
+
+func (e *Expectation) check(resp *http.Response, respBody []byte) error {
+	var failures []string
+	if e.Status != 0 && resp.StatusCode != e.Status {
+		failures = append(failures, fmt.Sprintf("expected status %d but got %d", e.Status, resp.StatusCode))
+	}
+	if e.Status == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		failures = append(failures, fmt.Sprintf("expected a 2xx status but got %d", resp.StatusCode))
+	}
+	for _, name := range sortedKeys(e.Headers) {
+		if value := resp.Header.Get(name); value != e.Headers[name] {
+			failures = append(failures, fmt.Sprintf("expected header %v = %v but got %v", name, e.Headers[name], value))
+		}
+	}
+	for _, text := range e.BodyContains {
+		if !bytes.Contains(respBody, []byte(text)) {
+			failures = append(failures, fmt.Sprintf("expected body to contain %q", text))
+		}
+	}
+	if e.BodyMatches != "" && !regexp.MustCompile(e.BodyMatches).Match(respBody) {
+		failures = append(failures, fmt.Sprintf("expected body to match %q", e.BodyMatches))
+	}
+	if len(failures) > 0 {
+		if len(respBody) > 0 {
+			log.Debug().Msgf("Response body = %s", respBody)
+		}
+		return fmt.Errorf("%v", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
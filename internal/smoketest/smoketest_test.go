@@ -0,0 +1,78 @@
+package smoketest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCase(t *testing.T, dir string, name string, content string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+}
+
+func TestLoadCases(t *testing.T) {
+	dir := t.TempDir()
+	writeCase(t, dir, "b.yml", "name: Second\nmethod: get\nendpoint: /orders\n")
+	writeCase(t, dir, "a.yaml", "payloadFile: payload.xml\nexpect:\n  status: 202\n")
+	writeCase(t, dir, "payload.xml", "<Order/>")
+
+	cases, err := LoadCases(dir)
+	if err != nil {
+		t.Fatalf("LoadCases failed with error - %v", err)
+	}
+	if assert.Len(t, cases, 2, "Incorrect number of test cases") {
+		assert.Equal(t, "a", cases[0].Name, "Name does not default to file name")
+		assert.Equal(t, http.MethodPost, cases[0].Method, "Method does not default to POST")
+		assert.Equal(t, "<Order/>", cases[0].Payload, "Payload file not read")
+		assert.Equal(t, 202, cases[0].Expect.Status, "Incorrect expected status")
+		assert.Equal(t, "Second", cases[1].Name, "Incorrect name")
+		assert.Equal(t, http.MethodGet, cases[1].Method, "Incorrect method")
+	}
+
+	cases, err = LoadCases(filepath.Join(dir, "missing"))
+	assert.NoError(t, err, "Missing directory not ignored")
+	assert.Empty(t, cases, "Test cases found in missing directory")
+}
+
+func TestLoadCases_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeCase(t, dir, "unknown.yaml", "expect:\n  code: 200\n")
+	_, err := LoadCases(dir)
+	assert.ErrorContains(t, err, "field code not found", "Unknown field not detected")
+
+	dir = t.TempDir()
+	writeCase(t, dir, "regex.yaml", "expect:\n  bodyMatches: '('\n")
+	_, err = LoadCases(dir)
+	assert.ErrorContains(t, err, "bodyMatches", "Invalid regular expression not detected")
+}
+
+func TestSelectURL(t *testing.T) {
+	urls := []string{"https://tenant/http/orders", "https://tenant/http/invoices"}
+
+	_, err := (&Case{}).SelectURL(urls)
+	assert.ErrorContains(t, err, "several service endpoints", "Ambiguous endpoint not detected")
+
+	url, err := (&Case{Endpoint: "/invoices"}).SelectURL(urls)
+	if err != nil {
+		t.Fatalf("SelectURL failed with error - %v", err)
+	}
+	assert.Equal(t, "https://tenant/http/invoices", url, "Incorrect URL")
+
+	_, err = (&Case{}).SelectURL(nil)
+	assert.ErrorContains(t, err, "no service endpoint found", "Missing endpoint not detected")
+}
+
+func TestExpectationCheck(t *testing.T) {
+	resp := &http.Response{StatusCode: 500, Header: http.Header{"Content-Type": []string{"text/plain"}}}
+	e := &Expectation{Headers: map[string]string{"Content-Type": "application/json"}, BodyContains: []string{"OK"}, BodyMatches: "^Error"}
+
+	err := e.check(resp, []byte("Error: mapping failed"))
+	assert.EqualError(t, err, `expected a 2xx status but got 500; expected header Content-Type = application/json but got text/plain; expected body to contain "OK"`, "Incorrect failures")
+
+	e = &Expectation{Status: 500, BodyMatches: "^Error"}
+	assert.NoError(t, e.check(resp, []byte("Error: mapping failed")), "Expected status not accepted")
+}
@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"os"
+	"strings"
+)
+
+// GetManifestId returns the artifact ID in Bundle-SymbolicName of MANIFEST.MF of the artifact directory
+func GetManifestId(artifactDir string) (string, error) {
+	headers, err := file.ReadManifest(fmt.Sprintf("%v/META-INF/MANIFEST.MF", artifactDir))
+	if err != nil {
+		return "", err
+	}
+	return file.ManifestId(headers), nil
+}
+
+// SetManifestId changes the artifact ID in Bundle-SymbolicName of MANIFEST.MF, keeping directives like singleton:=true
+func SetManifestId(manifestPath string, id string) error {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	newline := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.Split(string(content), newline)
+
+	const header = "Bundle-SymbolicName:"
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], header) {
+			continue
+		}
+		// Join the continuation lines, which start with a space
+		value := strings.TrimPrefix(lines[i], header)
+		end := i + 1
+		for end < len(lines) && strings.HasPrefix(lines[end], " ") {
+			value += lines[end][1:]
+			end++
+		}
+		value = strings.TrimSpace(value)
+		_, directives, found := strings.Cut(value, ";")
+		newValue := id
+		if found {
+			newValue += ";" + directives
+		}
+		wrapped := wrapManifestLine(header + " " + newValue)
+		lines = append(lines[:i], append(wrapped, lines[end:]...)...)
+		return os.WriteFile(manifestPath, []byte(strings.Join(lines, newline)), os.ModePerm)
+	}
+	return errors.Errorf("Bundle-SymbolicName not found in %v", manifestPath)
+}
+
+// wrapManifestLine splits a header into lines of the maximum width, with the continuation lines starting with a space
+func wrapManifestLine(line string) []string {
+	var lines []string
+	for len(line) > file.ManifestLineWidth {
+		lines = append(lines, line[:file.ManifestLineWidth])
+		line = " " + line[file.ManifestLineWidth:]
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,30 @@
+package sync
+
+import (
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSetManifestId(t *testing.T) {
+	manifestPath := t.TempDir() + "/MANIFEST.MF"
+	err := file.CopyFile("../../test/testdata/artifacts/create/Integration_Test_IFlow/META-INF/MANIFEST.MF", manifestPath)
+	if err != nil {
+		t.Fatalf("CopyFile failed with error - %v", err)
+	}
+
+	newId := "QA_Integration_Test_IFlow_With_A_Very_Long_Identifier_Exceeding_The_Line_Width"
+	err = SetManifestId(manifestPath, newId)
+	if err != nil {
+		t.Fatalf("SetManifestId failed with error - %v", err)
+	}
+
+	headers, err := file.ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("GetManifestHeaders failed with error - %v", err)
+	}
+	assert.Equal(t, newId, file.ManifestId(headers), "Incorrect artifact ID")
+	assert.Equal(t, "Integration Test IFlow", headers.Get("Bundle-Name"), "Other headers changed")
+	assert.True(t, strings.HasSuffix(strings.ReplaceAll(headers.Get("Bundle-SymbolicName"), " ", ""), ";singleton:=true"), "Directive not kept")
+}
@@ -0,0 +1,125 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusAdded     = "ADDED"
+	StatusCreated   = "CREATED"
+	StatusUpdated   = "UPDATED"
+	StatusUnchanged = "UNCHANGED"
+	StatusSkipped   = "SKIPPED"
+	StatusFailed    = "FAILED"
+	StatusNotRun    = "NOT_RUN"
+)
+
+// reportAction returns the action of the report for the status of an artifact
+func reportAction(status string) string {
+	switch status {
+	case StatusAdded, StatusCreated:
+		return report.ActionCreated
+	case StatusUpdated:
+		return report.ActionUpdated
+	case StatusUnchanged:
+		return report.ActionUnchanged
+	case StatusSkipped:
+		return report.ActionSkippedDraft
+	case StatusNotRun:
+		return report.ActionNotRun
+	default:
+		return report.ActionFailed
+	}
+}
+
+// ArtifactResult is the outcome of processing a single artifact
+type ArtifactResult struct {
+	ArtifactId string
+	Status     string
+	Err        error
+}
+
+// runParallel calls process for indexes 0 to count-1 on at most parallelism workers.
+// The results are returned in the order of the indexes regardless of completion order.
+// After the first failure, the remaining indexes that have not started are not processed, and their results
+// are returned by notRun instead.
+func runParallel(parallelism int, count int, process func(worker int, index int) *ArtifactResult, notRun func(index int) *ArtifactResult) []*ArtifactResult {
+	results := make([]*ArtifactResult, count)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	parallelism = min(parallelism, count)
+
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= count {
+					return
+				}
+				if failed.Load() {
+					continue
+				}
+				results[i] = process(worker, i)
+				if results[i].Err != nil {
+					failed.Store(true)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	for i, result := range results {
+		if result == nil {
+			results[i] = notRun(i)
+		}
+	}
+	return results
+}
+
+// notRunResult records the artifact that is not processed because an earlier artifact failed
+func (s *Synchroniser) notRunResult(artifactId string, artifactType string, packageId string) *ArtifactResult {
+	s.report.Add(&report.Entry{Id: artifactId, Type: artifactType, PackageId: packageId, Action: report.ActionNotRun}, time.Now(), nil)
+	return &ArtifactResult{ArtifactId: artifactId, Status: StatusNotRun}
+}
+
+// workerDir returns the working directory of a worker. Each worker has its own subdirectory
+// when processing in parallel so that downloads and uploads do not overwrite each other.
+func (s *Synchroniser) workerDir(workDir string, worker int) string {
+	if s.parallelism <= 1 {
+		return workDir
+	}
+	return fmt.Sprintf("%v/worker%d", workDir, worker)
+}
+
+// logResults logs a summary of the artifact results and returns the first error
+func logResults(results []*ArtifactResult) error {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msg("Summary of processed artifacts")
+	var firstErr error
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if result.Status == StatusNotRun {
+			log.Warn().Msgf("%-10v %v - not processed as an earlier artifact failed", result.Status, result.ArtifactId)
+		} else if result.Err != nil {
+			log.Error().Msgf("%-10v %v - %v", result.Status, result.ArtifactId, result.Err)
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+		} else {
+			log.Info().Msgf("%-10v %v", result.Status, result.ArtifactId)
+		}
+	}
+	return firstErr
+}
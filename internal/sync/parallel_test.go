@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallel_StableOrder(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	results := runParallel(3, 10, func(worker int, i int) *ArtifactResult {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if current <= m || maxRunning.CompareAndSwap(m, current) {
+				break
+			}
+		}
+		// Later artifacts complete first
+		time.Sleep(time.Duration(10-i) * time.Millisecond)
+		return &ArtifactResult{ArtifactId: fmt.Sprintf("artifact%d", i), Status: StatusUnchanged}
+	}, func(i int) *ArtifactResult {
+		t.Errorf("Artifact %d not processed", i)
+		return nil
+	})
+
+	assert.Len(t, results, 10)
+	for i, result := range results {
+		assert.Equal(t, fmt.Sprintf("artifact%d", i), result.ArtifactId, "Results not in stable order")
+	}
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3), "More workers than parallelism")
+}
+
+func TestRunParallel_StopAfterFailure(t *testing.T) {
+	s := New(nil)
+	rep := report.New("sync")
+	s.SetReport(rep)
+	results := runParallel(1, 3, func(worker int, i int) *ArtifactResult {
+		if i == 1 {
+			return &ArtifactResult{ArtifactId: "failed", Status: StatusFailed, Err: fmt.Errorf("failed")}
+		}
+		return &ArtifactResult{ArtifactId: fmt.Sprintf("artifact%d", i), Status: StatusUnchanged}
+	}, func(i int) *ArtifactResult {
+		return s.notRunResult(fmt.Sprintf("artifact%d", i), "Integration", "Package1")
+	})
+
+	assert.Equal(t, StatusUnchanged, results[0].Status)
+	assert.Equal(t, &ArtifactResult{ArtifactId: "artifact2", Status: StatusNotRun}, results[2], "Artifact after failure not recorded as not run")
+	if assert.Len(t, rep.Entries, 1, "Artifact not run missing in report") {
+		assert.Equal(t, "artifact2", rep.Entries[0].Id)
+		assert.Equal(t, report.ActionNotRun, rep.Entries[0].Action)
+	}
+	assert.EqualError(t, logResults(results), "failed")
+}
+
+func TestWorkerDir(t *testing.T) {
+	s := New(nil)
+	assert.Equal(t, "/tmp", s.workerDir("/tmp", 0))
+
+	s.SetParallelism(4)
+	assert.Equal(t, "/tmp/worker2", s.workerDir("/tmp", 2))
+}
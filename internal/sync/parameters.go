@@ -0,0 +1,224 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"github.com/magiconair/properties"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const secretPrefix = "secret:"
+
+var placeholderPattern = regexp.MustCompile(`\$\{([^}]+)}`)
+
+// Only names in the syntax of environment variables are placeholders, so that Camel expressions in
+// parameter values, e.g. ${header.name} or ${date:now:yyyyMMdd}, are kept for the runtime
+var envVarPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// Parameters are the configuration parameters of an artifact after applying the
+// environment overlay and resolving the placeholders
+type Parameters struct {
+	props   *properties.Properties
+	secrets map[string]bool
+	// Resolved is true if the content differs from the base parameters file
+	Resolved bool
+}
+
+// OverlayFile returns the path of the parameters.<environment>.prop overlay of the parameters file
+func OverlayFile(parametersFile string, environment string) string {
+	ext := filepath.Ext(parametersFile)
+	return fmt.Sprintf("%v.%v%v", strings.TrimSuffix(parametersFile, ext), environment, ext)
+}
+
+// LoadParameters reads the parameters file and merges the parameters.<environment>.prop overlay from
+// the same directory if it exists. Placeholders ${ENV_VAR} are resolved from other parameters or
+// environment variables, and ${secret:name} from environment variable FLASHPIPE_SECRET_<NAME>.
+// Other ${...} values, e.g. Camel expressions, are not changed.
+func LoadParameters(parametersFile string, environment string) (*Parameters, error) {
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	p := &Parameters{props: properties.NewProperties(), secrets: map[string]bool{}}
+	p.props.DisableExpansion = true
+
+	if file.Exists(parametersFile) {
+		log.Info().Msgf("Getting parameters from %v file", parametersFile)
+		base, err := loader.LoadFile(parametersFile)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		p.props.Merge(base)
+	}
+	if environment != "" {
+		overlayFile := OverlayFile(parametersFile, environment)
+		if file.Exists(overlayFile) {
+			log.Info().Msgf("Applying parameters for environment %v from %v file", environment, overlayFile)
+			overlay, err := loader.LoadFile(overlayFile)
+			if err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+			p.props.Merge(overlay)
+			p.Resolved = true
+		} else {
+			log.Info().Msgf("No parameters for environment %v as %v file does not exist", environment, overlayFile)
+		}
+	}
+
+	raw := p.props.Map()
+	for _, key := range p.props.Keys() {
+		value, secret, err := resolvePlaceholders(raw[key], raw, []string{key})
+		if err != nil {
+			return nil, fmt.Errorf("Parameter %v in %v: %w", key, parametersFile, err)
+		}
+		if value != raw[key] {
+			p.Resolved = true
+			_, _, err = p.props.Set(key, value)
+			if err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+		}
+		if secret {
+			p.secrets[key] = true
+		}
+	}
+	return p, nil
+}
+
+// resolvePlaceholders replaces the placeholders in value. It also returns whether a secret was used.
+func resolvePlaceholders(value string, raw map[string]string, keys []string) (string, bool, error) {
+	var resolveErr error
+	secret := false
+	resolved := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(name, secretPrefix) {
+			secretName := strings.TrimPrefix(name, secretPrefix)
+			envVar := SecretEnvVar(secretName)
+			secretValue, ok := os.LookupEnv(envVar)
+			if !ok {
+				resolveErr = fmt.Errorf("secret %v not found in environment variable %v", secretName, envVar)
+			}
+			secret = true
+			return secretValue
+		}
+		if other, ok := raw[name]; ok {
+			for _, key := range keys {
+				if key == name {
+					resolveErr = fmt.Errorf("circular reference to %v", name)
+					return ""
+				}
+			}
+			otherValue, otherSecret, err := resolvePlaceholders(other, raw, append(keys, name))
+			if err != nil {
+				resolveErr = err
+			}
+			secret = secret || otherSecret
+			return otherValue
+		}
+		if !envVarPattern.MatchString(name) {
+			return match
+		}
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			resolveErr = fmt.Errorf("environment variable %v is not set", name)
+		}
+		return envValue
+	})
+	if resolveErr != nil {
+		return "", false, resolveErr
+	}
+	return resolved, secret, nil
+}
+
+// SchedulesToCron converts the schedules in the parameters file from the XML form of the tenant to the
+// readable cron form. Schedules that cannot be expressed in the cron form, e.g. with calendar settings,
+// are kept in XML form.
+func SchedulesToCron(parametersFile string) error {
+	if !file.Exists(parametersFile) {
+		return nil
+	}
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadFile(parametersFile)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	props.DisableExpansion = true
+	converted := false
+	for _, key := range props.Keys() {
+		value := props.GetString(key, "")
+		if !api.IsScheduleXML(value) {
+			continue
+		}
+		schedule, err := api.ParseScheduleXML(value)
+		if err != nil {
+			log.Warn().Msgf("Schedule of parameter %v is kept in XML form as it cannot be parsed - %v", key, err)
+			continue
+		}
+		if schedule.HasExtraSettings() {
+			log.Warn().Msgf("Schedule of parameter %v is kept in XML form as it has settings not supported by the cron form", key)
+			continue
+		}
+		_, _, err = props.Set(key, schedule.Cron())
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		converted = true
+	}
+	if !converted {
+		return nil
+	}
+	f, err := os.Create(parametersFile)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer f.Close()
+	_, err = props.WriteComment(f, "#", properties.UTF8)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+// SecretEnvVar returns the environment variable containing the value of the secret
+func SecretEnvVar(name string) string {
+	envVar := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(name))
+	return "FLASHPIPE_SECRET_" + envVar
+}
+
+func (p *Parameters) Get(key string) string {
+	return p.props.GetString(key, "")
+}
+
+// Display returns the value of the parameter for logging, with secrets masked
+func (p *Parameters) Display(key string) string {
+	if p.secrets[key] {
+		return "********"
+	}
+	return p.Get(key)
+}
+
+// Write stores the parameters in a file, typically parameters.prop of the artifact to be uploaded
+func (p *Parameters) Write(filePath string) error {
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer f.Close()
+	_, err = p.props.Write(f, properties.UTF8)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package sync
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeParametersFile(t *testing.T, path string, content string) {
+	err := os.WriteFile(path, []byte(content), os.ModePerm)
+	if err != nil {
+		t.Fatalf("File creation failed with error - %v", err)
+	}
+}
+
+func TestOverlayFile(t *testing.T) {
+	assert.Equal(t, "src/main/resources/parameters.QA.prop", OverlayFile("src/main/resources/parameters.prop", "QA"))
+}
+
+func TestLoadParameters_Overlay(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "Sender\\ Endpoint=/flow\nReceiver\\ Host=${HOST}\nTimeout=60000\n")
+	writeParametersFile(t, filepath.Join(dir, "parameters.QA.prop"), "Sender\\ Endpoint=/flow_qa\nPassword=${secret:qa-password}\n")
+	t.Setenv("HOST", "qa.example.com")
+	t.Setenv("FLASHPIPE_SECRET_QA_PASSWORD", "s3cret")
+
+	params, err := LoadParameters(parametersFile, "QA")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	assert.True(t, params.Resolved)
+	assert.Equal(t, "/flow_qa", params.Get("Sender Endpoint"))
+	assert.Equal(t, "qa.example.com", params.Get("Receiver Host"))
+	assert.Equal(t, "60000", params.Get("Timeout"))
+	assert.Equal(t, "s3cret", params.Get("Password"))
+	assert.Equal(t, "********", params.Display("Password"), "Secret is not masked")
+	assert.Equal(t, "/flow_qa", params.Display("Sender Endpoint"))
+}
+
+func TestLoadParameters_NoOverlay(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "Timeout=60000\nReference=${Timeout}\n")
+
+	params, err := LoadParameters(parametersFile, "PRD")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	assert.True(t, params.Resolved)
+	assert.Equal(t, "60000", params.Get("Reference"))
+
+	params, err = LoadParameters(filepath.Join(dir, "missing.prop"), "")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	assert.False(t, params.Resolved)
+	assert.Empty(t, params.Get("Timeout"))
+}
+
+func TestLoadParameters_MissingEnvironmentVariable(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "Host=${FLASHPIPE_TEST_UNDEFINED_VARIABLE}\n")
+
+	_, err := LoadParameters(parametersFile, "")
+	assert.ErrorContains(t, err, "environment variable FLASHPIPE_TEST_UNDEFINED_VARIABLE is not set")
+}
+
+func TestLoadParameters_CamelExpression(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "Receiver=${header.Receiver}\nApplication=${property.SAP_ApplicationID}\nFile=order_${date:now:yyyyMMdd}_${HOST}.xml\n")
+	t.Setenv("HOST", "dev")
+
+	params, err := LoadParameters(parametersFile, "")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	assert.Equal(t, "${header.Receiver}", params.Get("Receiver"), "Camel expression changed")
+	assert.Equal(t, "${property.SAP_ApplicationID}", params.Get("Application"), "Camel expression changed")
+	assert.Equal(t, "order_${date:now:yyyyMMdd}_dev.xml", params.Get("File"), "Environment variable not resolved next to Camel expression")
+}
+
+func TestLoadParameters_CircularReference(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "A=${B}\nB=${A}\n")
+
+	_, err := LoadParameters(parametersFile, "")
+	assert.ErrorContains(t, err, "circular reference")
+}
+
+func TestParameters_Write(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	writeParametersFile(t, parametersFile, "Host=${HOST}\n")
+	t.Setenv("HOST", "dev.example.com")
+
+	params, err := LoadParameters(parametersFile, "")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	outputFile := filepath.Join(dir, "upload", "parameters.prop")
+	err = params.Write(outputFile)
+	if err != nil {
+		t.Fatalf("Write failed with error - %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("ReadFile failed with error - %v", err)
+	}
+	assert.Equal(t, "Host = dev.example.com\n", string(content))
+}
+
+func TestSchedulesToCron(t *testing.T) {
+	dir := t.TempDir()
+	parametersFile := filepath.Join(dir, "parameters.prop")
+	schedule := "<row><cell>second</cell><cell>0</cell></row><row><cell>minute</cell><cell>0/15</cell></row>" +
+		"<row><cell>hour</cell><cell>0-23</cell></row><row><cell>day_of_month</cell><cell>?</cell></row>" +
+		"<row><cell>month</cell><cell>*</cell></row><row><cell>dayOfWeek</cell><cell>*</cell></row><row><cell>year</cell><cell>*</cell></row>"
+	calendarSchedule := schedule + "<row><cell>calendar</cell><cell>Holidays_DE</cell></row>"
+	writeParametersFile(t, parametersFile, "Timer="+schedule+"\nHolidayTimer="+calendarSchedule+"\nReceiver=${header.Receiver}\n")
+
+	err := SchedulesToCron(parametersFile)
+	if err != nil {
+		t.Fatalf("SchedulesToCron failed with error - %v", err)
+	}
+	params, err := LoadParameters(parametersFile, "")
+	if err != nil {
+		t.Fatalf("LoadParameters failed with error - %v", err)
+	}
+	assert.Equal(t, "0 0/15 0-23 ? * * *", params.Get("Timer"), "Schedule not converted to cron form")
+	assert.Equal(t, calendarSchedule, params.Get("HolidayTimer"), "Schedule with calendar settings converted")
+	assert.Equal(t, "${header.Receiver}", params.Get("Receiver"), "Other parameter changed")
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	ActionCreate          = "CREATE"
+	ActionUpdate          = "UPDATE"
+	ActionUndeploy        = "UNDEPLOY"
+	ActionUpdateParameter = "UPDATE_PARAMETER"
+	ActionDelete          = "DELETE"
+)
+
+// PlanAction is a single change that would be made to the tenant
+type PlanAction struct {
+	Action       string `json:"action"`
+	ArtifactId   string `json:"artifactId"`
+	ArtifactType string `json:"artifactType"`
+	PackageId    string `json:"packageId"`
+	Parameter    string `json:"parameter,omitempty"`
+	OldValue     string `json:"oldValue,omitempty"`
+	NewValue     string `json:"newValue,omitempty"`
+	// Files lists the changed files of an updated artifact
+	Files []string `json:"files,omitempty"`
+}
+
+// Plan collects the actions of a dry run instead of executing them
+type Plan struct {
+	Actions []*PlanAction `json:"actions"`
+	mu      sync.Mutex
+}
+
+// NewPlan returns an initialised Plan instance.
+func NewPlan() *Plan {
+	return &Plan{Actions: []*PlanAction{}}
+}
+
+func (p *Plan) Add(action *PlanAction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Actions = append(p.Actions, action)
+}
+
+func (p *Plan) Contains(action string, artifactId string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, a := range p.Actions {
+		if a.Action == action && a.ArtifactId == artifactId {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) Print(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", content)
+		return err
+	case "table":
+		if len(p.Actions) == 0 {
+			_, err := fmt.Fprintln(w, "No changes. Tenant is up to date.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ACTION\tTYPE\tARTIFACT\tPACKAGE\tDETAILS")
+		for _, a := range p.Actions {
+			details := ""
+			if a.Action == ActionUpdateParameter {
+				details = fmt.Sprintf("%v: %v -> %v", a.Parameter, a.OldValue, a.NewValue)
+			} else if len(a.Files) > 0 {
+				details = strings.Join(a.Files, ", ")
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", a.Action, a.ArtifactType, a.ArtifactId, a.PackageId, details)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("invalid plan format %v", format)
+	}
+}
@@ -0,0 +1,40 @@
+package sync
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlan_PrintTable(t *testing.T) {
+	plan := NewPlan()
+	plan.Add(&PlanAction{Action: ActionUpdate, ArtifactId: "DummyIFlow", ArtifactType: "Integration", PackageId: "DummyPackage"})
+	plan.Add(&PlanAction{Action: ActionUpdateParameter, ArtifactId: "DummyIFlow", ArtifactType: "Integration", PackageId: "DummyPackage", Parameter: "Sender Endpoint", OldValue: "/flow", NewValue: "/flow_update"})
+
+	buf := new(bytes.Buffer)
+	err := plan.Print(buf, "table")
+	if err != nil {
+		t.Fatalf("Print failed with error - %v", err)
+	}
+	assert.Contains(t, buf.String(), "UPDATE_PARAMETER", "Missing UPDATE_PARAMETER action")
+	assert.Contains(t, buf.String(), "Sender Endpoint: /flow -> /flow_update", "Missing parameter change details")
+}
+
+func TestPlan_PrintJSON(t *testing.T) {
+	plan := NewPlan()
+	plan.Add(&PlanAction{Action: ActionCreate, ArtifactId: "DummyIFlow", ArtifactType: "Integration", PackageId: "DummyPackage"})
+
+	buf := new(bytes.Buffer)
+	err := plan.Print(buf, "json")
+	if err != nil {
+		t.Fatalf("Print failed with error - %v", err)
+	}
+	assert.Contains(t, buf.String(), `"action": "CREATE"`, "Missing CREATE action")
+	assert.True(t, plan.Contains(ActionCreate, "DummyIFlow"), "Plan does not contain CREATE action for DummyIFlow")
+}
+
+func TestPlan_PrintInvalidFormat(t *testing.T) {
+	err := NewPlan().Print(new(bytes.Buffer), "yaml")
+
+	assert.Equal(t, "invalid plan format yaml", err.Error(), "Incorrect error message")
+}
@@ -0,0 +1,173 @@
+package sync
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+	"slices"
+)
+
+// inScope returns true if the ID is not filtered out by the included or excluded IDs
+func inScope(id string, includedIds []string, excludedIds []string) bool {
+	if len(includedIds) > 0 && !slices.Contains(includedIds, id) {
+		return false
+	}
+	return !slices.Contains(excludedIds, id)
+}
+
+// logPruneSummary lists the orphans that will be removed before any of them is removed
+func logPruneSummary(target string, orphans []string) {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	if len(orphans) == 0 {
+		log.Info().Msgf("🏆 No orphaned artifacts to prune from %v", target)
+		return
+	}
+	log.Warn().Msgf("The following %d artifact(s) no longer exist in the source and will be pruned from %v", len(orphans), target)
+	for _, id := range orphans {
+		log.Warn().Msgf("  - %v", id)
+	}
+}
+
+// pruneGit removes the artifact directories of artifacts that no longer exist in the package on the tenant
+func (s *Synchroniser) pruneGit(tenantArtifacts []*api.ArtifactDetails, artifactsDir string, includedIds []string, excludedIds []string) error {
+	dirs, err := getArtifactDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+	var orphans []*artifactDir
+	var orphanIds []string
+	for _, dir := range dirs {
+		if inScope(dir.id, includedIds, excludedIds) && api.FindArtifactById(dir.id, tenantArtifacts) == nil {
+			orphans = append(orphans, dir)
+			orphanIds = append(orphanIds, dir.id)
+		}
+	}
+	logPruneSummary("Git", orphanIds)
+	for _, orphan := range orphans {
+		log.Info().Msgf("🗑️ Removing directory %v of artifact %v", orphan.dir, orphan.id)
+		err = os.RemoveAll(orphan.dir)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// pruneTenant deletes the designtime artifacts of the package that no longer exist in Git
+func (s *Synchroniser) pruneTenant(packageId string, artifactsDir string, includedIds []string, excludedIds []string) error {
+	dirs, err := getArtifactDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+	tenantArtifacts, err := s.ip.GetAllArtifacts(packageId)
+	if err != nil {
+		return err
+	}
+	var orphans []*api.ArtifactDetails
+	var orphanIds []string
+	for _, artifact := range tenantArtifacts {
+		inGit := slices.ContainsFunc(dirs, func(dir *artifactDir) bool { return dir.id == artifact.Id })
+		if !inGit && inScope(artifact.Id, includedIds, excludedIds) {
+			orphans = append(orphans, artifact)
+			orphanIds = append(orphanIds, artifact.Id)
+		}
+	}
+	logPruneSummary("tenant", orphanIds)
+
+	r := api.NewRuntime(s.exe)
+	if s.pruneUndeploy && s.plan == nil && len(orphans) > 0 {
+		// Artifacts are only deleted after they are removed from the runtime
+		err = UndeployArtifacts(orphanIds, s.delayLength, s.maxCheckLimit, r)
+		if err != nil {
+			return err
+		}
+	}
+	for _, orphan := range orphans {
+		if s.pruneUndeploy && s.plan != nil {
+			version, _, err := r.Get(orphan.Id)
+			if err != nil {
+				return err
+			}
+			if version != "NOT_DEPLOYED" {
+				s.plan.Add(&PlanAction{Action: ActionUndeploy, ArtifactId: orphan.Id, ArtifactType: orphan.ArtifactType, PackageId: packageId})
+			}
+		}
+		if s.plan != nil {
+			s.plan.Add(&PlanAction{Action: ActionDelete, ArtifactId: orphan.Id, ArtifactType: orphan.ArtifactType, PackageId: packageId})
+			continue
+		}
+		log.Info().Msgf("🗑️ Deleting artifact %v from tenant", orphan.Id)
+		err = s.newDesigntimeArtifact(orphan.ArtifactType).Delete(orphan.Id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneAPIProxiesInGit removes the directories of APIProxies that no longer exist on the tenant
+func pruneAPIProxiesInGit(tenantProxies []*api.APIProxyMetadata, artifactsDir string, includedIds []string, excludedIds []string) error {
+	dirs, err := getAPIProxyDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+	var orphans []string
+	for _, name := range dirs {
+		onTenant := slices.ContainsFunc(tenantProxies, func(proxy *api.APIProxyMetadata) bool { return proxy.Name == name })
+		if !onTenant && inScope(name, includedIds, excludedIds) {
+			orphans = append(orphans, name)
+		}
+	}
+	logPruneSummary("Git", orphans)
+	for _, name := range orphans {
+		log.Info().Msgf("🗑️ Removing directory of APIProxy %v", name)
+		err = os.RemoveAll(artifactsDir + "/" + name)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// pruneAPIProxiesInTenant deletes the APIProxies that no longer exist in Git
+func pruneAPIProxiesInTenant(proxy *api.APIProxy, artifactsDir string, includedIds []string, excludedIds []string) error {
+	dirs, err := getAPIProxyDirs(artifactsDir)
+	if err != nil {
+		return err
+	}
+	tenantProxies, err := proxy.List()
+	if err != nil {
+		return err
+	}
+	var orphans []string
+	for _, tenantProxy := range tenantProxies {
+		if !slices.Contains(dirs, tenantProxy.Name) && inScope(tenantProxy.Name, includedIds, excludedIds) {
+			orphans = append(orphans, tenantProxy.Name)
+		}
+	}
+	logPruneSummary("tenant", orphans)
+	for _, name := range orphans {
+		err = proxy.Delete(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAPIProxyDirs returns the names of the APIProxy directories in artifactsDir
+func getAPIProxyDirs(artifactsDir string) ([]string, error) {
+	entries, err := os.ReadDir(artifactsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && file.Exists(artifactsDir+"/"+entry.Name()+"/manifest.json") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInScope(t *testing.T) {
+	assert.True(t, inScope("IFlow1", nil, nil), "ID without filter not in scope")
+	assert.True(t, inScope("IFlow1", []string{"IFlow1"}, nil), "Included ID not in scope")
+	assert.False(t, inScope("IFlow2", []string{"IFlow1"}, nil), "Not included ID in scope")
+	assert.False(t, inScope("IFlow1", nil, []string{"IFlow1"}), "Excluded ID in scope")
+}
+
+func TestPruneGit(t *testing.T) {
+	artifactsDir := t.TempDir()
+	err := file.ReplaceDir("../../test/testdata/artifacts/create", artifactsDir)
+	if err != nil {
+		t.Fatalf("ReplaceDir failed with error - %v", err)
+	}
+
+	s := New(nil)
+	tenantArtifacts := []*api.ArtifactDetails{{Id: "Integration_Test_IFlow"}}
+	err = s.pruneGit(tenantArtifacts, artifactsDir, nil, []string{"Integration_Test_Value_Mapping"})
+	if err != nil {
+		t.Fatalf("pruneGit failed with error - %v", err)
+	}
+
+	ids, err := GetArtifactDirIds(artifactsDir)
+	if err != nil {
+		t.Fatalf("GetArtifactDirIds failed with error - %v", err)
+	}
+	assert.Equal(t, []string{"Integration_Test_IFlow", "Integration_Test_Value_Mapping"}, ids, "Orphaned artifacts not pruned")
+}
+
+func TestPruneTenant_Undeploy(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "user", "password", host, "http", port, true)
+
+	packageData, err := api.GetPackageDetails("../../test/testdata/FlashPipeIntegrationTest.json")
+	if err != nil {
+		t.Fatalf("GetPackageDetails failed with error - %v", err)
+	}
+	err = api.NewIntegrationPackage(exe).Create(packageData)
+	if err != nil {
+		t.Fatalf("Create package failed with error - %v", err)
+	}
+	dt := api.NewDesigntimeArtifact("Integration", exe)
+	err = dt.Create("Integration_Test_IFlow", "Integration Test IFlow", "FlashPipeIntegrationTest", "../../test/testdata/artifacts/create/Integration_Test_IFlow")
+	if err != nil {
+		t.Fatalf("Create artifact failed with error - %v", err)
+	}
+	err = dt.Deploy("Integration_Test_IFlow")
+	if err != nil {
+		t.Fatalf("Deploy failed with error - %v", err)
+	}
+
+	// The artifact is deleted only after it is removed from the runtime
+	synchroniser := New(exe)
+	synchroniser.SetPrune(true, true)
+	synchroniser.SetUndeploymentCheck(0, 2)
+	err = synchroniser.pruneTenant("FlashPipeIntegrationTest", t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("pruneTenant failed with error - %v", err)
+	}
+
+	version, _, err := api.NewRuntime(exe).Get("Integration_Test_IFlow")
+	if err != nil {
+		t.Fatalf("Get runtime artifact failed with error - %v", err)
+	}
+	assert.Equal(t, "NOT_DEPLOYED", version, "Pruned artifact still deployed")
+	_, _, exists, err := dt.Get("Integration_Test_IFlow", "active")
+	if err != nil {
+		t.Fatalf("Get artifact failed with error - %v", err)
+	}
+	assert.False(t, exists, "Pruned artifact not deleted")
+}
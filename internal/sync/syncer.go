@@ -5,15 +5,20 @@ import (
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 type Syncer interface {
 	Exec(workDir string, artifactsDir string, includedIds []string, excludedIds []string) error
+	SetPrune(prune bool)
+	SetReport(r *report.Report)
 }
 
 func NewSyncer(target string, functionType string, exe *httpclnt.HTTPExecuter) Syncer {
@@ -36,7 +41,9 @@ func NewSyncer(target string, functionType string, exe *httpclnt.HTTPExecuter) S
 }
 
 type APIMGitSynchroniser struct {
-	exe *httpclnt.HTTPExecuter
+	exe    *httpclnt.HTTPExecuter
+	prune  bool
+	report *report.Report
 }
 
 // NewAPIMGitSynchroniser returns an initialised APIMGitSynchroniser instance.
@@ -46,6 +53,16 @@ func NewAPIMGitSynchroniser(exe *httpclnt.HTTPExecuter) Syncer {
 	return s
 }
 
+// SetPrune removes the directories of APIProxies that no longer exist on the tenant
+func (s *APIMGitSynchroniser) SetPrune(prune bool) {
+	s.prune = prune
+}
+
+// SetReport records the outcome of each processed APIProxy in r
+func (s *APIMGitSynchroniser) SetReport(r *report.Report) {
+	s.report = r
+}
+
 func (s *APIMGitSynchroniser) Exec(workDir string, artifactsDir string, includedIds []string, excludedIds []string) error {
 	log.Info().Msg("Sync APIM content to Git")
 
@@ -73,47 +90,67 @@ func (s *APIMGitSynchroniser) Exec(workDir string, artifactsDir string, included
 			continue
 		}
 
-		// Download artifact content
-		err = proxy.Download(artifact.Name, targetRootDir)
+		start := time.Now()
+		action, err := proxyToGit(proxy, artifact.Name, targetRootDir, artifactsDir)
+		s.report.Add(&report.Entry{Id: artifact.Name, Type: "APIProxy", Action: action}, start, err)
 		if err != nil {
 			return err
 		}
+	}
 
-		// Compare content and update Git if required
-		gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, artifact.Name)
-		downloadedArtifactPath := fmt.Sprintf("%v/%v", targetRootDir, artifact.Name)
-		if file.Exists(fmt.Sprintf("%v/manifest.json", gitArtifactPath)) {
-			// (1) If artifact already exists in Git, then compare and update
-			log.Info().Msg("Comparing content from tenant against Git")
-			dirDiffer := file.DiffDirectories(downloadedArtifactPath, gitArtifactPath)
-
-			if dirDiffer {
-				log.Info().Msg("🏆 Changes detected and will be updated to Git")
-				// Update the changes into the Git directory
-				err := file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
-				if err != nil {
-					return err
-				}
-			} else {
-				log.Info().Msg("🏆 No changes detected. Update to Git not required")
-			}
-		} else { // (2) If artifact does not exist in Git, then add it
-			log.Info().Msgf("🏆 APIProxy %v does not exist, and will be added to Git", artifact.Name)
-			err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
-			if err != nil {
-				return err
-			}
+	if s.prune {
+		err = pruneAPIProxiesInGit(artifacts, artifactsDir, includedIds, excludedIds)
+		if err != nil {
+			return err
 		}
 	}
-
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProxies")
 
 	return nil
 }
 
+// proxyToGit downloads the APIProxy and updates it in Git if it differs, and returns the action taken
+func proxyToGit(proxy *api.APIProxy, name string, targetRootDir string, artifactsDir string) (string, error) {
+	// Download artifact content
+	err := proxy.Download(name, targetRootDir)
+	if err != nil {
+		return "", err
+	}
+
+	// Compare content and update Git if required
+	gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, name)
+	downloadedArtifactPath := fmt.Sprintf("%v/%v", targetRootDir, name)
+	if file.Exists(fmt.Sprintf("%v/manifest.json", gitArtifactPath)) {
+		// (1) If artifact already exists in Git, then compare and update
+		log.Info().Msg("Comparing content from tenant against Git")
+		result := file.DiffDirectories(downloadedArtifactPath, gitArtifactPath)
+
+		if result.HasChanges() {
+			log.Info().Msgf("🏆 Changes detected and will be updated to Git: %v", strings.Join(result.ChangedFiles(), ", "))
+			// Update the changes into the Git directory
+			err := file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
+			if err != nil {
+				return "", err
+			}
+			return report.ActionUpdated, nil
+		}
+		log.Info().Msg("🏆 No changes detected. Update to Git not required")
+		return report.ActionUnchanged, nil
+	}
+	// (2) If artifact does not exist in Git, then add it
+	log.Info().Msgf("🏆 APIProxy %v does not exist, and will be added to Git", name)
+	err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
+	if err != nil {
+		return "", err
+	}
+	return report.ActionCreated, nil
+}
+
 type APIMTenantSynchroniser struct {
-	exe *httpclnt.HTTPExecuter
+	exe    *httpclnt.HTTPExecuter
+	prune  bool
+	report *report.Report
 }
 
 // NewAPIMTenantSynchroniser returns an initialised APIMTenantSynchroniser instance.
@@ -123,6 +160,16 @@ func NewAPIMTenantSynchroniser(exe *httpclnt.HTTPExecuter) Syncer {
 	return s
 }
 
+// SetPrune deletes the APIProxies that no longer exist in Git from the tenant
+func (s *APIMTenantSynchroniser) SetPrune(prune bool) {
+	s.prune = prune
+}
+
+// SetReport records the outcome of each processed APIProxy in r
+func (s *APIMTenantSynchroniser) SetReport(r *report.Report) {
+	s.report = r
+}
+
 func (s *APIMTenantSynchroniser) Exec(workDir string, artifactsDir string, includedIds []string, excludedIds []string) error {
 	// Get directory list
 	baseSourceDir := filepath.Clean(artifactsDir)
@@ -162,48 +209,64 @@ func (s *APIMTenantSynchroniser) Exec(workDir string, artifactsDir string, inclu
 			}
 
 			log.Info().Msgf("📢 Begin processing for APIProxy %v", artifactId)
-			proxyExists, err := proxy.Get(artifactId)
+			start := time.Now()
+			action, err := proxyToTenant(proxy, artifactId, gitArtifactDir, uploadWorkDir, downloadWorkDir)
+			s.report.Add(&report.Entry{Id: artifactId, Type: "APIProxy", Action: action}, start, err)
 			if err != nil {
 				return err
 			}
-			if !proxyExists {
-				log.Info().Msgf("APIProxy %v will be created", artifactId)
-
-				err = proxy.Upload(gitArtifactDir, uploadWorkDir)
-				if err != nil {
-					return err
-				}
-
-				log.Info().Msg("🏆 APIProxy created successfully")
-			} else {
-				log.Info().Msg("Checking if APIProxy needs to be updated")
-
-				err = proxy.Download(artifactId, downloadWorkDir)
-				if err != nil {
-					return err
-				}
-
-				log.Info().Msg("Comparing content from tenant against Git")
-				downloadArtifactDir := fmt.Sprintf("%v/%v", downloadWorkDir, artifactId)
-				dirDiffer := file.DiffDirectories(downloadArtifactDir, gitArtifactDir)
-				if dirDiffer == true {
-					log.Info().Msg("Changes found in APIProxy. APIProxy will be updated in tenant")
-
-					err = proxy.Upload(gitArtifactDir, uploadWorkDir)
-					if err != nil {
-						return err
-					}
-					log.Info().Msg("🏆 APIProxy updated successfully")
-				} else {
-					log.Info().Msg("🏆 No changes detected. APIProxy does not need to be updated")
-				}
-			}
 		}
 	}
 	if !artifactDirFound {
 		log.Warn().Msgf("No directory with APIProxy contents found in %v", baseSourceDir)
+	} else if s.prune {
+		err = pruneAPIProxiesInTenant(proxy, baseSourceDir, includedIds, excludedIds)
+		if err != nil {
+			return err
+		}
 	}
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProxies")
 	return nil
 }
+
+// proxyToTenant creates the APIProxy in the tenant or updates it if it differs, and returns the action taken
+func proxyToTenant(proxy *api.APIProxy, artifactId string, gitArtifactDir string, uploadWorkDir string, downloadWorkDir string) (string, error) {
+	proxyExists, err := proxy.Get(artifactId)
+	if err != nil {
+		return "", err
+	}
+	if !proxyExists {
+		log.Info().Msgf("APIProxy %v will be created", artifactId)
+
+		err = proxy.Upload(gitArtifactDir, uploadWorkDir)
+		if err != nil {
+			return "", err
+		}
+
+		log.Info().Msg("🏆 APIProxy created successfully")
+		return report.ActionCreated, nil
+	}
+	log.Info().Msg("Checking if APIProxy needs to be updated")
+
+	err = proxy.Download(artifactId, downloadWorkDir)
+	if err != nil {
+		return "", err
+	}
+
+	log.Info().Msg("Comparing content from tenant against Git")
+	downloadArtifactDir := fmt.Sprintf("%v/%v", downloadWorkDir, artifactId)
+	result := file.DiffDirectories(downloadArtifactDir, gitArtifactDir)
+	if result.HasChanges() {
+		log.Info().Msgf("Changes found in APIProxy. APIProxy will be updated in tenant: %v", strings.Join(result.ChangedFiles(), ", "))
+
+		err = proxy.Upload(gitArtifactDir, uploadWorkDir)
+		if err != nil {
+			return "", err
+		}
+		log.Info().Msg("🏆 APIProxy updated successfully")
+		return report.ActionUpdated, nil
+	}
+	log.Info().Msg("🏆 No changes detected. APIProxy does not need to be updated")
+	return report.ActionUnchanged, nil
+}
@@ -1,35 +1,99 @@
 package sync
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/dependency"
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/go-errors/errors"
-	"github.com/magiconair/properties"
 	"github.com/rs/zerolog/log"
-	"net/textproto"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 )
 
 type Synchroniser struct {
-	exe *httpclnt.HTTPExecuter
-	ip  *api.IntegrationPackage
+	exe           *httpclnt.HTTPExecuter
+	ip            *api.IntegrationPackage
+	plan          *Plan
+	ignoreDiagram bool
+	parallelism   int
+	environment   string
+	prune         bool
+	pruneUndeploy bool
+	delayLength   int
+	maxCheckLimit int
+	report        *report.Report
+	changes       *repo.Changes
 }
 
 func New(exe *httpclnt.HTTPExecuter) *Synchroniser {
 	s := new(Synchroniser)
 	s.exe = exe
 	s.ip = api.NewIntegrationPackage(exe)
+	s.parallelism = 1
+	s.delayLength = 30
+	s.maxCheckLimit = 10
 	return s
 }
 
+// SetDryRun records the changes to the tenant in plan instead of executing them
+func (s *Synchroniser) SetDryRun(plan *Plan) {
+	s.plan = plan
+}
+
+// SetIgnoreDiagram ignores changes to the diagram layout of IFlows when comparing artifact contents
+func (s *Synchroniser) SetIgnoreDiagram(ignoreDiagram bool) {
+	s.ignoreDiagram = ignoreDiagram
+}
+
+// SetParallelism sets the number of artifacts that are processed concurrently
+func (s *Synchroniser) SetParallelism(parallelism int) {
+	s.parallelism = parallelism
+}
+
+// SetEnvironment applies the parameters.<environment>.prop overlay to the parameters of the artifacts
+func (s *Synchroniser) SetEnvironment(environment string) {
+	s.environment = environment
+}
+
+// SetPrune removes artifacts from the target that no longer exist in the source, optionally undeploying them first
+func (s *Synchroniser) SetPrune(prune bool, undeploy bool) {
+	s.prune = prune
+	s.pruneUndeploy = undeploy
+}
+
+// SetUndeploymentCheck sets how often the runtime status is checked until the artifacts pruned with undeploy are undeployed
+func (s *Synchroniser) SetUndeploymentCheck(delayLength int, maxCheckLimit int) {
+	s.delayLength = delayLength
+	s.maxCheckLimit = maxCheckLimit
+}
+
+// SetReport records the outcome of each processed artifact in r
+func (s *Synchroniser) SetReport(r *report.Report) {
+	s.report = r
+}
+
+// SetGitChanges records the artifacts that are added or updated in Git in changes
+func (s *Synchroniser) SetGitChanges(changes *repo.Changes) {
+	s.changes = changes
+}
+
+func (s *Synchroniser) newDesigntimeArtifact(artifactType string) api.DesigntimeArtifact {
+	dt := api.NewDesigntimeArtifact(artifactType, s.exe)
+	if integration, ok := dt.(*api.Integration); ok {
+		integration.SetIgnoreDiagram(s.ignoreDiagram)
+	}
+	return dt
+}
+
 func (s *Synchroniser) PackageToGit(packageDataFromTenant *api.PackageSingleData, packageId string, workDir string, artifactsDir string) error {
 	// Create temp directory in working dir
 	err := os.MkdirAll(workDir+"/from_tenant", os.ModePerm)
@@ -109,104 +173,216 @@ func (s *Synchroniser) ArtifactsToGit(packageId string, workDir string, artifact
 		return err
 	}
 
-	// Create temp directories in working dir
-	err = os.MkdirAll(workDir+"/download", os.ModePerm)
+	filtered, err := filterArtifacts(artifacts, includedIds, excludedIds)
 	if err != nil {
-		return errors.Wrap(err, 0)
+		return err
 	}
 
-	filtered, err := filterArtifacts(artifacts, includedIds, excludedIds)
+	var gitArtifacts []*gitArtifact
+	for _, artifact := range filtered {
+		gitArtifacts = append(gitArtifacts, &gitArtifact{packageId: packageId, details: artifact, artifactsDir: artifactsDir})
+	}
+	results, err := s.artifactsToGit(gitArtifacts, workDir, draftHandling, dirNamingType, scriptCollectionMap)
+	if err != nil {
+		return err
+	}
+	err = logResults(results)
 	if err != nil {
 		return err
 	}
+	if s.prune {
+		err = s.pruneGit(artifacts, artifactsDir, includedIds, excludedIds)
+		if err != nil {
+			return err
+		}
+	}
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Completed processing of artifacts in integration package %v", packageId)
+	return nil
+}
 
-	// Process through the artifacts
-	for _, artifact := range filtered {
+// PackagesToGit syncs the artifacts of the packages to a subdirectory per package in artifactsBaseDir. The artifacts
+// of all packages are processed together, so that the workers are also used across packages with few artifacts.
+func (s *Synchroniser) PackagesToGit(packageIds []string, workDir string, artifactsBaseDir string, draftHandling string, syncPackageLevelDetails bool) error {
+	var gitArtifacts []*gitArtifact
+	for i, id := range packageIds {
 		log.Info().Msg("---------------------------------------------------------------------------------")
-		log.Info().Msgf("📢 Begin processing for artifact %v", artifact.Id)
-		// Check if artifact is in draft version
-		if artifact.IsDraft {
-			switch draftHandling {
-			case "SKIP":
-				log.Warn().Msgf("Artifact %v is in draft version, and will be skipped", artifact.Id)
-				continue
-			case "ADD":
-				log.Info().Msgf("Artifact %v is in draft version, and will be added", artifact.Id)
-			case "ERROR":
-				return fmt.Errorf("Artifact %v is in draft version. Save Version in Web UI first!", artifact.Id)
+		log.Info().Msgf("Processing package %d/%d - ID: %v", i+1, len(packageIds), id)
+		packageDataFromTenant, readOnly, _, err := s.VerifyDownloadablePackage(id)
+		if err != nil {
+			return err
+		}
+		if readOnly {
+			continue
+		}
+		packageArtifactsDir := fmt.Sprintf("%v/%v", artifactsBaseDir, id)
+		if syncPackageLevelDetails {
+			err = s.PackageToGit(packageDataFromTenant, id, fmt.Sprintf("%v/%v", workDir, id), packageArtifactsDir)
+			if err != nil {
+				return err
 			}
 		}
-		// Download artifact content
-		dt := api.NewDesigntimeArtifact(artifact.ArtifactType, s.exe)
-		targetDownloadFile := fmt.Sprintf("%v/download/%v.zip", workDir, artifact.Id)
-		err = dt.Download(targetDownloadFile, artifact.Id)
+		log.Info().Msgf("Getting artifacts in integration package %v", id)
+		artifacts, err := s.ip.GetAllArtifacts(id)
 		if err != nil {
 			return err
 		}
+		for _, artifact := range artifacts {
+			gitArtifacts = append(gitArtifacts, &gitArtifact{packageId: id, details: artifact, artifactsDir: packageArtifactsDir})
+		}
+	}
+
+	results, err := s.artifactsToGit(gitArtifacts, workDir, draftHandling, "ID", nil)
+	if err != nil {
+		return err
+	}
+	return logResults(results)
+}
+
+// gitArtifact is an artifact of a package on the tenant that is synced to the artifacts directory of the package
+type gitArtifact struct {
+	packageId    string
+	details      *api.ArtifactDetails
+	artifactsDir string
+}
+
+// artifactsToGit syncs the artifacts to Git on the workers of the synchroniser, and returns the result of each artifact
+func (s *Synchroniser) artifactsToGit(artifacts []*gitArtifact, workDir string, draftHandling string, dirNamingType string, scriptCollectionMap []string) ([]*ArtifactResult, error) {
+	// Check draft versions before processing so that no artifact is processed on error
+	if draftHandling == "ERROR" {
+		for _, artifact := range artifacts {
+			if artifact.details.IsDraft {
+				return nil, fmt.Errorf("Artifact %v is in draft version. Save Version in Web UI first!", artifact.details.Id)
+			}
+		}
+	}
+
+	// Create temp directories in working dir
+	for w := 0; w < max(s.parallelism, 1); w++ {
+		err := os.MkdirAll(s.workerDir(workDir, w)+"/download", os.ModePerm)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+	}
 
-		// TODO - override directory name using key value pair - to cater for syncing artifact from different environment
-		var directoryName string
+	// Process through the artifacts
+	results := runParallel(s.parallelism, len(artifacts), func(worker int, i int) *ArtifactResult {
+		artifact := artifacts[i].details
+		start := time.Now()
+		directoryName := artifact.Id
 		if dirNamingType == "NAME" {
 			directoryName = artifact.Name
-		} else {
-			directoryName = artifact.Id
 		}
-		// Unzip artifact contents
-		log.Debug().Msgf("Target artifact directory name - %v", directoryName)
-		downloadedArtifactPath := fmt.Sprintf("%v/download/%v", workDir, directoryName)
-		err = file.UnzipSource(targetDownloadFile, downloadedArtifactPath)
+		gitArtifactPath := fmt.Sprintf("%v/%v", artifacts[i].artifactsDir, directoryName)
+		versionBefore := getManifestVersion(gitArtifactPath)
+		status, err := s.artifactToGit(artifact, s.workerDir(workDir, worker), artifacts[i].artifactsDir, draftHandling, dirNamingType, scriptCollectionMap)
 		if err != nil {
-			return err
+			status = StatusFailed
+		}
+		if status == StatusAdded || status == StatusUpdated {
+			s.changes.Add(&repo.ArtifactChange{Id: artifact.Id, Type: artifact.ArtifactType, Dir: gitArtifactPath, VersionBefore: versionBefore, VersionAfter: getManifestVersion(gitArtifactPath)})
 		}
-		log.Info().Msgf("Downloaded artifact unzipped to %v", downloadedArtifactPath)
+		entry := &report.Entry{Id: artifact.Id, Type: artifact.ArtifactType, PackageId: artifacts[i].packageId, Action: reportAction(status), VersionBefore: versionBefore}
+		if status != StatusSkipped {
+			entry.VersionAfter = artifact.Version
+		}
+		s.report.Add(entry, start, err)
+		return &ArtifactResult{ArtifactId: artifact.Id, Status: status, Err: err}
+	}, func(i int) *ArtifactResult {
+		return s.notRunResult(artifacts[i].details.Id, artifacts[i].details.ArtifactType, artifacts[i].packageId)
+	})
 
-		gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, directoryName)
-		if file.Exists(fmt.Sprintf("%v/META-INF/MANIFEST.MF", gitArtifactPath)) {
-			// (1) If artifact already exists in Git, then compare and update
-			log.Info().Msg("Comparing content from tenant against Git")
+	// Clean up working directory
+	for w := 0; w < max(s.parallelism, 1); w++ {
+		err := os.RemoveAll(s.workerDir(workDir, w) + "/download")
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+	}
+	return results, nil
+}
 
-			// Diff artifact contents
-			dirDiffer, err := dt.CompareContent(downloadedArtifactPath, gitArtifactPath, scriptCollectionMap, "git")
-			if err != nil {
-				return err
-			}
+func (s *Synchroniser) artifactToGit(artifact *api.ArtifactDetails, workDir string, artifactsDir string, draftHandling string, dirNamingType string, scriptCollectionMap []string) (string, error) {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("📢 Begin processing for artifact %v", artifact.Id)
+	// Check if artifact is in draft version
+	if artifact.IsDraft {
+		switch draftHandling {
+		case "SKIP":
+			log.Warn().Msgf("Artifact %v is in draft version, and will be skipped", artifact.Id)
+			return StatusSkipped, nil
+		case "ADD":
+			log.Info().Msgf("Artifact %v is in draft version, and will be added", artifact.Id)
+		}
+	}
+	// Download artifact content
+	dt := s.newDesigntimeArtifact(artifact.ArtifactType)
+	targetDownloadFile := fmt.Sprintf("%v/download/%v.zip", workDir, artifact.Id)
+	err := dt.Download(targetDownloadFile, artifact.Id)
+	if err != nil {
+		return "", err
+	}
 
-			if dirDiffer {
-				log.Info().Msg("🏆 Changes detected and will be updated to Git")
-				// Update the changes into the Git directory
-				err = dt.CopyContent(downloadedArtifactPath, gitArtifactPath)
-				if err != nil {
-					return err
-				}
-			} else {
-				log.Info().Msg("🏆 No changes detected. Update to Git not required")
-			}
+	// TODO - override directory name using key value pair - to cater for syncing artifact from different environment
+	var directoryName string
+	if dirNamingType == "NAME" {
+		directoryName = artifact.Name
+	} else {
+		directoryName = artifact.Id
+	}
+	// Unzip artifact contents
+	log.Debug().Msgf("Target artifact directory name - %v", directoryName)
+	downloadedArtifactPath := fmt.Sprintf("%v/download/%v", workDir, directoryName)
+	err = file.UnzipSource(targetDownloadFile, downloadedArtifactPath)
+	if err != nil {
+		return "", err
+	}
+	log.Info().Msgf("Downloaded artifact unzipped to %v", downloadedArtifactPath)
 
-		} else { // (2) If artifact does not exist in Git, then add it
-			log.Info().Msgf("🏆 Artifact %v does not exist, and will be added to Git", artifact.Id)
-			// Update the script collection in IFlow BPMN2 XML before syncing to Git
-			if artifact.ArtifactType == "Integration" {
-				err = file.UpdateBPMN(downloadedArtifactPath, scriptCollectionMap)
-				if err != nil {
-					return err
-				}
-			}
-			err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
+	// Schedules are maintained in Git in the readable cron form
+	err = SchedulesToCron(fmt.Sprintf("%v/src/main/resources/parameters.prop", downloadedArtifactPath))
+	if err != nil {
+		return "", err
+	}
+
+	gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, directoryName)
+	if file.Exists(fmt.Sprintf("%v/META-INF/MANIFEST.MF", gitArtifactPath)) {
+		// (1) If artifact already exists in Git, then compare and update
+		log.Info().Msgf("Comparing content of artifact %v from tenant against Git", artifact.Id)
+
+		// Diff artifact contents
+		changedFiles, err := dt.CompareContent(downloadedArtifactPath, gitArtifactPath, scriptCollectionMap, "git")
+		if err != nil {
+			return "", err
+		}
+
+		if len(changedFiles) > 0 {
+			log.Info().Msgf("🏆 Changes detected in artifact %v and will be updated to Git: %v", artifact.Id, strings.Join(changedFiles, ", "))
+			// Update the changes into the Git directory
+			err = dt.CopyContent(downloadedArtifactPath, gitArtifactPath)
 			if err != nil {
-				return err
+				return "", err
 			}
+			return StatusUpdated, nil
 		}
+		log.Info().Msgf("🏆 No changes detected in artifact %v. Update to Git not required", artifact.Id)
+		return StatusUnchanged, nil
 	}
 
-	// Clean up working directory
-	err = os.RemoveAll(workDir + "/download")
+	// (2) If artifact does not exist in Git, then add it
+	log.Info().Msgf("🏆 Artifact %v does not exist, and will be added to Git", artifact.Id)
+	// Update the script collection in IFlow BPMN2 XML before syncing to Git
+	if artifact.ArtifactType == "Integration" {
+		err = file.UpdateBPMN(downloadedArtifactPath, scriptCollectionMap)
+		if err != nil {
+			return "", err
+		}
+	}
+	err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
 	if err != nil {
-		return errors.Wrap(err, 0)
+		return "", err
 	}
-
-	log.Info().Msg("---------------------------------------------------------------------------------")
-	log.Info().Msgf("🏆 Completed processing of artifacts in integration package %v", packageId)
-	return nil
+	return StatusAdded, nil
 }
 
 func filterArtifacts(artifacts []*api.ArtifactDetails, includedIds []string, excludedIds []string) ([]*api.ArtifactDetails, error) {
@@ -278,6 +454,15 @@ func packageContentDiffer(source *api.PackageSingleData, target *api.PackageSing
 	return false
 }
 
+// tenantArtifact is an artifact directory to be uploaded to the tenant
+type tenantArtifact struct {
+	id        string
+	name      string
+	typ       string
+	dir       string
+	paramFile string
+}
+
 func (s *Synchroniser) ArtifactsToTenant(packageId string, workDir string, artifactsDir string, includedIds []string, excludedIds []string) error {
 	// Get directory list
 	baseSourceDir := filepath.Clean(artifactsDir)
@@ -287,24 +472,21 @@ func (s *Synchroniser) ArtifactsToTenant(packageId string, workDir string, artif
 	}
 
 	artifactDirFound := false
+	var artifacts []*tenantArtifact
 	for _, entry := range entries {
 		manifestPath := fmt.Sprintf("%v/%v/META-INF/MANIFEST.MF", baseSourceDir, entry.Name())
 		if entry.IsDir() && file.Exists(manifestPath) {
 			artifactDirFound = true
 			artifactDir := fmt.Sprintf("%v/%v", baseSourceDir, entry.Name())
-			log.Info().Msg("---------------------------------------------------------------------------------")
 			log.Info().Msgf("Processing directory %v", artifactDir)
-			paramFile := fmt.Sprintf("%v/src/main/resouces/parameters/prop", artifactDir)
+			paramFile := fmt.Sprintf("%v/src/main/resources/parameters.prop", artifactDir)
 
-			headers, err := GetManifestHeaders(manifestPath)
+			headers, err := file.ReadManifest(manifestPath)
 			if err != nil {
 				return err
 			}
 
-			artifactId := headers.Get("Bundle-SymbolicName")
-			// remove spaces then remove ;singleton:=true
-			artifactId = strings.ReplaceAll(artifactId, " ", "")
-			artifactId = strings.ReplaceAll(artifactId, ";singleton:=true", "")
+			artifactId := file.ManifestId(headers)
 
 			// Filter in/out artifacts
 			if len(includedIds) > 0 {
@@ -327,117 +509,287 @@ func (s *Synchroniser) ArtifactsToTenant(packageId string, workDir string, artif
 			if artifactType == "IntegrationFlow" {
 				artifactType = "Integration"
 			}
-
-			log.Info().Msgf("📢 Begin processing for artifact %v", artifactId)
-			err = s.SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, paramFile, nil)
-			if err != nil {
-				return err
-			}
+			artifacts = append(artifacts, &tenantArtifact{id: artifactId, name: artifactName, typ: artifactType, dir: artifactDir, paramFile: paramFile})
 		}
 	}
 	if !artifactDirFound {
 		log.Warn().Msgf("No directory with artifact contents found in %v", baseSourceDir)
+		return nil
+	}
+
+	levels := orderArtifacts(artifacts, baseSourceDir)
+	var results []*ArtifactResult
+	failed := false
+	for _, level := range levels {
+		if failed {
+			// Artifacts of the next levels depend on the failed artifact
+			for _, a := range level {
+				results = append(results, s.notRunResult(a.id, a.typ, packageId))
+			}
+			continue
+		}
+		levelResults := runParallel(s.parallelism, len(level), func(worker int, i int) *ArtifactResult {
+			a := level[i]
+			log.Info().Msg("---------------------------------------------------------------------------------")
+			log.Info().Msgf("📢 Begin processing for artifact %v", a.id)
+			status, err := s.reportArtifactToTenant(a.id, a.name, a.typ, packageId, a.dir, s.workerDir(workDir, worker), a.paramFile, nil)
+			if err != nil {
+				status = StatusFailed
+			}
+			return &ArtifactResult{ArtifactId: a.id, Status: status, Err: err}
+		}, func(i int) *ArtifactResult {
+			return s.notRunResult(level[i].id, level[i].typ, packageId)
+		})
+		results = append(results, levelResults...)
+		failed = slices.ContainsFunc(levelResults, func(r *ArtifactResult) bool { return r.Err != nil })
+	}
+	err = logResults(results)
+	if err != nil {
+		return err
+	}
+	if s.prune {
+		return s.pruneTenant(packageId, artifactsDir, includedIds, excludedIds)
 	}
 	return nil
 }
 
-func GetManifestHeaders(manifestPath string) (textproto.MIMEHeader, error) {
-	manifestFile, err := os.Open(manifestPath)
+// orderArtifacts groups the artifacts by their dependencies, so that referenced script collections, message mappings
+// and IFlows called with ProcessDirect are created before the artifacts using them. If the dependencies cannot be
+// determined, e.g. due to an invalid BPMN2 file, the artifacts are processed in a single group.
+func orderArtifacts(artifacts []*tenantArtifact, artifactsDir string) [][]*tenantArtifact {
+	graph, err := dependency.Build(artifactsDir)
 	if err != nil {
-		return nil, errors.Wrap(err, 0)
+		log.Warn().Msgf("Artifacts are processed without considering their dependencies as these cannot be determined - %v", err)
+		return [][]*tenantArtifact{artifacts}
+	}
+	if _, cyclic := graph.Levels(); len(cyclic) > 0 {
+		log.Warn().Msgf("Artifacts %v have cyclic dependencies and are processed last", strings.Join(cyclic, ", "))
+	}
+	byId := map[string][]*tenantArtifact{}
+	var ids []string
+	for _, a := range artifacts {
+		byId[a.id] = append(byId[a.id], a)
+		ids = append(ids, a.id)
+	}
+	var levels [][]*tenantArtifact
+	for _, levelIds := range graph.Order(ids) {
+		var level []*tenantArtifact
+		for _, id := range levelIds {
+			level = append(level, byId[id][0])
+			byId[id] = byId[id][1:]
+		}
+		levels = append(levels, level)
 	}
-	defer manifestFile.Close()
+	return levels
+}
 
-	tp := textproto.NewReader(bufio.NewReader(manifestFile))
-	headers, err := tp.ReadMIMEHeader()
+// GetArtifactDirIds returns the IDs of the artifacts in the subdirectories of artifactsDir
+func GetArtifactDirIds(artifactsDir string) ([]string, error) {
+	dirs, err := getArtifactDirs(artifactsDir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, dir := range dirs {
+		ids = append(ids, dir.id)
+	}
+	return ids, nil
+}
+
+// artifactDir is a subdirectory of the artifacts directory containing the contents of an artifact
+type artifactDir struct {
+	id  string
+	dir string
+}
+
+func getArtifactDirs(artifactsDir string) ([]*artifactDir, error) {
+	baseSourceDir := filepath.Clean(artifactsDir)
+	entries, err := os.ReadDir(baseSourceDir)
 	if err != nil {
 		return nil, errors.Wrap(err, 0)
 	}
-	return headers, nil
+	var dirs []*artifactDir
+	for _, entry := range entries {
+		manifestPath := fmt.Sprintf("%v/%v/META-INF/MANIFEST.MF", baseSourceDir, entry.Name())
+		if entry.IsDir() && file.Exists(manifestPath) {
+			headers, err := file.ReadManifest(manifestPath)
+			if err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, &artifactDir{id: file.ManifestId(headers), dir: fmt.Sprintf("%v/%v", baseSourceDir, entry.Name())})
+		}
+	}
+	return dirs, nil
 }
 
 func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile string, scriptMap []string) error {
-	dt := api.NewDesigntimeArtifact(artifactType, s.exe)
+	_, err := s.reportArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, scriptMap)
+	return err
+}
+
+// reportArtifactToTenant creates or updates the artifact in the tenant, and records the outcome in the report
+func (s *Synchroniser) reportArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile string, scriptMap []string) (string, error) {
+	start := time.Now()
+	var versionBefore string
+	if s.report != nil {
+		versionBefore, _, _, _ = s.newDesigntimeArtifact(artifactType).Get(artifactId, "active")
+	}
+	status, err := s.singleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, scriptMap)
+	s.report.Add(&report.Entry{Id: artifactId, Type: artifactType, PackageId: packageId, Action: reportAction(status), VersionBefore: versionBefore, VersionAfter: getManifestVersion(artifactDir)}, start, err)
+	return status, err
+}
+
+// getManifestVersion returns the Bundle-Version in MANIFEST.MF of the artifact directory, or an empty string if there is none
+func getManifestVersion(artifactDir string) string {
+	headers, err := file.ReadManifest(fmt.Sprintf("%v/META-INF/MANIFEST.MF", artifactDir))
+	if err != nil {
+		return ""
+	}
+	return headers.Get("Bundle-Version")
+}
+
+func (s *Synchroniser) singleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile string, scriptMap []string) (string, error) {
+	dt := s.newDesigntimeArtifact(artifactType)
 
 	exists, err := artifactExists(artifactId, artifactType, packageId, dt, s.ip)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	var params *Parameters
+	if artifactType == "Integration" {
+		params, err = LoadParameters(parametersFile, s.environment)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	if !exists {
 		log.Info().Msgf("Artifact %v will be created", artifactId)
+		if s.plan != nil {
+			s.plan.Add(&PlanAction{Action: ActionCreate, ArtifactId: artifactId, ArtifactType: artifactType, PackageId: packageId})
+			return StatusCreated, nil
+		}
 		if artifactType == "Integration" {
 			err = file.UpdateBPMN(artifactDir, scriptMap)
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 
-		err = prepareUploadDir(workDir, artifactDir, dt)
+		err = prepareUploadDir(workDir, artifactDir, dt, params)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		err = createArtifact(artifactId, artifactName, packageId, workDir+"/upload", dt)
 		if err != nil {
-			return err
+			return "", err
+		}
+		err = cleanUploadDir(workDir)
+		if err != nil {
+			return "", err
 		}
-
 		log.Info().Msg("🏆 Designtime artifact created successfully")
-	} else {
-		log.Info().Msg("Checking if designtime artifact needs to be updated")
 
-		zipFile := fmt.Sprintf("%v/%v.zip", workDir, artifactId)
-		err = dt.Download(zipFile, artifactId)
-		if err != nil {
-			return err
+		// Schedules in cron form are only converted to the XML form of the tenant by the configuration update
+		if params != nil && len(params.props.Keys()) > 0 {
+			log.Info().Msg("Updating configured parameter(s) of Integration designtime artifact where necessary")
+			err = updateConfiguration(artifactId, artifactType, packageId, params, s.exe, s.plan)
+			if err != nil {
+				return "", err
+			}
 		}
+		return StatusCreated, nil
+	}
+
+	status := StatusUnchanged
+	log.Info().Msg("Checking if designtime artifact needs to be updated")
+
+	zipFile := fmt.Sprintf("%v/%v.zip", workDir, artifactId)
+	err = dt.Download(zipFile, artifactId)
+	if err != nil {
+		return "", err
+	}
+
+	changedFiles, err := compareArtifactContents(workDir, zipFile, artifactDir, scriptMap, dt)
+	if err != nil {
+		return "", err
+	}
 
-		changesFound, err := compareArtifactContents(workDir, zipFile, artifactDir, scriptMap, dt)
+	if len(changedFiles) > 0 {
+		log.Info().Msgf("Changed files: %v", strings.Join(changedFiles, ", "))
+	}
+	if len(changedFiles) > 0 && s.plan != nil {
+		log.Info().Msg("Changes found in designtime artifact. Designtime artifact would be updated in CPI tenant")
+		s.plan.Add(&PlanAction{Action: ActionUpdate, ArtifactId: artifactId, ArtifactType: artifactType, PackageId: packageId, Files: changedFiles})
+		err = s.planUndeployForUpdate(artifactId, artifactType, packageId, artifactDir)
 		if err != nil {
-			return err
+			return "", err
+		}
+		status = StatusUpdated
+	} else if len(changedFiles) > 0 {
+		log.Info().Msg("Changes found in designtime artifact. Designtime artifact will be updated in CPI tenant")
+		err = prepareUploadDir(workDir, artifactDir, dt, params)
+		if err != nil {
+			return "", err
+		}
+		err = updateArtifact(artifactId, artifactName, packageId, workDir+"/upload", dt)
+		if err != nil {
+			return "", err
+		}
+		err = cleanUploadDir(workDir)
+		if err != nil {
+			return "", err
 		}
 
-		if changesFound == true {
-			log.Info().Msg("Changes found in designtime artifact. Designtime artifact will be updated in CPI tenant")
-			err = prepareUploadDir(workDir, artifactDir, dt)
-			if err != nil {
-				return err
-			}
-			err = updateArtifact(artifactId, artifactName, packageId, workDir+"/upload", dt)
+		designtimeVersion, _, _, err := dt.Get(artifactId, "active")
+		if err != nil {
+			return "", err
+		}
+		r := api.NewRuntime(s.exe)
+		runtimeVersion, _, err := r.Get(artifactId)
+		if err != nil {
+			return "", err
+		}
+		if runtimeVersion == designtimeVersion {
+			log.Info().Msg("Undeploying existing runtime artifact with same version number due to changes in design")
+			err = r.UnDeploy(artifactId)
 			if err != nil {
-				return err
+				return "", err
 			}
+		}
 
-			designtimeVersion, _, _, err := dt.Get(artifactId, "active")
-			if err != nil {
-				return err
-			}
-			r := api.NewRuntime(s.exe)
-			runtimeVersion, _, err := r.Get(artifactId)
-			if err != nil {
-				return err
-			}
-			if runtimeVersion == designtimeVersion {
-				log.Info().Msg("Undeploying existing runtime artifact with same version number due to changes in design")
-				err = r.UnDeploy(artifactId)
-				if err != nil {
-					return err
-				}
-			}
+		log.Info().Msg("🏆 Designtime artifact updated successfully")
+		status = StatusUpdated
+	} else {
+		log.Info().Msg("🏆 No changes detected. Designtime artifact does not need to be updated")
+	}
 
-			log.Info().Msg("🏆 Designtime artifact updated successfully")
-		} else {
-			log.Info().Msg("🏆 No changes detected. Designtime artifact does not need to be updated")
+	if params != nil && len(params.props.Keys()) > 0 {
+		log.Info().Msg("Updating configured parameter(s) of Integration designtime artifact where necessary")
+		err = updateConfiguration(artifactId, artifactType, packageId, params, s.exe, s.plan)
+		if err != nil {
+			return "", err
 		}
+	}
+	return status, nil
+}
 
-		if artifactType == "Integration" && file.Exists(parametersFile) {
-			log.Info().Msg("Updating configured parameter(s) of Integration designtime artifact where necessary")
-			err = updateConfiguration(artifactId, parametersFile, s.exe)
-			if err != nil {
-				return err
-			}
-		}
+func (s *Synchroniser) planUndeployForUpdate(artifactId string, artifactType string, packageId string, artifactDir string) error {
+	// The designtime version after the update is the one in the MANIFEST.MF
+	headers, err := file.ReadManifest(fmt.Sprintf("%v/META-INF/MANIFEST.MF", artifactDir))
+	if err != nil {
+		return err
+	}
+	designtimeVersion := headers.Get("Bundle-Version")
+	r := api.NewRuntime(s.exe)
+	runtimeVersion, _, err := r.Get(artifactId)
+	if err != nil {
+		return err
+	}
+	if runtimeVersion == designtimeVersion {
+		s.plan.Add(&PlanAction{Action: ActionUndeploy, ArtifactId: artifactId, ArtifactType: artifactType, PackageId: packageId})
 	}
 	return nil
 }
@@ -469,14 +821,31 @@ func artifactExists(artifactId string, artifactType string, packageId string, dt
 	}
 }
 
-func prepareUploadDir(workDir string, artifactDir string, dt api.DesigntimeArtifact) error {
+func prepareUploadDir(workDir string, artifactDir string, dt api.DesigntimeArtifact, params *Parameters) error {
 	// Clean up previous uploads
 	uploadDir := workDir + "/upload"
 	err := os.RemoveAll(uploadDir)
 	if err != nil {
 		return errors.Wrap(err, 0)
 	}
-	return dt.CopyContent(artifactDir, uploadDir)
+	err = dt.CopyContent(artifactDir, uploadDir)
+	if err != nil {
+		return err
+	}
+	// Upload the parameters of the environment with the placeholders resolved
+	if params != nil && params.Resolved {
+		return params.Write(uploadDir + "/src/main/resources/parameters.prop")
+	}
+	return nil
+}
+
+// cleanUploadDir removes the uploaded content as it may contain resolved secrets
+func cleanUploadDir(workDir string) error {
+	err := os.RemoveAll(workDir + "/upload")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
 }
 
 func createArtifact(artifactId string, artifactName string, packageId string, artifactDir string, dt api.DesigntimeArtifact) error {
@@ -495,23 +864,23 @@ func updateArtifact(artifactId string, artifactName string, packageId string, ar
 	return nil
 }
 
-func compareArtifactContents(workDir string, zipFile string, artifactDir string, scriptMap []string, dt api.DesigntimeArtifact) (bool, error) {
+func compareArtifactContents(workDir string, zipFile string, artifactDir string, scriptMap []string, dt api.DesigntimeArtifact) ([]string, error) {
 	tgtDir := fmt.Sprintf("%v/download", workDir)
 	err := os.RemoveAll(tgtDir)
 	if err != nil {
-		return false, errors.Wrap(err, 0)
+		return nil, errors.Wrap(err, 0)
 	}
 
 	log.Info().Msgf("Unzipping downloaded designtime artifact %v to %v/download", zipFile, workDir)
 	err = file.UnzipSource(zipFile, tgtDir)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	return dt.CompareContent(artifactDir, tgtDir, scriptMap, "tenant")
 }
 
-func updateConfiguration(artifactId string, parametersFile string, exe *httpclnt.HTTPExecuter) error {
+func updateConfiguration(artifactId string, artifactType string, packageId string, params *Parameters, exe *httpclnt.HTTPExecuter, plan *Plan) error {
 	// Get configured parameters from tenant
 	c := api.NewConfiguration(exe)
 	tenantParameters, err := c.Get(artifactId, "active")
@@ -519,24 +888,39 @@ func updateConfiguration(artifactId string, parametersFile string, exe *httpclnt
 		return err
 	}
 
-	// Get parameters from parameters.prop file
-	log.Info().Msgf("Getting parameters from %v file", parametersFile)
-	fileParameters := properties.MustLoadFile(parametersFile, properties.UTF8)
-
 	log.Info().Msg("Comparing parameters and updating where necessary")
 	atLeastOneUpdated := false
 	for _, result := range tenantParameters.Root.Results {
-		if result.DataType != "custom:schedule" { // TODO - handle translation to Cron
-			// Skip updating for schedulers which require translation to Cron values
-			fileValue := fileParameters.GetString(result.ParameterKey, "")
-			if fileValue != "" && fileValue != result.ParameterValue {
-				log.Info().Msgf("Parameter %v to be updated from %v to %v", result.ParameterKey, result.ParameterValue, fileValue)
-				err = c.Update(artifactId, "active", result.ParameterKey, fileValue)
-				if err != nil {
-					return err
-				}
-				atLeastOneUpdated = true
+		fileValue := params.Get(result.ParameterKey)
+		if fileValue == "" {
+			continue
+		}
+		oldValue := result.ParameterValue
+		newValue := params.Display(result.ParameterKey)
+		if result.DataType == api.ScheduleDataType {
+			// Schedules are compared semantically, and can be maintained in the readable cron form
+			var changed bool
+			fileValue, oldValue, newValue, changed, err = compareSchedule(result.ParameterKey, result.ParameterValue, fileValue)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
 			}
+		} else if fileValue == result.ParameterValue {
+			continue
+		} else if params.secrets[result.ParameterKey] {
+			oldValue = params.Display(result.ParameterKey)
+		}
+		log.Info().Msgf("Parameter %v to be updated from %v to %v", result.ParameterKey, oldValue, newValue)
+		atLeastOneUpdated = true
+		if plan != nil {
+			plan.Add(&PlanAction{Action: ActionUpdateParameter, ArtifactId: artifactId, ArtifactType: artifactType, PackageId: packageId, Parameter: result.ParameterKey, OldValue: oldValue, NewValue: newValue})
+			continue
+		}
+		err = c.Update(artifactId, "active", result.ParameterKey, fileValue)
+		if err != nil {
+			return err
 		}
 	}
 	if atLeastOneUpdated {
@@ -547,6 +931,10 @@ func updateConfiguration(artifactId string, parametersFile string, exe *httpclnt
 		}
 		if version == "NOT_DEPLOYED" {
 			log.Info().Msg("🏆 No existing runtime artifact deployed")
+		} else if plan != nil {
+			if !plan.Contains(ActionUndeploy, artifactId) {
+				plan.Add(&PlanAction{Action: ActionUndeploy, ArtifactId: artifactId, ArtifactType: artifactType, PackageId: packageId})
+			}
 		} else {
 			log.Info().Msg("🏆 Undeploying existing runtime artifact due to changes in configured parameters")
 			err = r.UnDeploy(artifactId)
@@ -559,3 +947,34 @@ func updateConfiguration(artifactId string, parametersFile string, exe *httpclnt
 	}
 	return nil
 }
+
+// compareSchedule compares the schedule of the tenant with the schedule of the file in either XML or cron form.
+// It returns the XML value for the update and the readable forms of the old and new schedules for logging.
+func compareSchedule(key string, tenantValue string, fileValue string) (string, string, string, bool, error) {
+	fileSchedule, err := api.ParseSchedule(fileValue)
+	if err != nil && api.IsScheduleXML(fileValue) {
+		// The XML form is copied from the tenant, so it is applied as is even if it cannot be compared semantically
+		log.Warn().Msgf("Schedule of parameter %v is compared as text as it cannot be parsed - %v", key, err)
+		return fileValue, tenantValue, fileValue, fileValue != tenantValue, nil
+	} else if err != nil {
+		return "", "", "", false, fmt.Errorf("Parameter %v: %w", key, err)
+	}
+	// A tenant value that is not in XML form, e.g. uploaded in cron form with parameters.prop, is always replaced
+	tenantSchedule, err := api.ParseScheduleXML(tenantValue)
+	if err != nil {
+		log.Debug().Msgf("Schedule of parameter %v on tenant cannot be parsed - %v", key, err)
+		tenantSchedule = nil
+	}
+	oldValue := tenantValue
+	if tenantSchedule != nil {
+		if tenantSchedule.Equal(fileSchedule) {
+			return "", "", "", false, nil
+		}
+		oldValue = tenantSchedule.Cron()
+	}
+	updateValue, err := fileSchedule.XML(tenantSchedule)
+	if err != nil {
+		return "", "", "", false, fmt.Errorf("Parameter %v: %w", key, err)
+	}
+	return updateValue, oldValue, fileSchedule.Cron(), true, nil
+}
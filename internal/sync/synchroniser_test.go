@@ -1,8 +1,14 @@
 package sync
 
 import (
+	"fmt"
 	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/mock"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -63,3 +69,165 @@ func TestFilterExcludeInvalidID(t *testing.T) {
 
 	assert.Equal(t, "Artifact DummyIFlow2 in --ids-exclude does not exist", err.Error(), "Incorrect error message")
 }
+
+func TestGetArtifactDirIds(t *testing.T) {
+	ids, err := GetArtifactDirIds("../../test/testdata/artifacts/create")
+	if err != nil {
+		t.Fatalf("GetArtifactDirIds failed with error - %v", err)
+	}
+
+	assert.Equal(t, 4, len(ids), "Expected number of artifacts = 4")
+	assert.Equal(t, "Integration_Test_IFlow", ids[0], "Expected ID for first entry = Integration_Test_IFlow")
+}
+
+func TestOrderArtifacts(t *testing.T) {
+	artifactsDir := t.TempDir()
+	processDirect := `<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd">
+<bpmn2:messageFlow id="MessageFlow_1"><bpmn2:extensionElements>
+<ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+<ifl:property><key>direction</key><value>%v</value></ifl:property>
+<ifl:property><key>address</key><value>/invoices</value></ifl:property>
+</bpmn2:extensionElements></bpmn2:messageFlow>
+</bpmn2:definitions>`
+	files := map[string]string{
+		"Order_Create/META-INF/MANIFEST.MF":                                                   "Manifest-Version: 1.0\nBundle-SymbolicName: Order_Create\nSAP-BundleType: IntegrationFlow\n\n",
+		"Order_Create/src/main/resources/scenarioflows/integrationflow/Order_Create.iflw":     fmt.Sprintf(processDirect, "Receiver"),
+		"Invoice_Create/META-INF/MANIFEST.MF":                                                 "Manifest-Version: 1.0\nBundle-SymbolicName: Invoice_Create\nSAP-BundleType: IntegrationFlow\n\n",
+		"Invoice_Create/src/main/resources/scenarioflows/integrationflow/Invoice_Create.iflw": fmt.Sprintf(processDirect, "Sender"),
+	}
+	for name, content := range files {
+		path := filepath.Join(artifactsDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("MkdirAll failed with error - %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed with error - %v", err)
+		}
+	}
+
+	artifacts := []*tenantArtifact{{id: "Order_Create"}, {id: "Invoice_Create"}}
+	levels := orderArtifacts(artifacts, artifactsDir)
+	assert.Equal(t, [][]*tenantArtifact{{artifacts[1]}, {artifacts[0]}}, levels, "Called IFlow not created first")
+
+	// An invalid artifact does not stop the sync of the others
+	err := os.WriteFile(filepath.Join(artifactsDir, "Order_Create/src/main/resources/scenarioflows/integrationflow/Order_Create.iflw"), []byte("<bpmn2:definitions"), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile failed with error - %v", err)
+	}
+	levels = orderArtifacts(artifacts, artifactsDir)
+	assert.Equal(t, [][]*tenantArtifact{artifacts}, levels, "Artifacts not processed without order")
+}
+
+func TestCompareSchedule_WithoutRecurrence(t *testing.T) {
+	tenantValue := "<row><cell>dateType</cell><cell>ON_DATE</cell></row><row><cell>fireAt</cell><cell>10:00</cell></row>"
+	fileValue := "<row><cell>dateType</cell><cell>ON_DATE</cell></row><row><cell>fireAt</cell><cell>11:00</cell></row>"
+
+	// XML schedules that cannot be parsed are compared as text instead of failing
+	_, _, _, changed, err := compareSchedule("Timer", tenantValue, tenantValue)
+	if err != nil {
+		t.Fatalf("compareSchedule failed with error - %v", err)
+	}
+	assert.False(t, changed, "Same schedule detected as changed")
+
+	updateValue, _, _, changed, err := compareSchedule("Timer", tenantValue, fileValue)
+	if err != nil {
+		t.Fatalf("compareSchedule failed with error - %v", err)
+	}
+	assert.True(t, changed, "Changed schedule not detected")
+	assert.Equal(t, fileValue, updateValue)
+
+	_, _, _, _, err = compareSchedule("Timer", tenantValue, "0 0 12")
+	assert.Error(t, err, "Invalid cron form accepted")
+}
+
+func TestPackagesToGit(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "user", "password", host, "http", port, true)
+
+	packageData, err := api.GetPackageDetails("../../test/testdata/FlashPipeIntegrationTest.json")
+	if err != nil {
+		t.Fatalf("GetPackageDetails failed with error - %v", err)
+	}
+	ip := api.NewIntegrationPackage(exe)
+	dt := api.NewDesigntimeArtifact("Integration", exe)
+	packageIds := []string{"FlashPipeIntegrationTest", "FlashPipeIntegrationTest2"}
+	for i, packageId := range packageIds {
+		packageData.Root.Id = packageId
+		err = ip.Create(packageData)
+		if err != nil {
+			t.Fatalf("Create package failed with error - %v", err)
+		}
+		artifactId := fmt.Sprintf("Integration_Test_IFlow%d", i+1)
+		err = dt.Create(artifactId, artifactId, packageId, "../../test/testdata/artifacts/create/Integration_Test_IFlow")
+		if err != nil {
+			t.Fatalf("Create artifact failed with error - %v", err)
+		}
+	}
+
+	// The artifacts of both packages are processed on the same workers
+	artifactsBaseDir := t.TempDir()
+	synchroniser := New(exe)
+	synchroniser.SetParallelism(2)
+	err = synchroniser.PackagesToGit(packageIds, t.TempDir(), artifactsBaseDir, "SKIP", false)
+	if err != nil {
+		t.Fatalf("PackagesToGit failed with error - %v", err)
+	}
+	assert.True(t, file.IsArtifactDir(filepath.Join(artifactsBaseDir, "FlashPipeIntegrationTest", "Integration_Test_IFlow1")), "Artifact of first package not synced")
+	assert.True(t, file.IsArtifactDir(filepath.Join(artifactsBaseDir, "FlashPipeIntegrationTest2", "Integration_Test_IFlow2")), "Artifact of second package not synced")
+}
+
+func TestSingleArtifactToTenant_CreateWithCronSchedule(t *testing.T) {
+	s := mock.NewServer()
+	svr := s.Start()
+	defer svr.Close()
+	host, port := httpclnt.GetHostPort(svr.URL)
+	exe := httpclnt.New("", "", "", "", "user", "password", host, "http", port, true)
+
+	packageData, err := api.GetPackageDetails("../../test/testdata/FlashPipeIntegrationTest.json")
+	if err != nil {
+		t.Fatalf("GetPackageDetails failed with error - %v", err)
+	}
+	err = api.NewIntegrationPackage(exe).Create(packageData)
+	if err != nil {
+		t.Fatalf("Create package failed with error - %v", err)
+	}
+
+	// Timer IFlow with the schedule in cron form as written to Git
+	artifactDir := filepath.Join(t.TempDir(), "Integration_Test_IFlow")
+	err = file.ReplaceDir("../../test/testdata/artifacts/create/Integration_Test_IFlow", artifactDir)
+	if err != nil {
+		t.Fatalf("ReplaceDir failed with error - %v", err)
+	}
+	parametersFile := filepath.Join(artifactDir, "src/main/resources/parameters.prop")
+	writeParametersFile(t, parametersFile, "Timer=0 0/15 0-23 ? * * *\n")
+	writeParametersFile(t, filepath.Join(artifactDir, "src/main/resources/parameters.propdef"),
+		"<parameters><parameter><name>Timer</name><type>custom:schedule</type></parameter></parameters>")
+
+	synchroniser := New(exe)
+	status, err := synchroniser.singleArtifactToTenant("Integration_Test_IFlow", "Integration Test IFlow", "Integration", "FlashPipeIntegrationTest", artifactDir, t.TempDir(), parametersFile, nil)
+	if err != nil {
+		t.Fatalf("singleArtifactToTenant failed with error - %v", err)
+	}
+	assert.Equal(t, StatusCreated, status)
+
+	parameters, err := api.NewConfiguration(exe).Get("Integration_Test_IFlow", "active")
+	if err != nil {
+		t.Fatalf("Get configuration failed with error - %v", err)
+	}
+	parameter := api.FindParameterByKey("Timer", parameters.Root.Results)
+	if assert.NotNil(t, parameter, "Schedule not configured") {
+		assert.True(t, api.IsScheduleXML(parameter.ParameterValue), "Schedule not stored in XML form - %v", parameter.ParameterValue)
+		schedule, err := api.ParseScheduleXML(parameter.ParameterValue)
+		if err != nil {
+			t.Fatalf("ParseScheduleXML failed with error - %v", err)
+		}
+		expected, err := api.ParseSchedule("0 0/15 0-23 ? * * *")
+		if err != nil {
+			t.Fatalf("ParseSchedule failed with error - %v", err)
+		}
+		assert.True(t, schedule.Equal(expected), "Incorrect schedule %v", schedule.Cron())
+	}
+}
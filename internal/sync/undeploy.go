@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// UndeployArtifacts undeploys the artifacts that are deployed, and waits until they are removed from the runtime
+func UndeployArtifacts(ids []string, delayLength int, maxCheckLimit int, rt *api.Runtime) error {
+	// Trigger undeployment of artifacts that are currently deployed
+	var undeployed []string
+	for i, id := range ids {
+		log.Info().Msgf("Processing artifact %d - %v", i+1, id)
+		version, status, err := rt.Get(id)
+		if err != nil {
+			return err
+		}
+		if version == "NOT_DEPLOYED" {
+			log.Info().Msgf("Artifact %v is not deployed. Skipping runtime undeployment", id)
+			continue
+		}
+		log.Info().Msgf("🗑️ Undeploying artifact %v with status %v", id, status)
+		err = rt.UnDeploy(id)
+		if err != nil {
+			return err
+		}
+		undeployed = append(undeployed, id)
+	}
+	if len(undeployed) == 0 {
+		log.Info().Msg("🏆 No artifacts to undeploy")
+		return nil
+	}
+
+	// Wait until the artifacts are removed from the runtime
+	var remaining []string
+	for _, id := range undeployed {
+		err := checkUndeploymentStatus(rt, delayLength, maxCheckLimit, id)
+		if err != nil {
+			log.Error().Msgf("Artifact %v - %v", id, err)
+			remaining = append(remaining, id)
+			continue
+		}
+		log.Info().Msgf("Artifact %v undeployed successfully", id)
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("Artifact(s) %v still deployed after %d checks", strings.Join(remaining, ", "), maxCheckLimit)
+	}
+
+	log.Info().Msgf("🏆 %d artifact(s) undeployment completed successfully", len(undeployed))
+	return nil
+}
+
+func checkUndeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit int, id string) error {
+	log.Info().Msgf("Checking runtime status for artifact %v every %d seconds up to %d times", id, delayLength, maxCheckLimit)
+
+	for i := 0; i < maxCheckLimit; i++ {
+		version, status, err := runtime.Get(id)
+		if err != nil {
+			return err
+		}
+		if version == "NOT_DEPLOYED" {
+			return nil
+		}
+		log.Info().Msgf("Check %d - Current artifact runtime status = %s", i+1, status)
+		time.Sleep(time.Duration(delayLength) * time.Second)
+	}
+	return fmt.Errorf("Artifact still deployed after %d checks", maxCheckLimit)
+}